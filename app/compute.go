@@ -0,0 +1,209 @@
+package app
+
+import (
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/opensourceways/xihe-training-center/domain"
+	"github.com/opensourceways/xihe-training-center/domain/compute"
+)
+
+type ComputeCatalogDTO struct {
+	Engines []ComputeEngineDTO `json:"engines"`
+	Flavors []ComputeFlavorDTO `json:"flavors"`
+
+	ImageRegistries []string `json:"image_registries"`
+}
+
+type ComputeEngineDTO struct {
+	Type     string   `json:"type"`
+	Versions []string `json:"versions"`
+}
+
+type ComputeFlavorDTO struct {
+	Flavor string `json:"flavor"`
+	Desc   string `json:"desc"`
+}
+
+type ComputeService interface {
+	List() ComputeCatalogDTO
+
+	// Check checks whether the compute is in the catalog.
+	Check(*domain.Compute) error
+}
+
+// NewComputeService returns the service of compute catalog which is loaded
+// from the config. It will be refreshed from the training platform
+// periodically if the refresh interval is set, otherwise c can be nil.
+func NewComputeService(
+	c compute.Compute, cfg *ComputeConfig, log *logrus.Entry,
+) ComputeService {
+	s := &computeService{
+		c:       c,
+		cfg:     *cfg,
+		log:     log,
+		catalog: cfg.catalog(),
+	}
+
+	if cfg.RefreshInterval > 0 {
+		s.refresh()
+
+		go s.keepRefreshing()
+	}
+
+	return s
+}
+
+type computeService struct {
+	c   compute.Compute
+	cfg ComputeConfig
+	log *logrus.Entry
+
+	mu      sync.RWMutex
+	catalog domain.ComputeCatalog
+}
+
+func (s *computeService) List() ComputeCatalogDTO {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	dto := ComputeCatalogDTO{
+		Engines: make([]ComputeEngineDTO, len(s.catalog.Engines)),
+		Flavors: make([]ComputeFlavorDTO, len(s.catalog.Flavors)),
+
+		ImageRegistries: append([]string{}, s.catalog.ImageRegistries...),
+	}
+
+	for i := range s.catalog.Engines {
+		dto.Engines[i] = ComputeEngineDTO(s.catalog.Engines[i])
+	}
+
+	for i := range s.catalog.Flavors {
+		dto.Flavors[i] = ComputeFlavorDTO(s.catalog.Flavors[i])
+	}
+
+	return dto
+}
+
+func (s *computeService) Check(c *domain.Compute) error {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.catalog.Check(c)
+}
+
+func (s *computeService) keepRefreshing() {
+	ticker := time.NewTicker(s.cfg.refreshInterval())
+	defer ticker.Stop()
+
+	for range ticker.C {
+		s.refresh()
+	}
+}
+
+// refresh refreshes the catalog from the training platform.
+// The catalog keeps unchanged if it failed.
+func (s *computeService) refresh() {
+	engines, err := s.c.ListEngines()
+	if err != nil {
+		s.log.Errorf("list compute engines failed, err:%s", err.Error())
+
+		return
+	}
+
+	flavors, err := s.c.ListFlavors()
+	if err != nil {
+		s.log.Errorf("list compute flavors failed, err:%s", err.Error())
+
+		return
+	}
+
+	allowed := s.cfg.catalog()
+	v := domain.ComputeCatalog{
+		Engines: filterEngines(engines, allowed.Engines),
+		Flavors: filterFlavors(flavors, allowed.Flavors),
+
+		ImageRegistries: allowed.ImageRegistries,
+	}
+
+	// the empty list means no check, so it can't be used
+	// in case none of the allowed ones is supported.
+	if len(v.Engines) == 0 {
+		s.log.Warn("no supported compute engine, use the ones of config")
+
+		v.Engines = allowed.Engines
+	}
+
+	if len(v.Flavors) == 0 {
+		s.log.Warn("no supported compute flavor, use the ones of config")
+
+		v.Flavors = allowed.Flavors
+	}
+
+	s.mu.Lock()
+	s.catalog = v
+	s.mu.Unlock()
+}
+
+// filterEngines returns the engines which are allowed.
+// All the engines are allowed if the allowed list is empty.
+func filterEngines(engines, allowed []domain.ComputeEngine) []domain.ComputeEngine {
+	if len(allowed) == 0 {
+		return engines
+	}
+
+	versions := map[string]map[string]bool{}
+	for i := range allowed {
+		m := make(map[string]bool, len(allowed[i].Versions))
+		for _, v := range allowed[i].Versions {
+			m[v] = true
+		}
+
+		versions[allowed[i].Type] = m
+	}
+
+	r := make([]domain.ComputeEngine, 0, len(engines))
+	for i := range engines {
+		m, ok := versions[engines[i].Type]
+		if !ok {
+			continue
+		}
+
+		e := domain.ComputeEngine{Type: engines[i].Type}
+		for _, v := range engines[i].Versions {
+			if m[v] {
+				e.Versions = append(e.Versions, v)
+			}
+		}
+
+		if len(e.Versions) > 0 {
+			r = append(r, e)
+		}
+	}
+
+	return r
+}
+
+// filterFlavors returns the flavors which are allowed.
+// All the flavors are allowed if the allowed list is empty.
+func filterFlavors(flavors, allowed []domain.ComputeFlavorInfo) []domain.ComputeFlavorInfo {
+	if len(allowed) == 0 {
+		return flavors
+	}
+
+	m := make(map[string]bool, len(allowed))
+	for i := range allowed {
+		m[allowed[i].Flavor] = true
+	}
+
+	r := make([]domain.ComputeFlavorInfo, 0, len(flavors))
+	for i := range flavors {
+		if m[flavors[i].Flavor] {
+			r = append(r, flavors[i])
+		}
+	}
+
+	return r
+}
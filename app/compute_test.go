@@ -0,0 +1,149 @@
+package app
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/opensourceways/xihe-training-center/domain"
+	"github.com/opensourceways/xihe-training-center/infrastructure/inmemory"
+)
+
+func newTestCompute(t *testing.T, typ, version, flavor string) *domain.Compute {
+	t.Helper()
+
+	var (
+		c   domain.Compute
+		err error
+	)
+
+	if c.Type, err = domain.NewComputeType(typ); err != nil {
+		t.Fatal(err)
+	}
+
+	if c.Version, err = domain.NewComputeVersion(version); err != nil {
+		t.Fatal(err)
+	}
+
+	if c.Flavor, err = domain.NewComputeFlavor(flavor); err != nil {
+		t.Fatal(err)
+	}
+
+	return &c
+}
+
+func TestComputeCatalogOfConfig(t *testing.T) {
+	log := logrus.NewEntry(logrus.StandardLogger())
+
+	// nothing is checked without catalog.
+	s := NewComputeService(nil, &ComputeConfig{}, log)
+	if err := s.Check(newTestCompute(t, "any", "any", "any")); err != nil {
+		t.Errorf("check failed, err:%v", err)
+	}
+
+	s = NewComputeService(nil, &ComputeConfig{
+		Engines: []ComputeEngineConfig{{Type: "mindspore", Versions: []string{"1.8"}}},
+		Flavors: []ComputeFlavorConfig{{Flavor: "cpu"}},
+	}, log)
+
+	cases := []struct {
+		compute *domain.Compute
+		valid   bool
+	}{
+		{newTestCompute(t, "mindspore", "1.8", "cpu"), true},
+		{newTestCompute(t, "pytorch", "1.8", "cpu"), false},
+		{newTestCompute(t, "mindspore", "1.7", "cpu"), false},
+		{newTestCompute(t, "mindspore", "1.8", "gpu"), false},
+	}
+
+	for i, c := range cases {
+		if err := s.Check(c.compute); (err == nil) != c.valid {
+			t.Errorf("case %d: err = %v, want valid = %v", i, err, c.valid)
+		}
+	}
+
+	if v := s.List(); len(v.Engines) != 1 || len(v.Flavors) != 1 {
+		t.Errorf("catalog = %+v", v)
+	}
+}
+
+func TestComputeCatalogRefreshed(t *testing.T) {
+	c := inmemory.NewCompute()
+	c.SetCatalog(domain.ComputeCatalog{
+		Engines: []domain.ComputeEngine{
+			{Type: "mindspore", Versions: []string{"1.7", "1.8"}},
+			{Type: "pytorch", Versions: []string{"1.8"}},
+		},
+		Flavors: []domain.ComputeFlavorInfo{{Flavor: "cpu"}, {Flavor: "gpu"}},
+	})
+
+	// the config works as the allow list.
+	s := NewComputeService(c, &ComputeConfig{
+		Engines:         []ComputeEngineConfig{{Type: "mindspore", Versions: []string{"1.8", "2.0"}}},
+		RefreshInterval: 3600,
+	}, logrus.NewEntry(logrus.StandardLogger()))
+
+	v := s.List()
+	if len(v.Engines) != 1 || len(v.Engines[0].Versions) != 1 || v.Engines[0].Versions[0] != "1.8" {
+		t.Errorf("engines = %+v, want mindspore 1.8 only", v.Engines)
+	}
+
+	if len(v.Flavors) != 2 {
+		t.Errorf("flavors = %+v, want all the ones of platform", v.Flavors)
+	}
+
+	if err := s.Check(newTestCompute(t, "mindspore", "1.8", "gpu")); err != nil {
+		t.Errorf("check failed, err:%v", err)
+	}
+
+	if err := s.Check(newTestCompute(t, "mindspore", "2.0", "gpu")); err == nil {
+		t.Errorf("the version unsupported by platform passed the check")
+	}
+
+	// the catalog is kept if refreshing failed.
+	c.Fail("ListFlavors", errors.New("unavailable"))
+	s.(*computeService).refresh()
+
+	if v := s.List(); len(v.Flavors) != 2 {
+		t.Errorf("flavors = %+v after failed refreshing", v.Flavors)
+	}
+}
+
+func TestComputeCatalogCustomImage(t *testing.T) {
+	newImage := func(url string) *domain.Compute {
+		c := newTestCompute(t, "any", "any", "cpu")
+		c.Type, c.Version = nil, nil
+
+		v, err := domain.NewImageURL(url)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		c.Image = &domain.CustomImage{URL: v}
+
+		return c
+	}
+
+	log := logrus.NewEntry(logrus.StandardLogger())
+	image := "swr.cn-north-4.myhuaweicloud.com/xihe/train:v1"
+
+	// the custom image is not allowed by default.
+	s := NewComputeService(nil, &ComputeConfig{}, log)
+	if err := s.Check(newImage(image)); err == nil {
+		t.Errorf("the custom image is allowed without registries")
+	}
+
+	s = NewComputeService(nil, &ComputeConfig{
+		Engines:         []ComputeEngineConfig{{Type: "mindspore", Versions: []string{"1.8"}}},
+		ImageRegistries: []string{"swr.cn-north-4.myhuaweicloud.com"},
+	}, log)
+
+	if err := s.Check(newImage(image)); err != nil {
+		t.Errorf("check failed, err:%v", err)
+	}
+
+	if err := s.Check(newImage("docker.io/library/python:3.9")); err == nil {
+		t.Errorf("the image of unlisted registry is allowed")
+	}
+}
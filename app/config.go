@@ -0,0 +1,158 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/opensourceways/xihe-training-center/domain"
+)
+
+type WaitingConfig struct {
+	// Interval specifies the interval of second between two checks
+	// of the inputs of trainings which are waiting for inputs.
+	Interval int `json:"interval"`
+
+	// Timeout specifies the max seconds which a training can wait for
+	// its inputs. The training will fail if the inputs are still
+	// not ready after that.
+	Timeout int `json:"timeout"`
+
+	// Retention specifies the seconds to keep the training after it
+	// stops waiting and is finished, so that it can still be got by
+	// its id within that time. It is one day by default.
+	Retention int `json:"retention"`
+}
+
+func (cfg *WaitingConfig) SetDefault() {
+	if cfg.Interval <= 0 {
+		cfg.Interval = 60
+	}
+
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = 24 * 3600
+	}
+
+	if cfg.Retention <= 0 {
+		cfg.Retention = 24 * 3600
+	}
+}
+
+func (cfg *WaitingConfig) interval() time.Duration {
+	return time.Duration(cfg.Interval) * time.Second
+}
+
+func (cfg *WaitingConfig) timeout() time.Duration {
+	return time.Duration(cfg.Timeout) * time.Second
+}
+
+func (cfg *WaitingConfig) retention() time.Duration {
+	return time.Duration(cfg.Retention) * time.Second
+}
+
+type SyncLockConfig struct {
+	// Holder is the identity of this instance when it holds the sync lock.
+	// It is "hostname-pid" by default.
+	Holder string `json:"holder"`
+
+	// LeaseDuration specifies the seconds of lease of sync lock.
+	// The lease is renewed periodically during syncing, and the
+	// lock can be taken over by other instances once it expired.
+	LeaseDuration int `json:"lease_duration"`
+}
+
+func (cfg *SyncLockConfig) SetDefault() {
+	if cfg.Holder == "" {
+		host, _ := os.Hostname()
+		cfg.Holder = fmt.Sprintf("%s-%d", host, os.Getpid())
+	}
+
+	if cfg.LeaseDuration <= 0 {
+		cfg.LeaseDuration = 300
+	}
+}
+
+func (cfg *SyncLockConfig) leaseDuration() time.Duration {
+	return time.Duration(cfg.LeaseDuration) * time.Second
+}
+
+type ComputeConfig struct {
+	// Engines are the engines which can be used by trainings.
+	Engines []ComputeEngineConfig `json:"engines"`
+
+	// Flavors are the flavors which can be used by trainings.
+	Flavors []ComputeFlavorConfig `json:"flavors"`
+
+	// ImageRegistries are the registries, such as swr.cn-north-4.myhuaweicloud.com,
+	// of the custom images which can be used. The custom image is not
+	// allowed if it is empty.
+	ImageRegistries []string `json:"image_registries"`
+
+	// RefreshInterval specifies the interval of second to refresh the
+	// catalog from the training platform. The catalog is not refreshed
+	// if it is 0. When refreshing, the engines and flavors above work
+	// as the allow list if they are not empty.
+	RefreshInterval int `json:"refresh_interval"`
+}
+
+func (cfg *ComputeConfig) Validate() error {
+	for i := range cfg.Engines {
+		if v := &cfg.Engines[i]; v.Type == "" || len(v.Versions) == 0 {
+			return errors.New("invalid compute engine, type and versions must be set")
+		}
+	}
+
+	for i := range cfg.Flavors {
+		if cfg.Flavors[i].Flavor == "" {
+			return errors.New("invalid compute flavor, flavor must be set")
+		}
+	}
+
+	for _, v := range cfg.ImageRegistries {
+		if v == "" {
+			return errors.New("empty image registry")
+		}
+	}
+
+	return nil
+}
+
+func (cfg *ComputeConfig) refreshInterval() time.Duration {
+	return time.Duration(cfg.RefreshInterval) * time.Second
+}
+
+func (cfg *ComputeConfig) catalog() domain.ComputeCatalog {
+	c := domain.ComputeCatalog{
+		Engines: make([]domain.ComputeEngine, len(cfg.Engines)),
+		Flavors: make([]domain.ComputeFlavorInfo, len(cfg.Flavors)),
+
+		ImageRegistries: cfg.ImageRegistries,
+	}
+
+	for i := range cfg.Engines {
+		c.Engines[i] = domain.ComputeEngine(cfg.Engines[i])
+	}
+
+	for i := range cfg.Flavors {
+		c.Flavors[i] = domain.ComputeFlavorInfo(cfg.Flavors[i])
+	}
+
+	return c
+}
+
+type ComputeEngineConfig struct {
+	Type     string   `json:"type"`
+	Versions []string `json:"versions"`
+}
+
+type ComputeFlavorConfig struct {
+	Flavor string `json:"flavor"`
+	Desc   string `json:"desc"`
+}
+
+// ConfigService returns the effective config of service
+// in which the secrets are masked.
+type ConfigService interface {
+	Get() map[string]interface{}
+}
@@ -0,0 +1,144 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+const (
+	healthStatusOK   = "ok"
+	healthStatusFail = "fail"
+)
+
+type HealthConfig struct {
+	// Timeout specifies the max seconds of each check.
+	Timeout int `json:"timeout"`
+}
+
+func (cfg *HealthConfig) SetDefault() {
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = 5
+	}
+}
+
+func (cfg *HealthConfig) timeout() time.Duration {
+	return time.Duration(cfg.Timeout) * time.Second
+}
+
+// HealthCheck checks whether the service or one of its dependencies works.
+type HealthCheck struct {
+	Name  string
+	Check func(context.Context) error
+}
+
+type HealthCheckDTO struct {
+	Name     string `json:"name"`
+	Status   string `json:"status"`
+	Error    string `json:"error,omitempty"`
+	Duration int64  `json:"duration_ms"`
+}
+
+type HealthDTO struct {
+	Status string           `json:"status"`
+	Checks []HealthCheckDTO `json:"checks"`
+}
+
+func (dto *HealthDTO) IsHealthy() bool {
+	return dto.Status == healthStatusOK
+}
+
+type HealthService interface {
+	// Liveness reports whether the service is working.
+	Liveness() HealthDTO
+
+	// Readiness reports whether the dependencies are available.
+	Readiness() HealthDTO
+}
+
+func NewHealthService(liveness, readiness []HealthCheck, cfg *HealthConfig) HealthService {
+	return &healthService{
+		liveness:  liveness,
+		readiness: readiness,
+		timeout:   cfg.timeout(),
+	}
+}
+
+type healthService struct {
+	liveness  []HealthCheck
+	readiness []HealthCheck
+	timeout   time.Duration
+}
+
+func (s *healthService) Liveness() HealthDTO {
+	return s.check(s.liveness)
+}
+
+func (s *healthService) Readiness() HealthDTO {
+	return s.check(s.readiness)
+}
+
+// check runs the checks concurrently, and the result
+// is failed if any of them fails.
+func (s *healthService) check(checks []HealthCheck) HealthDTO {
+	r := HealthDTO{
+		Status: healthStatusOK,
+		Checks: make([]HealthCheckDTO, len(checks)),
+	}
+
+	var wg sync.WaitGroup
+
+	for i := range checks {
+		wg.Add(1)
+
+		go func(i int) {
+			defer wg.Done()
+
+			r.Checks[i] = s.checkOne(&checks[i])
+		}(i)
+	}
+
+	wg.Wait()
+
+	for i := range r.Checks {
+		if r.Checks[i].Status != healthStatusOK {
+			r.Status = healthStatusFail
+		}
+	}
+
+	return r
+}
+
+func (s *healthService) checkOne(c *HealthCheck) HealthCheckDTO {
+	ctx, cancel := context.WithTimeout(context.Background(), s.timeout)
+	defer cancel()
+
+	start := time.Now()
+
+	// the check may not respect the context, so don't wait for it after timeout.
+	done := make(chan error, 1)
+	go func() {
+		done <- c.Check(ctx)
+	}()
+
+	var err error
+	select {
+	case err = <-done:
+	case <-ctx.Done():
+		err = errors.New("timeout")
+	}
+
+	r := HealthCheckDTO{
+		Name:     c.Name,
+		Status:   healthStatusOK,
+		Duration: time.Since(start).Milliseconds(),
+	}
+
+	if err != nil {
+		r.Status = healthStatusFail
+		r.Error = err.Error()
+	}
+
+	return r
+}
@@ -0,0 +1,44 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestHealthReportsEachCheck(t *testing.T) {
+	cfg := HealthConfig{Timeout: 1}
+
+	ok := HealthCheck{Name: "ok", Check: func(context.Context) error { return nil }}
+	failed := HealthCheck{Name: "failed", Check: func(context.Context) error {
+		return errors.New("unreachable")
+	}}
+	// the check which doesn't respect the context.
+	blocked := HealthCheck{Name: "blocked", Check: func(context.Context) error {
+		select {}
+	}}
+
+	s := NewHealthService([]HealthCheck{ok}, []HealthCheck{ok, failed, blocked}, &cfg)
+
+	if v := s.Liveness(); !v.IsHealthy() || len(v.Checks) != 1 {
+		t.Fatalf("liveness should be healthy, got %+v", v)
+	}
+
+	v := s.Readiness()
+	if v.IsHealthy() {
+		t.Fatal("readiness should fail if any check fails")
+	}
+
+	want := []struct{ name, status, err string }{
+		{"ok", healthStatusOK, ""},
+		{"failed", healthStatusFail, "unreachable"},
+		{"blocked", healthStatusFail, "timeout"},
+	}
+
+	for i, w := range want {
+		c := v.Checks[i]
+		if c.Name != w.name || c.Status != w.status || c.Error != w.err {
+			t.Errorf("check %d: want %v, got %+v", i, w, c)
+		}
+	}
+}
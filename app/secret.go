@@ -0,0 +1,141 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/opensourceways/xihe-training-center/domain"
+	"github.com/opensourceways/xihe-training-center/domain/secret"
+)
+
+var errSecretUnsupported = errors.New("secret is not supported")
+
+type SecretSaveCmd struct {
+	Owner domain.Account
+	Name  domain.SecretName
+	Value string
+}
+
+func (cmd *SecretSaveCmd) Validate() error {
+	if cmd.Owner == nil || cmd.Name == nil || cmd.Value == "" {
+		return errors.New("invalid cmd of saving secret")
+	}
+
+	return nil
+}
+
+type SecretDTO struct {
+	Name      string `json:"name"`
+	UpdatedAt int64  `json:"updated_at"`
+}
+
+// SecretService manages the secrets of users. The value of secret
+// can't be read by the service, and it is only injected to the env
+// of training when submitting it.
+type SecretService interface {
+	// Save creates the secret or updates its value if it exists.
+	Save(*SecretSaveCmd) error
+	List(owner domain.Account) ([]SecretDTO, error)
+	Delete(owner domain.Account, name domain.SecretName) error
+}
+
+// NewSecretService returns the service of secrets, the secret
+// is not supported if s is nil.
+func NewSecretService(s secret.Secret) SecretService {
+	return secretService{s}
+}
+
+type secretService struct {
+	s secret.Secret
+}
+
+func (s secretService) Save(cmd *SecretSaveCmd) error {
+	if s.s == nil {
+		return errSecretUnsupported
+	}
+
+	return s.s.Save(&domain.Secret{
+		Owner:     cmd.Owner,
+		Name:      cmd.Name,
+		Value:     cmd.Value,
+		UpdatedAt: time.Now().Unix(),
+	})
+}
+
+func (s secretService) List(owner domain.Account) ([]SecretDTO, error) {
+	if s.s == nil {
+		return nil, errSecretUnsupported
+	}
+
+	v, err := s.s.List(owner)
+	if err != nil {
+		return nil, err
+	}
+
+	r := make([]SecretDTO, len(v))
+	for i := range v {
+		r[i] = SecretDTO{
+			Name:      v[i].Name.SecretName(),
+			UpdatedAt: v[i].UpdatedAt,
+		}
+	}
+
+	return r, nil
+}
+
+func (s secretService) Delete(owner domain.Account, name domain.SecretName) error {
+	if s.s == nil {
+		return errSecretUnsupported
+	}
+
+	return s.s.Delete(owner, name)
+}
+
+// checkSecretEnv checks whether the secrets referenced by the env exist.
+func (s *trainingService) checkSecretEnv(t *domain.UserTraining) error {
+	_, err := s.resolveSecretEnv(t)
+
+	return err
+}
+
+// resolveSecretEnv returns the env which includes the values of secrets.
+// It should be called only when submitting the training, so that the
+// values will not be kept in memory.
+func (s *trainingService) resolveSecretEnv(t *domain.UserTraining) ([]domain.KeyValue, error) {
+	if len(t.SecretEnv) == 0 {
+		return t.Env, nil
+	}
+
+	if s.ss == nil {
+		return nil, errSecretUnsupported
+	}
+
+	env := make([]domain.KeyValue, 0, len(t.Env)+len(t.SecretEnv))
+	env = append(env, t.Env...)
+
+	for i := range t.SecretEnv {
+		item := &t.SecretEnv[i]
+
+		v, err := s.ss.Find(t.User, item.Secret)
+		if err != nil {
+			if secret.IsErrorSecretNotExists(err) {
+				err = secret.NewErrorSecretNotExists(fmt.Errorf(
+					"secret: %s of env: %s does not exist",
+					item.Secret.SecretName(), item.Key.CustomizedKey(),
+				))
+			}
+
+			return nil, err
+		}
+
+		value, err := domain.NewCustomizedValue(v.Value)
+		if err != nil {
+			return nil, err
+		}
+
+		env = append(env, domain.KeyValue{Key: item.Key, Value: value})
+	}
+
+	return env, nil
+}
@@ -2,62 +2,292 @@ package app
 
 import (
 	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
 
 	"github.com/opensourceways/xihe-training-center/domain"
 	"github.com/opensourceways/xihe-training-center/domain/platform"
 	"github.com/opensourceways/xihe-training-center/domain/synclock"
 	"github.com/opensourceways/xihe-training-center/domain/training"
+	"github.com/opensourceways/xihe-training-center/metrics"
 	"github.com/opensourceways/xihe-training-center/utils"
-	"github.com/sirupsen/logrus"
 )
 
-func newSyncService(
+const (
+	syncProgressDone    = "done"
+	syncProgressFailed  = "failed"
+	syncProgressWaiting = "waiting"
+	syncProgressSyncing = "syncing"
+)
+
+type ProjectSyncCmd struct {
+	Owner       domain.Account
+	RepoId      string
+	ProjectName domain.ProjectName
+
+	// Ref is the branch or tag to sync. It is the default branch if empty.
+	Ref string
+
+	// Commit is the commit to sync. It is the latest commit of Ref if empty.
+	Commit string
+
+	// CodeDir is the directory to sync. The whole repo is synced if empty.
+	CodeDir string
+}
+
+type ProjectSyncDTO struct {
+	Status     string `json:"status"`
+	LastCommit string `json:"last_commit"`
+
+	// The fields below describe the sync in progress started by this instance.
+	Commit    string `json:"commit,omitempty"`
+	Progress  string `json:"progress,omitempty"`
+	StartedAt int64  `json:"started_at,omitempty"`
+	Error     string `json:"error,omitempty"`
+}
+
+type ProjectService interface {
+	// Sync starts to sync the project in background.
+	Sync(*ProjectSyncCmd) (ProjectSyncDTO, error)
+
+	// SyncAndWait syncs the project and waits until it is done.
+	// It will reuse the in-flight sync of the same commit.
+	SyncAndWait(*ProjectSyncCmd) (commit string, err error)
+
+	GetSyncStatus(owner domain.Account, repoId, ref string) (ProjectSyncDTO, error)
+}
+
+func NewProjectService(
 	h training.Training,
 	p platform.Platform,
 	log *logrus.Entry,
 	lock synclock.RepoSyncLock,
-) *syncService {
+	cfg *SyncLockConfig,
+) ProjectService {
 	return &syncService{
-		h:    h,
-		p:    p,
-		lock: lock,
-		log:  log,
+		h:     h,
+		p:     p,
+		cfg:   *cfg,
+		lock:  lock,
+		log:   log,
+		tasks: make(map[string]*syncTask),
 	}
 }
 
+type syncTask struct {
+	commit    string
+	codeDir   string
+	progress  string
+	startedAt int64
+	err       error
+	done      chan struct{}
+}
+
 type syncService struct {
 	log  *logrus.Entry
 	h    training.Training
+	cfg  SyncLockConfig
 	lock synclock.RepoSyncLock
 	p    platform.Platform
+
+	// tasks saves the unfinished sync task of each repo.
+	mu    sync.Mutex
+	tasks map[string]*syncTask
 }
 
-func (s *syncService) checkResourceReady(i *domain.ResourceRef) error {
-	c, err := s.h.GetRepoSyncedCommit(i)
+func (s *syncService) Sync(cmd *ProjectSyncCmd) (dto ProjectSyncDTO, err error) {
+	info, synced, err := s.prepare(cmd)
 	if err != nil {
-		return err
+		return
+	}
+
+	if !synced {
+		s.startSync(&info)
+	}
+
+	if dto, err = s.GetSyncStatus(cmd.Owner, cmd.RepoId, cmd.Ref); err != nil {
+		return
 	}
 
-	if c == "" {
-		return errors.New("not ready")
+	if synced {
+		dto.Commit = info.Commit
+		dto.Progress = syncProgressDone
+		dto.StartedAt = 0
+		dto.Error = ""
 	}
 
-	lastCommit, err := s.p.GetLastCommit(i.RepoId)
+	return
+}
+
+func (s *syncService) SyncAndWait(cmd *ProjectSyncCmd) (string, error) {
+	info, synced, err := s.prepare(cmd)
+	if err != nil || synced {
+		return info.Commit, err
+	}
+
+	t := s.startSync(&info)
+
+	<-t.done
+
+	return info.Commit, t.err
+}
+
+func (s *syncService) GetSyncStatus(owner domain.Account, repoId, ref string) (
+	dto ProjectSyncDTO, err error,
+) {
+	c, err := s.lock.Find(owner, repoId, ref)
 	if err != nil {
-		return err
+		if !synclock.IsRepoSyncLockNotExist(err) {
+			return
+		}
+
+		err = nil
+	} else {
+		if c.Status != nil {
+			dto.Status = c.Status.RepoSyncStatus()
+		}
+		dto.LastCommit = c.LastCommit
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if t, ok := s.tasks[syncTaskKey(owner, repoId, ref)]; ok {
+		dto.Commit = t.commit
+		dto.Progress = t.progress
+		dto.StartedAt = t.startedAt
+
+		if t.err != nil {
+			dto.Error = t.err.Error()
+		}
+	}
+
+	return
+}
+
+func syncTaskKey(owner domain.Account, repoId, ref string) string {
+	return owner.Account() + "/" + repoId + "@" + ref
+}
+
+func (s *syncService) prepare(cmd *ProjectSyncCmd) (
+	info training.ProjectInfo, synced bool, err error,
+) {
+	commit := cmd.Commit
+	if commit == "" {
+		if commit, err = s.p.GetLastCommit(cmd.RepoId, cmd.Ref); err != nil {
+			return
+		}
+
+		if commit == "" {
+			err = errors.New("empty project")
+
+			return
+		}
 	}
 
-	if string(c) != lastCommit {
-		return errors.New("not ready")
+	info = training.ProjectInfo{
+		Name:    cmd.ProjectName,
+		Owner:   cmd.Owner,
+		RepoId:  cmd.RepoId,
+		Ref:     cmd.Ref,
+		Commit:  commit,
+		CodeDir: cmd.CodeDir,
 	}
 
-	return nil
+	// the snapshot of a commit is immutable, so it can be reused directly.
+	synced, err = s.h.IsProjectSynced(&info)
+
+	return
+}
+
+// startSync starts a sync task or returns the in-flight one of the same
+// commit and directory.
+// The syncs of different commits of a ref of repo will run one by one.
+func (s *syncService) startSync(info *training.ProjectInfo) *syncTask {
+	key := syncTaskKey(info.Owner, info.RepoId, info.Ref)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var prev chan struct{}
+
+	if t, ok := s.tasks[key]; ok && !t.isDone() {
+		if t.commit == info.Commit && t.codeDir == info.CodeDir {
+			return t
+		}
+
+		prev = t.done
+	}
+
+	t := &syncTask{
+		commit:    info.Commit,
+		codeDir:   info.CodeDir,
+		progress:  syncProgressWaiting,
+		startedAt: time.Now().Unix(),
+		done:      make(chan struct{}),
+	}
+	s.tasks[key] = t
+
+	v := *info
+
+	go func() {
+		if prev != nil {
+			<-prev
+		}
+
+		err := s.doSync(&v, t)
+
+		s.mu.Lock()
+		t.err = err
+		if err == nil {
+			t.progress = syncProgressDone
+		} else {
+			t.progress = syncProgressFailed
+		}
+
+		// the waiters hold the task, so it can be removed safely.
+		// the result of sync is kept by the sync lock.
+		if s.tasks[key] == t {
+			delete(s.tasks, key)
+		}
+		s.mu.Unlock()
+
+		close(t.done)
+	}()
+
+	return t
 }
 
-func (s *syncService) syncProject(
-	owner domain.Account, repoName domain.ProjectName, repoId string,
-) (syncErr error) {
-	c, err := s.lock.Find(owner, repoId)
+func (t *syncTask) isDone() bool {
+	select {
+	case <-t.done:
+		return true
+	default:
+		return false
+	}
+}
+
+func (s *syncService) setProgress(t *syncTask, progress string) {
+	s.mu.Lock()
+	t.progress = progress
+	s.mu.Unlock()
+}
+
+func (s *syncService) doSync(info *training.ProjectInfo, t *syncTask) (syncErr error) {
+	// it may be synced by the previous task.
+	synced, err := s.h.IsProjectSynced(info)
+	if err != nil || synced {
+		return err
+	}
+
+	s.setProgress(t, syncProgressSyncing)
+
+	owner, repoId := info.Owner, info.RepoId
+
+	c, err := s.lock.Find(owner, repoId, info.Ref)
 	if err != nil {
 		if !synclock.IsRepoSyncLockNotExist(err) {
 			return err
@@ -65,42 +295,62 @@ func (s *syncService) syncProject(
 
 		c.Owner = owner
 		c.RepoId = repoId
+		c.Ref = info.Ref
 	}
 
-	if c.Status != nil && !c.Status.IsDone() {
-		return errors.New("can't sync")
-	}
+	now := time.Now()
 
-	lastCommit, err := s.p.GetLastCommit(repoId)
-	if err != nil {
-		return err
-	}
+	if c.IsLocked() {
+		if !c.IsExpired(now.Unix()) {
+			return fmt.Errorf("can't sync, it is being synced by %s", c.Holder)
+		}
 
-	if c.LastCommit == lastCommit {
-		return nil
+		s.log.Warnf(
+			"take over the expired sync lock of repo: %s:%s held by %s",
+			owner.Account(), repoId, c.Holder,
+		)
 	}
 
 	// try lock
 	c.Status = domain.RepoSyncStatusRunning
+	c.Holder = s.cfg.Holder
+	c.Expiry = now.Add(s.cfg.leaseDuration()).Unix()
 	c, err = s.lock.Save(&c)
 	if err != nil {
+		if synclock.IsErrorConcurrentUpdating(err) {
+			return errors.New("can't sync, it is being synced by others")
+		}
+
 		return err
 	}
 
+	lease := newSyncLease(s.lock, &c, s.cfg.leaseDuration(), s.log)
+
 	// do sync
-	repoURL := s.p.GetCloneURL(owner.Account(), repoName.ProjectName())
-	lastCommit, syncErr = s.h.SyncProject(&training.ProjectInfo{
-		Name:        repoName,
-		Owner:       owner,
-		RepoId:      repoId,
-		RepoURL:     repoURL,
-		StartCommit: c.LastCommit,
-	})
+	info.RepoURL = s.p.GetCloneURL(owner.Account(), info.Name.ProjectName())
+	info.RepoCredential = s.p.GetCloneCredential()
+	if info.CodeDir == "" {
+		info.StartCommit = c.LastCommit
+	}
+	start := time.Now()
+	lastCommit, syncErr := s.h.SyncProject(info)
+	metrics.ObserveProjectSync(start, syncErr)
 
-	if syncErr == nil {
+	c, held := lease.release()
+	if !held {
+		// the lock has been released by force or taken over by others,
+		// it can't be unlocked by this instance.
+		return
+	}
+
+	// LastCommit is the base of next sync of whole repo,
+	// so it can't be changed by the partial sync.
+	if syncErr == nil && info.CodeDir == "" {
 		c.LastCommit = lastCommit
 	}
 	c.Status = domain.RepoSyncStatusDone
+	c.Holder = ""
+	c.Expiry = 0
 
 	// unlock
 	err = utils.Retry(func() error {
@@ -110,16 +360,21 @@ func (s *syncService) syncProject(
 				"unlock sync repo failed, err:%s",
 				err.Error(),
 			)
+
+			if synclock.IsErrorConcurrentUpdating(err) {
+				// the lock has been taken over, don't retry.
+				return nil
+			}
 		}
 
 		return err
 	})
 	if err != nil {
 		s.log.Errorf(
-			"dead lock happened for repo: %s:%s",
+			"unlock failed for repo: %s:%s, it will be taken over after the lease expired",
 			owner.Account(), repoId,
 		)
 	}
 
-	return syncErr
+	return
 }
@@ -0,0 +1,190 @@
+package app
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/opensourceways/xihe-training-center/domain"
+	"github.com/opensourceways/xihe-training-center/domain/synclock"
+)
+
+// syncLease keeps the lease of sync lock alive until it is stopped.
+type syncLease struct {
+	log      *logrus.Entry
+	repo     synclock.RepoSyncLock
+	duration time.Duration
+
+	mu   sync.Mutex
+	lock domain.RepoSyncLock
+	lost bool
+
+	stop    chan struct{}
+	stopped chan struct{}
+}
+
+func newSyncLease(
+	repo synclock.RepoSyncLock, lock *domain.RepoSyncLock,
+	duration time.Duration, log *logrus.Entry,
+) *syncLease {
+	l := &syncLease{
+		log:      log,
+		repo:     repo,
+		lock:     *lock,
+		duration: duration,
+		stop:     make(chan struct{}),
+		stopped:  make(chan struct{}),
+	}
+
+	go l.keepAlive()
+
+	return l
+}
+
+func (l *syncLease) keepAlive() {
+	defer close(l.stopped)
+
+	ticker := time.NewTicker(l.duration / 3)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if !l.renew() {
+				return
+			}
+
+		case <-l.stop:
+			return
+		}
+	}
+}
+
+func (l *syncLease) renew() bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	c := l.lock
+	c.Expiry = time.Now().Add(l.duration).Unix()
+
+	v, err := l.repo.Save(&c)
+	if err == nil {
+		l.lock = v
+
+		return true
+	}
+
+	if synclock.IsErrorConcurrentUpdating(err) {
+		// the lock has been released by force or taken over.
+		l.lost = true
+
+		l.log.Errorf(
+			"lost the sync lock of repo: %s:%s",
+			c.Owner.Account(), c.RepoId,
+		)
+
+		return false
+	}
+
+	l.log.Errorf("renew sync lock failed, err:%s", err.Error())
+
+	return true
+}
+
+// release stops renewing the lease and returns the latest lock.
+// It returns false if the lock has been lost.
+func (l *syncLease) release() (domain.RepoSyncLock, bool) {
+	close(l.stop)
+	<-l.stopped
+
+	return l.lock, !l.lost
+}
+
+// SyncLockDTO is the sync lock of a ref of repo shown to the administrator.
+type SyncLockDTO struct {
+	Owner      string `json:"owner"`
+	RepoId     string `json:"repo_id"`
+	Ref        string `json:"ref"`
+	Status     string `json:"status"`
+	Holder     string `json:"holder"`
+	Expiry     int64  `json:"expiry"`
+	Expired    bool   `json:"expired"`
+	Version    int    `json:"version"`
+	LastCommit string `json:"last_commit"`
+}
+
+type SyncLockService interface {
+	Get(owner domain.Account, repoId, ref string) (SyncLockDTO, error)
+
+	// ForceRelease releases the lock no matter who holds it.
+	ForceRelease(owner domain.Account, repoId, ref string) error
+}
+
+func NewSyncLockService(lock synclock.RepoSyncLock, log *logrus.Entry) SyncLockService {
+	return syncLockService{
+		log:  log,
+		lock: lock,
+	}
+}
+
+type syncLockService struct {
+	log  *logrus.Entry
+	lock synclock.RepoSyncLock
+}
+
+func (s syncLockService) Get(owner domain.Account, repoId, ref string) (
+	dto SyncLockDTO, err error,
+) {
+	c, err := s.lock.Find(owner, repoId, ref)
+	if err != nil {
+		return
+	}
+
+	dto.Owner = owner.Account()
+	dto.RepoId = c.RepoId
+	dto.Ref = c.Ref
+	dto.Holder = c.Holder
+	dto.Expiry = c.Expiry
+	dto.Version = c.Version
+	dto.LastCommit = c.LastCommit
+
+	if c.Status != nil {
+		dto.Status = c.Status.RepoSyncStatus()
+	}
+
+	if c.IsLocked() {
+		dto.Expired = c.IsExpired(time.Now().Unix())
+	}
+
+	return
+}
+
+func (s syncLockService) ForceRelease(owner domain.Account, repoId, ref string) error {
+	c, err := s.lock.Find(owner, repoId, ref)
+	if err != nil {
+		return err
+	}
+
+	if !c.IsLocked() {
+		return nil
+	}
+
+	s.log.Infof(
+		"release the sync lock of repo: %s:%s@%s held by %s by force",
+		owner.Account(), repoId, ref, c.Holder,
+	)
+
+	c.Status = domain.RepoSyncStatusDone
+	c.Holder = ""
+	c.Expiry = 0
+
+	if _, err = s.lock.Save(&c); err != nil {
+		if synclock.IsErrorConcurrentUpdating(err) {
+			return errors.New("the lock is changed, try again")
+		}
+	}
+
+	return err
+}
@@ -0,0 +1,61 @@
+package app
+
+import (
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+func TestSyncTaskIsRemovedWhenDone(t *testing.T) {
+	env := newTestEnv(t, 10)
+	env.ts.SetSyncDelay(100 * time.Millisecond)
+
+	s := NewProjectService(
+		env.ts, env.pf, logrus.NewEntry(logrus.StandardLogger()), env.lock,
+		&SyncLockConfig{Holder: "test", LeaseDuration: 300},
+	).(*syncService)
+
+	c := newTestCmd(t, "")
+	cmd := &ProjectSyncCmd{
+		Owner:       c.User,
+		RepoId:      c.ProjectRepoId,
+		ProjectName: c.ProjectName,
+	}
+
+	dto, err := s.Sync(cmd)
+	if err != nil {
+		t.Fatalf("sync failed, err:%v", err)
+	}
+
+	if dto.Commit != testCommit || dto.Progress == "" {
+		t.Errorf("status = %+v, want the sync in progress of %s", dto, testCommit)
+	}
+
+	// the waiter reuses the in-flight task and reads its result.
+	commit, err := s.SyncAndWait(cmd)
+	if err != nil || commit != testCommit {
+		t.Fatalf("sync and wait = %s, %v, want %s", commit, err, testCommit)
+	}
+
+	s.mu.Lock()
+	n := len(s.tasks)
+	s.mu.Unlock()
+
+	if n != 0 {
+		t.Errorf("%d tasks are kept after done, want 0", n)
+	}
+
+	if v := env.ts.SyncNum(); v != 1 {
+		t.Errorf("synced %d times, want 1", v)
+	}
+
+	dto, err = s.GetSyncStatus(cmd.Owner, cmd.RepoId, cmd.Ref)
+	if err != nil {
+		t.Fatalf("get sync status failed, err:%v", err)
+	}
+
+	if dto.LastCommit != testCommit || dto.Progress != "" {
+		t.Errorf("status = %+v, want the last commit %s only", dto, testCommit)
+	}
+}
@@ -2,21 +2,44 @@ package app
 
 import (
 	"errors"
+	"fmt"
 	"sync"
 
 	"github.com/sirupsen/logrus"
 
 	"github.com/opensourceways/xihe-training-center/domain"
+	"github.com/opensourceways/xihe-training-center/domain/jobowner"
 	"github.com/opensourceways/xihe-training-center/domain/platform"
-	"github.com/opensourceways/xihe-training-center/domain/synclock"
+	"github.com/opensourceways/xihe-training-center/domain/secret"
 	"github.com/opensourceways/xihe-training-center/domain/training"
 	"github.com/opensourceways/xihe-training-center/domain/watch"
+	"github.com/opensourceways/xihe-training-center/metrics"
 )
 
+type errorInvalidCode struct {
+	error
+}
+
+func newErrorInvalidCode(err error) errorInvalidCode {
+	return errorInvalidCode{err}
+}
+
+// IsErrorInvalidCode checks whether the code dir or boot file
+// of training does not exist in the project.
+func IsErrorInvalidCode(err error) bool {
+	_, ok := err.(errorInvalidCode)
+
+	return ok
+}
+
 type TrainingCreateCmd struct {
 	ProjectId  string
 	TrainingId string
 
+	// WaitForInputs specifies whether to wait for the inputs
+	// instead of failing when they are not ready.
+	WaitForInputs bool
+
 	domain.UserTraining
 }
 
@@ -28,7 +51,6 @@ func (cmd *TrainingCreateCmd) Validate() error {
 		cmd.ProjectName != nil &&
 		cmd.Name != nil &&
 		cmd.CodeDir != nil &&
-		cmd.BootFile != nil &&
 		cmd.ProjectId != "" &&
 		cmd.TrainingId != ""
 
@@ -37,7 +59,15 @@ func (cmd *TrainingCreateCmd) Validate() error {
 	}
 
 	c := &cmd.Compute
-	if c.Flavor == nil || c.Type == nil || c.Version == nil {
+	if c.Flavor == nil {
+		return err
+	}
+
+	if c.Image == nil {
+		if c.Type == nil || c.Version == nil || cmd.BootFile == nil {
+			return err
+		}
+	} else if c.Image.URL == nil || c.Image.Command == nil || c.Image.WorkDir == nil {
 		return err
 	}
 
@@ -59,6 +89,22 @@ func (cmd *TrainingCreateCmd) Validate() error {
 		return err
 	}
 
+	if err := cmd.validateParameterRender(); err != nil {
+		return err
+	}
+
+	for i := range cmd.SecretEnv {
+		if v := &cmd.SecretEnv[i]; v.Key == nil || v.Secret == nil {
+			return errors.New("invalid secret env")
+		}
+	}
+
+	for _, v := range cmd.Dependencies {
+		if v == nil || c.Image != nil {
+			return errors.New("invalid dependency")
+		}
+	}
+
 	for i := range cmd.Inputs {
 		v := &cmd.Inputs[i]
 
@@ -70,124 +116,529 @@ func (cmd *TrainingCreateCmd) Validate() error {
 	return nil
 }
 
+func (cmd *TrainingCreateCmd) validateParameterRender() error {
+	p := &cmd.ParameterRender
+	if p.IsFlags() {
+		return nil
+	}
+
+	err := errors.New("invalid parameter render")
+
+	// the other modes are implemented by the generated boot file.
+	if cmd.Compute.Image != nil {
+		return err
+	}
+
+	switch p.Mode.ParameterMode() {
+	case domain.ParameterModeFile.ParameterMode():
+		if p.FileFormat == nil {
+			return err
+		}
+
+	case domain.ParameterModeTemplate.ParameterMode():
+		if p.Template == nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
 type JobInfoDTO struct {
 	JobId     string `json:"job_id"`
 	LogDir    string `json:"log_dir"`
 	AimDir    string `json:"aim_dir"`
 	OutputDir string `json:"output_dir"`
+
+	Status        string           `json:"status"`
+	ProjectCommit string           `json:"project_commit"`
+	Inputs        []InputCommitDTO `json:"inputs"`
+}
+
+// InputCommitDTO is the resolved commit of input which the training runs on.
+type InputCommitDTO struct {
+	Key    string `json:"key"`
+	Commit string `json:"commit"`
 }
 
 type JobDetailDTO struct {
 	Status   string `json:"status"`
 	Duration int    `json:"duration"`
+	Reason   string `json:"reason,omitempty"`
+
+	// ProjectCommit and Inputs are the commits which the training runs on.
+	// Inputs is empty if the training is still waiting for its inputs.
+	ProjectCommit string           `json:"project_commit,omitempty"`
+	Inputs        []InputCommitDTO `json:"inputs,omitempty"`
 }
 
 type TrainingService interface {
 	Create(cmd *TrainingCreateCmd) (JobInfoDTO, error)
 	Delete(jobId string) error
 	Terminate(jobId string) error
+	GetDetail(jobId string) (JobDetailDTO, error)
 	GetLogDownloadURL(jobId string) (string, error)
+
+	// GetOwner returns the user who creates the training.
+	GetOwner(jobId string) (domain.Account, error)
+
+	// SetMaxTrainingNum changes the max num of trainings at runtime.
+	// The trainings beyond it keep running if it is decreased.
+	SetMaxTrainingNum(n int)
+
+	// Exit fails the trainings which are waiting for inputs, because
+	// they are only kept in memory and will be lost after exiting.
+	Exit()
 }
 
 func NewTrainingService(
 	ts training.Training,
 	pf platform.Platform,
+	ps ProjectService,
 	ws watch.WatchService,
+	ss secret.Secret,
+	jo jobowner.JobOwner,
 	log *logrus.Entry,
-	lock synclock.RepoSyncLock,
 	maxTrainingNum int,
+	waitingCfg *WaitingConfig,
 ) TrainingService {
 	t := &trainingService{
 		ts:  ts,
+		pf:  pf,
+		ps:  ps,
 		ws:  ws,
+		ss:  ss,
+		jo:  jo,
 		log: log,
-		ss:  newSyncService(ts, pf, log, lock),
 
 		maxTrainingNum: maxTrainingNum,
+
+		waitingCfg: *waitingCfg,
+		waitings:   make(map[string]*waitingTraining),
 	}
 
 	ws.RegisterTrainingDone(t.callback)
 
+	go t.checkWaitingTrainings()
+
 	return t
 }
 
 type trainingService struct {
-	ss  *syncService
+	ps  ProjectService
 	log *logrus.Entry
+	pf  platform.Platform
 	ts  training.Training
 	ws  watch.WatchService
+	ss  secret.Secret
+	jo  jobowner.JobOwner
 
 	lock           sync.RWMutex
 	currentNum     int
 	maxTrainingNum int
+
+	waitingCfg WaitingConfig
+	waitingNum int
+	waitings   map[string]*waitingTraining
+}
+
+func (s *trainingService) callback(info *watch.TrainingInfo) {
+	s.release()
+
+	s.finishWaitingJob(info.JobId)
+
+	trainingFinished(info.Status, info.Flavor)
 }
 
-func (s *trainingService) callback(*watch.TrainingInfo) {
+// reserve reserves a slot for the training. Only the reservation is
+// serialized, so that the slow works of different trainings can be
+// done in parallel.
+func (s *trainingService) reserve() error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if s.currentNum >= s.maxTrainingNum {
+		return errors.New("too many trainings")
+	}
+
+	s.currentNum++
+	s.updateMetrics()
+
+	return nil
+}
+
+func (s *trainingService) SetMaxTrainingNum(n int) {
+	s.lock.Lock()
+	s.maxTrainingNum = n
+	s.updateMetrics()
+	s.lock.Unlock()
+}
+
+// updateMetrics must be called with holding the lock.
+func (s *trainingService) updateMetrics() {
+	metrics.SetTrainingSlots(s.currentNum, s.waitingNum, s.maxTrainingNum)
+}
+
+func trainingFinished(status domain.TrainingStatus, flavor domain.ComputeFlavor) {
+	v, f := "", ""
+	if status != nil {
+		v = status.TrainingStatus()
+	}
+
+	if flavor != nil {
+		f = flavor.ComputeFlavor()
+	}
+
+	metrics.TrainingFinished(v, f)
+}
+
+func (s *trainingService) release() {
 	s.lock.Lock()
 	s.currentNum--
+	s.updateMetrics()
 	s.lock.Unlock()
 }
 
 func (s *trainingService) Create(cmd *TrainingCreateCmd) (dto JobInfoDTO, err error) {
-	s.lock.Lock()
-	defer s.lock.Unlock()
+	if err = s.reserve(); err != nil {
+		return
+	}
 
-	if s.currentNum >= s.maxTrainingNum {
-		err = errors.New("too many trainings")
+	// the slot will be released when the training is done if it is submitted.
+	submitted := false
+	defer func() {
+		if !submitted {
+			s.release()
+		}
+	}()
 
+	// check the secrets before the slow sync of project.
+	if err = s.checkSecretEnv(&cmd.UserTraining); err != nil {
 		return
 	}
 
-	err = s.ss.syncProject(cmd.User, cmd.ProjectName, cmd.ProjectRepoId)
+	sc := ProjectSyncCmd{
+		Owner:       cmd.User,
+		RepoId:      cmd.ProjectRepoId,
+		ProjectName: cmd.ProjectName,
+		Ref:         cmd.ProjectRef,
+		Commit:      cmd.ProjectCommit,
+	}
+	if cmd.SyncCodeDirOnly {
+		sc.CodeDir = cmd.CodeDir.Directory()
+	}
+
+	cmd.ProjectCommit, err = s.ps.SyncAndWait(&sc)
 	if err != nil {
 		s.log.Debug("sync project failed")
 
 		return
 	}
 
+	if err = s.checkCode(cmd); err != nil {
+		return
+	}
+
+	inputs, err := s.checkInputs(cmd)
+	if err != nil {
+		if !cmd.WaitForInputs {
+			return
+		}
+
+		return s.wait(cmd, err)
+	}
+
+	// only the submitted training uses the resolved commits of inputs.
+	t := *cmd
+	t.Inputs = inputs
+
+	v, err := s.submit(&t)
+	if err != nil {
+		return
+	}
+
+	submitted = true
+
+	dto.JobId = v.JobId
+	dto.LogDir = v.LogDir
+	dto.AimDir = v.AimDir
+	dto.OutputDir = v.OutputDir
+	dto.Status = domain.TrainingStatusRunning.TrainingStatus()
+	dto.ProjectCommit = cmd.ProjectCommit
+	dto.Inputs = toInputCommitDTOs(toJobCommits(&t).Inputs)
+
+	return
+}
+
+// checkCode checks the code of training in the synced snapshot of
+// project, so that the mistake can be found before submitting the job.
+func (s *trainingService) checkCode(cmd *TrainingCreateCmd) error {
+	dir := cmd.CodeDir.Directory()
+
+	empty, err := s.ts.IsCodeDirEmpty(&cmd.UserTraining)
+	if err != nil {
+		return err
+	}
+
+	if empty {
+		return newErrorInvalidCode(fmt.Errorf(
+			"code dir: %s is empty or does not exist at commit: %s",
+			dir, cmd.ProjectCommit,
+		))
+	}
+
+	// the command of custom image may not need the boot file.
+	if cmd.BootFile == nil {
+		return nil
+	}
+
+	exist, err := s.ts.IsBootFileExist(&cmd.UserTraining)
+	if err != nil {
+		return err
+	}
+
+	if !exist {
+		return newErrorInvalidCode(fmt.Errorf(
+			"boot file: %s does not exist in code dir: %s at commit: %s",
+			cmd.BootFile.FilePath(), dir, cmd.ProjectCommit,
+		))
+	}
+
+	return nil
+}
+
+// checkInputs checks whether all the inputs are ready, saves the snapshots
+// of their commits, and returns the copy of inputs in which the commits are
+// resolved. The inputs of cmd are kept as they are, so that the pinned
+// commits are not lost and the ones which are not pinned keep following
+// the latest commits.
+func (s *trainingService) checkInputs(cmd *TrainingCreateCmd) ([]domain.Input, error) {
+	if len(cmd.Inputs) == 0 {
+		return nil, nil
+	}
+
+	r := make([]domain.Input, len(cmd.Inputs))
+
 	for i := range cmd.Inputs {
 		dep := &cmd.Inputs[i].ResourceRef
 
-		if err = s.ss.checkResourceReady(dep); err != nil {
+		c, err := s.checkResourceReady(dep)
+		if err != nil {
 			s.log.Debugf(
 				"check dependent resource:%s failed",
 				dep.ToPath(),
 			)
 
-			return
+			return nil, err
+		}
+
+		r[i] = cmd.Inputs[i]
+		r[i].Commit = c
+	}
+
+	// the snapshots are saved after all the inputs are ready,
+	// so that the training waiting for inputs won't save them
+	// again and again before it can be submitted.
+	for i := range r {
+		dep := &r[i].ResourceRef
+
+		if err := s.ts.SnapshotInput(dep); err != nil {
+			s.log.Errorf(
+				"save snapshot of resource:%s failed, err:%s",
+				dep.ToPath(), err.Error(),
+			)
+
+			return nil, err
+		}
+	}
+
+	return r, nil
+}
+
+// checkResourceReady checks whether the resource has been synced to
+// the specified commit or the latest one, and returns the synced commit.
+func (s *trainingService) checkResourceReady(i *domain.ResourceRef) (string, error) {
+	c, err := s.ts.GetRepoSyncedCommit(i)
+	if err != nil {
+		return "", err
+	}
+
+	if c == "" {
+		return "", errors.New("not ready")
+	}
+
+	if i.Commit != "" {
+		if c != i.Commit {
+			return "", fmt.Errorf(
+				"not ready, the synced commit is %s, not %s", c, i.Commit,
+			)
 		}
+
+		return c, nil
 	}
 
-	v, err := s.ts.Create(&cmd.UserTraining)
+	lastCommit, err := s.pf.GetLastCommit(i.RepoId, "")
 	if err != nil {
+		return "", err
+	}
+
+	if c != lastCommit {
+		return "", errors.New("not ready")
+	}
+
+	return c, nil
+}
+
+// submit must be called with holding a slot reserved.
+func (s *trainingService) submit(cmd *TrainingCreateCmd) (v domain.JobInfo, err error) {
+	// the values of secrets are only in the training which is submitted.
+	t := cmd.UserTraining
+	if t.Env, err = s.resolveSecretEnv(&cmd.UserTraining); err != nil {
 		return
 	}
 
-	dto.JobId = v.JobId
-	dto.LogDir = v.LogDir
-	dto.AimDir = v.AimDir
-	dto.OutputDir = v.OutputDir
+	if v, err = s.ts.Create(&t); err != nil {
+		return
+	}
+
+	// the job has been created, so don't fail even if the owner is not saved.
+	if err := s.jo.Save(v.JobId, cmd.User, toJobCommits(cmd)); err != nil {
+		s.log.Errorf(
+			"save owner of job:%s failed, err:%s", v.JobId, err.Error(),
+		)
+	}
 
 	s.ws.WatchTraining(&watch.TrainingInfo{
 		User:       cmd.User,
 		ProjectId:  cmd.ProjectId,
 		TrainingId: cmd.TrainingId,
+		Flavor:     cmd.Compute.Flavor,
 		JobInfo:    v,
 	})
 
-	s.currentNum++
-
 	return
 }
 
 func (s *trainingService) Delete(jobId string) error {
-	return s.ts.Delete(jobId)
+	if isWaitingJobId(jobId) {
+		return s.deleteWaiting(jobId)
+	}
+
+	return s.deleteJob(jobId)
+}
+
+func (s *trainingService) deleteJob(jobId string) error {
+	if err := s.ts.Delete(jobId); err != nil {
+		return err
+	}
+
+	if err := s.jo.Delete(jobId); err != nil {
+		s.log.Errorf(
+			"delete owner of job:%s failed, err:%s", jobId, err.Error(),
+		)
+	}
+
+	return nil
 }
 
 func (s *trainingService) Terminate(jobId string) error {
+	if isWaitingJobId(jobId) {
+		return s.terminateWaiting(jobId)
+	}
+
 	return s.ts.Terminate(jobId)
 }
 
+func (s *trainingService) GetDetail(jobId string) (dto JobDetailDTO, err error) {
+	if isWaitingJobId(jobId) {
+		if jobId, dto, err = s.getWaiting(jobId); err != nil || jobId == "" {
+			return
+		}
+	}
+
+	v, err := s.ts.GetDetail(jobId)
+	if err != nil {
+		return
+	}
+
+	dto.Status = v.Status.TrainingStatus()
+	dto.Duration = v.Duration
+
+	// the job which was created before the commits are recorded has no record.
+	c, err := s.jo.FindCommits(jobId)
+	if err != nil {
+		if jobowner.IsErrorJobOwnerNotExists(err) {
+			err = nil
+		}
+
+		return
+	}
+
+	dto.ProjectCommit = c.Project
+	dto.Inputs = toInputCommitDTOs(c.Inputs)
+
+	return
+}
+
 func (s *trainingService) GetLogDownloadURL(jobId string) (string, error) {
+	if isWaitingJobId(jobId) {
+		v, detail, err := s.getWaiting(jobId)
+		if err != nil {
+			return "", err
+		}
+
+		if v == "" {
+			return "", fmt.Errorf(
+				"no log, the training is %s, reason:%s",
+				detail.Status, detail.Reason,
+			)
+		}
+
+		jobId = v
+	}
+
 	return s.ts.GetLogDownloadURL(jobId)
 }
+
+func (s *trainingService) GetOwner(jobId string) (domain.Account, error) {
+	if isWaitingJobId(jobId) {
+		return s.getWaitingOwner(jobId)
+	}
+
+	return s.jo.Find(jobId)
+}
+
+// toJobCommits returns the commits of cmd in which the commits
+// of inputs have been resolved.
+func toJobCommits(cmd *TrainingCreateCmd) *jobowner.JobCommits {
+	r := &jobowner.JobCommits{Project: cmd.ProjectCommit}
+
+	if n := len(cmd.Inputs); n > 0 {
+		r.Inputs = make([]jobowner.InputCommit, n)
+
+		for i := range cmd.Inputs {
+			v := &cmd.Inputs[i]
+
+			r.Inputs[i] = jobowner.InputCommit{
+				Key:    v.Key.CustomizedKey(),
+				Commit: v.Commit,
+			}
+		}
+	}
+
+	return r
+}
+
+func toInputCommitDTOs(v []jobowner.InputCommit) []InputCommitDTO {
+	if len(v) == 0 {
+		return nil
+	}
+
+	r := make([]InputCommitDTO, len(v))
+
+	for i := range v {
+		r[i] = InputCommitDTO{Key: v[i].Key, Commit: v[i].Commit}
+	}
+
+	return r
+}
@@ -0,0 +1,906 @@
+package app
+
+import (
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/opensourceways/xihe-training-center/domain"
+	"github.com/opensourceways/xihe-training-center/domain/jobowner"
+	"github.com/opensourceways/xihe-training-center/domain/secret"
+	"github.com/opensourceways/xihe-training-center/infrastructure/inmemory"
+)
+
+const (
+	testRepoId    = "1"
+	testCommit    = "1111111111111111111111111111111111111111"
+	testNewCommit = "2222222222222222222222222222222222222222"
+)
+
+type testEnv struct {
+	pf   *inmemory.Platform
+	ts   *inmemory.Training
+	ws   *inmemory.WatchService
+	lock *inmemory.RepoSyncLock
+	ss   *inmemory.Secret
+	jo   *inmemory.JobOwner
+	s    *trainingService
+}
+
+func newTestEnv(t *testing.T, maxTrainingNum int) *testEnv {
+	t.Helper()
+
+	domain.Init(&domain.Config{
+		MaxTrainingNameLength: 50,
+		MinTrainingNameLength: 1,
+		MaxTrainingDescLength: 100,
+	})
+
+	log := logrus.NewEntry(logrus.StandardLogger())
+
+	env := &testEnv{
+		pf:   inmemory.NewPlatform(),
+		ts:   inmemory.NewTraining(),
+		lock: inmemory.NewRepoSyncLock(),
+		ss:   inmemory.NewSecret(),
+		jo:   inmemory.NewJobOwner(),
+	}
+	env.ws = inmemory.NewWatchService(env.ts)
+
+	env.pf.SetLastCommit(testRepoId, testCommit)
+
+	ps := NewProjectService(
+		env.ts, env.pf, log, env.lock,
+		&SyncLockConfig{Holder: "test", LeaseDuration: 300},
+	)
+
+	// the waiting trainings are checked by the test itself.
+	env.s = NewTrainingService(
+		env.ts, env.pf, ps, env.ws, env.ss, env.jo, log, maxTrainingNum,
+		&WaitingConfig{Interval: 3600, Timeout: 3600, Retention: 3600},
+	).(*trainingService)
+
+	return env
+}
+
+func newTestCmd(t *testing.T, trainingId string) *TrainingCreateCmd {
+	t.Helper()
+
+	must := func(err error) {
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	cmd := &TrainingCreateCmd{
+		ProjectId:  "p1",
+		TrainingId: trainingId,
+	}
+
+	var err error
+
+	cmd.User, err = domain.NewAccount("alice")
+	must(err)
+
+	cmd.ProjectRepoId = testRepoId
+
+	cmd.ProjectName, err = domain.NewProjectName("project-demo")
+	must(err)
+
+	cmd.Name, err = domain.NewTrainingName("train")
+	must(err)
+
+	cmd.CodeDir, err = domain.NewDirectory("code")
+	must(err)
+
+	cmd.BootFile, err = domain.NewFilePath("train.py")
+	must(err)
+
+	cmd.Compute.Type, err = domain.NewComputeType("mindspore")
+	must(err)
+
+	cmd.Compute.Version, err = domain.NewComputeVersion("1.8")
+	must(err)
+
+	cmd.Compute.Flavor, err = domain.NewComputeFlavor("cpu")
+	must(err)
+
+	return cmd
+}
+
+func newTestInput(t *testing.T) domain.Input {
+	t.Helper()
+
+	key, err := domain.NewCustomizedKey("data")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	user, err := domain.NewAccount("bob")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return domain.Input{
+		Key: key,
+		ResourceRef: domain.ResourceRef{
+			User:   user,
+			Type:   domain.ResourceTypeDataset,
+			RepoId: "2",
+		},
+	}
+}
+
+func TestCreateSyncsProject(t *testing.T) {
+	env := newTestEnv(t, 10)
+
+	dto, err := env.s.Create(newTestCmd(t, "t1"))
+	if err != nil {
+		t.Fatalf("create failed, err:%v", err)
+	}
+
+	if dto.ProjectCommit != testCommit {
+		t.Errorf("project commit = %s, want %s", dto.ProjectCommit, testCommit)
+	}
+
+	if dto.Status != domain.TrainingStatusRunning.TrainingStatus() {
+		t.Errorf("status = %s, want Running", dto.Status)
+	}
+
+	if v := env.ts.Jobs()[dto.JobId].ProjectCommit; v != testCommit {
+		t.Errorf("the job runs on %s, want %s", v, testCommit)
+	}
+
+	if env.ws.Watching() != 1 {
+		t.Errorf("the training is not watched")
+	}
+
+	// the snapshot of the same commit is reused.
+	if _, err := env.s.Create(newTestCmd(t, "t2")); err != nil {
+		t.Fatalf("create failed, err:%v", err)
+	}
+
+	if n := env.ts.SyncNum(); n != 1 {
+		t.Errorf("synced %d times, want 1", n)
+	}
+
+	// a new commit is synced based on the last one.
+	env.pf.SetLastCommit(testRepoId, testNewCommit)
+
+	dto, err = env.s.Create(newTestCmd(t, "t3"))
+	if err != nil {
+		t.Fatalf("create failed, err:%v", err)
+	}
+
+	if dto.ProjectCommit != testNewCommit {
+		t.Errorf("project commit = %s, want %s", dto.ProjectCommit, testNewCommit)
+	}
+
+	c, err := env.lock.Find(newTestCmd(t, "").User, testRepoId, "")
+	if err != nil {
+		t.Fatalf("find sync lock failed, err:%v", err)
+	}
+
+	if c.LastCommit != testNewCommit || c.IsLocked() {
+		t.Errorf("sync lock = %+v, want unlocked at %s", c, testNewCommit)
+	}
+}
+
+func TestCreatePinnedProjectCommit(t *testing.T) {
+	env := newTestEnv(t, 10)
+
+	cmd := newTestCmd(t, "t1")
+	cmd.ProjectCommit = testNewCommit
+
+	dto, err := env.s.Create(cmd)
+	if err != nil {
+		t.Fatalf("create failed, err:%v", err)
+	}
+
+	if dto.ProjectCommit != testNewCommit {
+		t.Errorf("project commit = %s, want %s", dto.ProjectCommit, testNewCommit)
+	}
+}
+
+func TestCreateWithRef(t *testing.T) {
+	env := newTestEnv(t, 10)
+
+	env.pf.SetRefCommit(testRepoId, "dev", testNewCommit)
+
+	cmd := newTestCmd(t, "t1")
+	cmd.ProjectRef = "dev"
+
+	dto, err := env.s.Create(cmd)
+	if err != nil {
+		t.Fatalf("create failed, err:%v", err)
+	}
+
+	if dto.ProjectCommit != testNewCommit {
+		t.Errorf("project commit = %s, want %s", dto.ProjectCommit, testNewCommit)
+	}
+
+	// the last commit is tracked per ref.
+	c, err := env.lock.Find(cmd.User, testRepoId, "dev")
+	if err != nil || c.LastCommit != testNewCommit {
+		t.Errorf("sync lock of dev = %+v, err = %v", c, err)
+	}
+
+	if _, err := env.lock.Find(cmd.User, testRepoId, ""); err == nil {
+		t.Errorf("the sync lock of default branch is changed")
+	}
+}
+
+func TestCreateSyncsCodeDirOnly(t *testing.T) {
+	env := newTestEnv(t, 10)
+
+	cmd := newTestCmd(t, "t1")
+	cmd.SyncCodeDirOnly = true
+
+	if _, err := env.s.Create(cmd); err != nil {
+		t.Fatalf("create failed, err:%v", err)
+	}
+
+	// the partial snapshot is not the base of the sync of whole repo.
+	c, err := env.lock.Find(cmd.User, testRepoId, "")
+	if err != nil {
+		t.Fatalf("find sync lock failed, err:%v", err)
+	}
+
+	if c.LastCommit != "" || c.IsLocked() {
+		t.Errorf("sync lock = %+v, want unlocked without last commit", c)
+	}
+
+	// the whole repo is synced even if the code dir of the commit is synced.
+	if _, err := env.s.Create(newTestCmd(t, "t2")); err != nil {
+		t.Fatalf("create failed, err:%v", err)
+	}
+
+	if n := env.ts.SyncNum(); n != 2 {
+		t.Errorf("synced %d times, want 2", n)
+	}
+}
+
+func TestCreateChecksCode(t *testing.T) {
+	env := newTestEnv(t, 1)
+
+	env.ts.SetProjectFiles("code/main.py", "README.md")
+
+	_, err := env.s.Create(newTestCmd(t, "t1"))
+	if !IsErrorInvalidCode(err) || !strings.Contains(err.Error(), "train.py") {
+		t.Fatalf("err = %v, want the boot file does not exist", err)
+	}
+
+	cmd := newTestCmd(t, "t2")
+	cmd.CodeDir, _ = domain.NewDirectory("src")
+
+	_, err = env.s.Create(cmd)
+	if !IsErrorInvalidCode(err) || !strings.Contains(err.Error(), "src") {
+		t.Fatalf("err = %v, want the code dir is empty", err)
+	}
+
+	if n := len(env.ts.Jobs()); n != 0 {
+		t.Errorf("%d jobs are created, want 0", n)
+	}
+
+	// the slot is not consumed by the invalid trainings.
+	env.ts.SetProjectFiles("code/train.py")
+
+	if _, err := env.s.Create(newTestCmd(t, "t3")); err != nil {
+		t.Errorf("create failed, err:%v", err)
+	}
+}
+
+func TestCreateInjectsSecretEnv(t *testing.T) {
+	env := newTestEnv(t, 10)
+
+	cmd := newTestCmd(t, "t1")
+
+	e := domain.SecretEnv{}
+	e.Key, _ = domain.NewCustomizedKey("TOKEN")
+	e.Secret, _ = domain.NewSecretName("hub-token")
+	cmd.SecretEnv = []domain.SecretEnv{e}
+
+	_, err := env.s.Create(cmd)
+	if !secret.IsErrorSecretNotExists(err) {
+		t.Fatalf("err = %v, want the secret does not exist", err)
+	}
+
+	if n := len(env.ts.Jobs()); n != 0 {
+		t.Fatalf("%d jobs are created, want 0", n)
+	}
+
+	if err := env.ss.Save(&domain.Secret{
+		Owner: cmd.User,
+		Name:  e.Secret,
+		Value: "s3cr3t",
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	dto, err := env.s.Create(cmd)
+	if err != nil {
+		t.Fatalf("create failed, err:%v", err)
+	}
+
+	job := env.ts.Jobs()[dto.JobId]
+
+	found := false
+	for _, v := range job.Env {
+		if v.Key.CustomizedKey() == "TOKEN" {
+			found = v.Value != nil && v.Value.CustomizedValue() == "s3cr3t"
+		}
+	}
+
+	if !found {
+		t.Errorf("the secret is not injected into env: %v", job.Env)
+	}
+
+	if len(cmd.Env) != 0 {
+		t.Errorf("the secret is kept in the cmd: %v", cmd.Env)
+	}
+}
+
+func TestCreateFailsIfProjectIsBeingSynced(t *testing.T) {
+	env := newTestEnv(t, 10)
+
+	user := newTestCmd(t, "").User
+
+	_, err := env.lock.Save(&domain.RepoSyncLock{
+		Owner:  user,
+		RepoId: testRepoId,
+		Status: domain.RepoSyncStatusRunning,
+		Holder: "other",
+		Expiry: time.Now().Add(time.Hour).Unix(),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = env.s.Create(newTestCmd(t, "t1"))
+	if err == nil || !strings.Contains(err.Error(), "other") {
+		t.Fatalf("err = %v, want being synced by other", err)
+	}
+
+	// the expired lock will be taken over.
+	c, _ := env.lock.Find(user, testRepoId, "")
+	c.Expiry = time.Now().Add(-time.Second).Unix()
+	if _, err := env.lock.Save(&c); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := env.s.Create(newTestCmd(t, "t2")); err != nil {
+		t.Fatalf("create failed, err:%v", err)
+	}
+}
+
+func TestCreateChecksInputs(t *testing.T) {
+	env := newTestEnv(t, 10)
+
+	input := newTestInput(t)
+	env.pf.SetLastCommit(input.RepoId, testNewCommit)
+
+	cmd := newTestCmd(t, "t1")
+	cmd.Inputs = []domain.Input{input}
+
+	// not synced
+	if _, err := env.s.Create(cmd); err == nil {
+		t.Fatal("create training with input not synced")
+	}
+
+	// synced, but not the latest
+	env.ts.SetRepoSyncedCommit(&input.ResourceRef, testCommit)
+
+	if _, err := env.s.Create(cmd); err == nil {
+		t.Fatal("create training with input not the latest")
+	}
+
+	// pinned to the synced commit, but the snapshot can't be saved.
+	cmd.Inputs[0].Commit = testCommit
+	env.ts.Fail("SnapshotInput", errors.New("the resource is changed"))
+
+	if _, err := env.s.Create(cmd); err == nil {
+		t.Fatal("create training without the snapshot of input")
+	}
+
+	env.ts.Recover("SnapshotInput")
+
+	dto, err := env.s.Create(cmd)
+	if err != nil {
+		t.Fatalf("create failed, err:%v", err)
+	}
+
+	if !env.ts.HasInputSnapshot(&cmd.Inputs[0].ResourceRef) {
+		t.Errorf("the snapshot of input is not saved")
+	}
+
+	if len(dto.Inputs) != 1 || dto.Inputs[0].Commit != testCommit {
+		t.Errorf("inputs = %+v, want the commit %s", dto.Inputs, testCommit)
+	}
+
+	// the resolved commits are recorded in the job.
+	detail, err := env.s.GetDetail(dto.JobId)
+	if err != nil {
+		t.Fatalf("get detail failed, err:%v", err)
+	}
+
+	if detail.ProjectCommit != testCommit ||
+		len(detail.Inputs) != 1 || detail.Inputs[0] != dto.Inputs[0] {
+		t.Errorf("detail = %+v, want the commits of %+v", detail, dto)
+	}
+
+	// the latest
+	cmd.Inputs[0].Commit = ""
+	env.ts.SetRepoSyncedCommit(&input.ResourceRef, testNewCommit)
+
+	dto, err = env.s.Create(cmd)
+	if err != nil {
+		t.Fatalf("create failed, err:%v", err)
+	}
+
+	if dto.Inputs[0].Commit != testNewCommit {
+		t.Errorf("input commit = %s, want %s", dto.Inputs[0].Commit, testNewCommit)
+	}
+}
+
+func TestCreateReleasesSlotOnFailure(t *testing.T) {
+	env := newTestEnv(t, 1)
+
+	env.pf.Fail("GetLastCommit", errors.New("platform is down"))
+
+	if _, err := env.s.Create(newTestCmd(t, "t1")); err == nil {
+		t.Fatal("create succeeded when the platform is down")
+	}
+
+	env.pf.Recover("GetLastCommit")
+	env.ts.Fail("Create", errors.New("no resource"))
+
+	if _, err := env.s.Create(newTestCmd(t, "t2")); err == nil {
+		t.Fatal("create succeeded when the training center fails")
+	}
+
+	env.ts.Recover("Create")
+
+	if _, err := env.s.Create(newTestCmd(t, "t3")); err != nil {
+		t.Fatalf("the slot is not released, err:%v", err)
+	}
+}
+
+func TestConcurrencyLimit(t *testing.T) {
+	env := newTestEnv(t, 2)
+
+	env.ts.SetStatusSteps(inmemory.StatusStep{
+		After:  time.Hour,
+		Status: domain.TrainingStatusCompleted,
+	})
+	env.ts.SetSyncDelay(50 * time.Millisecond)
+
+	n := 5
+	errs := make([]error, n)
+	jobs := make([]string, n)
+
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		cmd := newTestCmd(t, "t")
+
+		wg.Add(1)
+
+		go func(i int) {
+			defer wg.Done()
+
+			dto, err := env.s.Create(cmd)
+			jobs[i], errs[i] = dto.JobId, err
+		}(i)
+	}
+	wg.Wait()
+
+	job := ""
+	succeeded := 0
+	for i, err := range errs {
+		if err == nil {
+			succeeded++
+			job = jobs[i]
+		}
+	}
+
+	if succeeded != 2 {
+		t.Fatalf("%d trainings are created, want 2", succeeded)
+	}
+
+	// the parallel creations share one sync of the same commit.
+	if v := env.ts.SyncNum(); v != 1 {
+		t.Errorf("synced %d times, want 1", v)
+	}
+
+	if _, err := env.s.Create(newTestCmd(t, "t")); err == nil {
+		t.Fatal("create training beyond the limit")
+	}
+
+	// the slot is released once the training is done.
+	if err := env.ts.SetStatus(job, domain.TrainingStatusCompleted); err != nil {
+		t.Fatal(err)
+	}
+
+	env.ws.Check()
+
+	if _, err := env.s.Create(newTestCmd(t, "t")); err != nil {
+		t.Fatalf("create failed after a training is done, err:%v", err)
+	}
+}
+
+func TestSetMaxTrainingNum(t *testing.T) {
+	env := newTestEnv(t, 1)
+
+	env.ts.SetStatusSteps(inmemory.StatusStep{
+		After:  time.Hour,
+		Status: domain.TrainingStatusCompleted,
+	})
+
+	if _, err := env.s.Create(newTestCmd(t, "t1")); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := env.s.Create(newTestCmd(t, "t2")); err == nil {
+		t.Fatal("create training beyond the limit")
+	}
+
+	env.s.SetMaxTrainingNum(2)
+
+	if _, err := env.s.Create(newTestCmd(t, "t2")); err != nil {
+		t.Fatalf("create failed after the limit is increased, err:%v", err)
+	}
+
+	// the running trainings are not affected if it is decreased.
+	env.s.SetMaxTrainingNum(1)
+
+	if n := env.ws.Watching(); n != 2 {
+		t.Errorf("%d trainings are watched, want 2", n)
+	}
+
+	if _, err := env.s.Create(newTestCmd(t, "t3")); err == nil {
+		t.Fatal("create training beyond the decreased limit")
+	}
+}
+
+func TestWaitForInputs(t *testing.T) {
+	env := newTestEnv(t, 10)
+
+	input := newTestInput(t)
+	env.pf.SetLastCommit(input.RepoId, testCommit)
+
+	cmd := newTestCmd(t, "t1")
+	cmd.Inputs = []domain.Input{input}
+	cmd.WaitForInputs = true
+
+	dto, err := env.s.Create(cmd)
+	if err != nil {
+		t.Fatalf("create failed, err:%v", err)
+	}
+
+	waiting := domain.TrainingStatusWaitingForInputs.TrainingStatus()
+	if dto.Status != waiting {
+		t.Fatalf("status = %s, want %s", dto.Status, waiting)
+	}
+
+	env.s.checkWaiting(env.s.listWaitings()[0])
+
+	detail, err := env.s.GetDetail(dto.JobId)
+	if err != nil || detail.Status != waiting {
+		t.Fatalf("detail = %+v, err = %v, want %s", detail, err, waiting)
+	}
+
+	if detail.ProjectCommit != testCommit || len(detail.Inputs) != 0 {
+		t.Errorf("detail = %+v, want only the project commit", detail)
+	}
+
+	env.ts.SetRepoSyncedCommit(&input.ResourceRef, testCommit)
+	env.s.checkWaiting(env.s.listWaitings()[0])
+
+	detail, err = env.s.GetDetail(dto.JobId)
+	if err != nil {
+		t.Fatalf("get detail failed, err:%v", err)
+	}
+
+	if detail.Status != domain.TrainingStatusRunning.TrainingStatus() {
+		t.Errorf("status = %s, want Running", detail.Status)
+	}
+
+	// the commits resolved when the training is submitted are recorded.
+	if len(detail.Inputs) != 1 || detail.Inputs[0].Commit != testCommit {
+		t.Errorf("inputs = %+v, want the commit %s", detail.Inputs, testCommit)
+	}
+
+	if env.ws.Watching() != 1 {
+		t.Errorf("the submitted training is not watched")
+	}
+}
+
+func TestTerminateWaitingTraining(t *testing.T) {
+	env := newTestEnv(t, 10)
+
+	cmd := newTestCmd(t, "t1")
+	cmd.Inputs = []domain.Input{newTestInput(t)}
+	cmd.WaitForInputs = true
+
+	dto, err := env.s.Create(cmd)
+	if err != nil {
+		t.Fatalf("create failed, err:%v", err)
+	}
+
+	if err := env.s.Terminate(dto.JobId); err != nil {
+		t.Fatalf("terminate failed, err:%v", err)
+	}
+
+	terminated := domain.TrainingStatusTerminated.TrainingStatus()
+
+	if v := env.ws.Status(cmd.TrainingId); v != terminated {
+		t.Errorf("notified status = %s, want %s", v, terminated)
+	}
+
+	if len(env.s.listWaitings()) != 0 {
+		t.Errorf("the terminated training is still waiting")
+	}
+}
+
+func TestGetOwner(t *testing.T) {
+	env := newTestEnv(t, 10)
+
+	cmd := newTestCmd(t, "t1")
+
+	dto, err := env.s.Create(cmd)
+	if err != nil {
+		t.Fatalf("create failed, err:%v", err)
+	}
+
+	if v, err := env.s.GetOwner(dto.JobId); err != nil || v.Account() != cmd.User.Account() {
+		t.Fatalf("owner = %v, err:%v, want %s", v, err, cmd.User.Account())
+	}
+
+	if err := env.s.Delete(dto.JobId); err != nil {
+		t.Fatalf("delete failed, err:%v", err)
+	}
+
+	if _, err := env.s.GetOwner(dto.JobId); !jobowner.IsErrorJobOwnerNotExists(err) {
+		t.Errorf("the owner of deleted job should not exist, err:%v", err)
+	}
+
+	// the owner of training waiting for inputs.
+	cmd = newTestCmd(t, "t2")
+	cmd.Inputs = []domain.Input{newTestInput(t)}
+	cmd.WaitForInputs = true
+
+	if dto, err = env.s.Create(cmd); err != nil {
+		t.Fatalf("create failed, err:%v", err)
+	}
+
+	if v, err := env.s.GetOwner(dto.JobId); err != nil || v.Account() != cmd.User.Account() {
+		t.Errorf("owner = %v, err:%v, want %s", v, err, cmd.User.Account())
+	}
+}
+
+func TestWaitingKeepsPinnedCommit(t *testing.T) {
+	env := newTestEnv(t, 10)
+
+	input := newTestInput(t)
+	input.Commit = testCommit
+	env.pf.SetLastCommit(input.RepoId, testNewCommit)
+
+	cmd := newTestCmd(t, "t1")
+	cmd.Inputs = []domain.Input{input}
+	cmd.WaitForInputs = true
+
+	if _, err := env.s.Create(cmd); err != nil {
+		t.Fatalf("create failed, err:%v", err)
+	}
+
+	// the other commit is synced, the training must keep waiting.
+	env.ts.SetRepoSyncedCommit(&input.ResourceRef, testNewCommit)
+
+	w := env.s.listWaitings()[0]
+	env.s.checkWaiting(w)
+
+	if !w.isWaiting() || w.cmd.Inputs[0].Commit != testCommit {
+		t.Fatalf("waiting = %v, pinned commit = %s, want %s",
+			w.isWaiting(), w.cmd.Inputs[0].Commit, testCommit)
+	}
+
+	env.ts.SetRepoSyncedCommit(&input.ResourceRef, testCommit)
+	env.s.checkWaiting(w)
+
+	jobs := env.ts.Jobs()
+	if len(jobs) != 1 {
+		t.Fatalf("submitted %d jobs, want 1", len(jobs))
+	}
+
+	for _, v := range jobs {
+		if c := v.Inputs[0].Commit; c != testCommit {
+			t.Errorf("submitted input commit = %s, want %s", c, testCommit)
+		}
+	}
+}
+
+func TestWaitingDoesNotPinReadyInput(t *testing.T) {
+	env := newTestEnv(t, 10)
+
+	ready := newTestInput(t)
+	env.pf.SetLastCommit(ready.RepoId, testCommit)
+	env.ts.SetRepoSyncedCommit(&ready.ResourceRef, testCommit)
+
+	notReady := newTestInput(t)
+	notReady.RepoId = "3"
+	env.pf.SetLastCommit(notReady.RepoId, testCommit)
+
+	cmd := newTestCmd(t, "t1")
+	cmd.Inputs = []domain.Input{ready, notReady}
+	cmd.WaitForInputs = true
+
+	if _, err := env.s.Create(cmd); err != nil {
+		t.Fatalf("create failed, err:%v", err)
+	}
+
+	w := env.s.listWaitings()[0]
+	env.s.checkWaiting(w)
+
+	if c := w.cmd.Inputs[0].Commit; c != "" {
+		t.Fatalf("the input which is not pinned is pinned to %s", c)
+	}
+
+	// the ready input moves on before the other one is ready.
+	env.pf.SetLastCommit(ready.RepoId, testNewCommit)
+	env.ts.SetRepoSyncedCommit(&ready.ResourceRef, testNewCommit)
+	env.ts.SetRepoSyncedCommit(&notReady.ResourceRef, testCommit)
+
+	env.s.checkWaiting(w)
+
+	if w.isWaiting() {
+		t.Fatalf("training is still waiting, reason:%s", w.reason)
+	}
+
+	for _, v := range env.ts.Jobs() {
+		if c := v.Inputs[0].Commit; c != testNewCommit {
+			t.Errorf("submitted input commit = %s, want %s", c, testNewCommit)
+		}
+	}
+}
+
+func TestSlowNotificationDoesNotBlock(t *testing.T) {
+	env := newTestEnv(t, 10)
+
+	waitingCmd := newTestCmd(t, "t1")
+	waitingCmd.Inputs = []domain.Input{newTestInput(t)}
+	waitingCmd.WaitForInputs = true
+
+	dto, err := env.s.Create(waitingCmd)
+	if err != nil {
+		t.Fatalf("create failed, err:%v", err)
+	}
+
+	held, release := env.ws.HoldNotify()
+
+	terminated := make(chan error, 1)
+	go func() {
+		terminated <- env.s.Terminate(dto.JobId)
+	}()
+
+	<-held
+
+	// the other trainings can be created and read during the notification.
+	done := make(chan error, 1)
+	go func() {
+		if _, err := env.s.GetDetail(dto.JobId); err != nil {
+			done <- err
+
+			return
+		}
+
+		_, err := env.s.Create(newTestCmd(t, "t2"))
+		done <- err
+	}()
+
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Errorf("failed during the notification, err:%v", err)
+		}
+
+	case <-time.After(5 * time.Second):
+		t.Error("blocked by the notification")
+	}
+
+	release()
+
+	if err := <-terminated; err != nil {
+		t.Errorf("terminate failed, err:%v", err)
+	}
+}
+
+func TestWaitingReasonOfNoSlot(t *testing.T) {
+	env := newTestEnv(t, 1)
+
+	input := newTestInput(t)
+	env.pf.SetLastCommit(input.RepoId, testCommit)
+
+	cmd := newTestCmd(t, "t1")
+	cmd.Inputs = []domain.Input{input}
+	cmd.WaitForInputs = true
+
+	if _, err := env.s.Create(cmd); err != nil {
+		t.Fatalf("create failed, err:%v", err)
+	}
+
+	// the only slot is taken, although the inputs are ready.
+	if _, err := env.s.Create(newTestCmd(t, "t2")); err != nil {
+		t.Fatalf("create failed, err:%v", err)
+	}
+
+	env.ts.SetRepoSyncedCommit(&input.ResourceRef, testCommit)
+
+	w := env.s.listWaitings()[0]
+	w.deadline = time.Now().Add(-time.Second)
+	env.s.checkWaiting(w)
+
+	if w.isWaiting() {
+		t.Fatal("the training should fail after the deadline")
+	}
+
+	if !strings.Contains(w.reason, "free slot") || strings.Contains(w.reason, "inputs") {
+		t.Errorf("reason = %s, want the one of waiting for a free slot", w.reason)
+	}
+}
+
+func TestPruneFinishedWaitings(t *testing.T) {
+	env := newTestEnv(t, 10)
+
+	cmd := newTestCmd(t, "t1")
+	cmd.Inputs = []domain.Input{newTestInput(t)}
+	cmd.WaitForInputs = true
+
+	dto, err := env.s.Create(cmd)
+	if err != nil {
+		t.Fatalf("create failed, err:%v", err)
+	}
+
+	env.s.pruneWaitings(time.Now().Add(2 * env.s.waitingCfg.retention()))
+
+	if _, err := env.s.GetDetail(dto.JobId); err != nil {
+		t.Fatalf("the waiting training is pruned, err:%v", err)
+	}
+
+	if err := env.s.Terminate(dto.JobId); err != nil {
+		t.Fatalf("terminate failed, err:%v", err)
+	}
+
+	env.s.pruneWaitings(time.Now())
+
+	if _, err := env.s.GetDetail(dto.JobId); err != nil {
+		t.Fatalf("the training is pruned within the retention, err:%v", err)
+	}
+
+	env.s.pruneWaitings(time.Now().Add(2 * env.s.waitingCfg.retention()))
+
+	if _, err := env.s.GetDetail(dto.JobId); err == nil {
+		t.Error("the finished training is not pruned after the retention")
+	}
+}
+
+func TestExitFailsWaitings(t *testing.T) {
+	env := newTestEnv(t, 10)
+
+	cmd := newTestCmd(t, "t1")
+	cmd.Inputs = []domain.Input{newTestInput(t)}
+	cmd.WaitForInputs = true
+
+	if _, err := env.s.Create(cmd); err != nil {
+		t.Fatalf("create failed, err:%v", err)
+	}
+
+	env.s.Exit()
+
+	failed := domain.TrainingStatusFailed.TrainingStatus()
+	if v := env.ws.Status(cmd.TrainingId); v != failed {
+		t.Errorf("notified status = %s, want %s", v, failed)
+	}
+}
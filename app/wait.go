@@ -0,0 +1,400 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/opensourceways/xihe-training-center/domain"
+	"github.com/opensourceways/xihe-training-center/domain/jobowner"
+	"github.com/opensourceways/xihe-training-center/domain/watch"
+	"github.com/opensourceways/xihe-training-center/utils"
+)
+
+const waitingJobIdPrefix = "waiting-"
+
+func isWaitingJobId(jobId string) bool {
+	return strings.HasPrefix(jobId, waitingJobIdPrefix)
+}
+
+// waitingTraining is the training which is waiting for its inputs.
+// It is identified by the id generated by training center, and it
+// will be mapped to the job id once the training is submitted.
+type waitingTraining struct {
+	cmd      TrainingCreateCmd
+	deadline time.Time
+
+	jobId  string
+	status domain.TrainingStatus
+	reason string
+
+	// finishedAt is the time when the training stopped waiting without
+	// a job, or its job was done. It is zero if it is not finished.
+	finishedAt time.Time
+}
+
+func (w *waitingTraining) isExpired(now time.Time, retention time.Duration) bool {
+	return !w.finishedAt.IsZero() && now.Sub(w.finishedAt) > retention
+}
+
+func (w *waitingTraining) isWaiting() bool {
+	return w.status.TrainingStatus() ==
+		domain.TrainingStatusWaitingForInputs.TrainingStatus()
+}
+
+func (w *waitingTraining) toTrainingInfo() watch.TrainingInfo {
+	return watch.TrainingInfo{
+		User:       w.cmd.User,
+		ProjectId:  w.cmd.ProjectId,
+		TrainingId: w.cmd.TrainingId,
+	}
+}
+
+func (s *trainingService) wait(cmd *TrainingCreateCmd, reason error) (
+	dto JobInfoDTO, err error,
+) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if s.waitingNum >= s.maxTrainingNum {
+		err = errors.New("too many trainings waiting for inputs")
+
+		return
+	}
+
+	now := time.Now()
+	id := waitingJobIdPrefix + utils.GenMD5([]byte(fmt.Sprintf(
+		"%s/%s/%s/%d",
+		cmd.User.Account(), cmd.ProjectId, cmd.TrainingId, now.UnixNano(),
+	)))
+
+	w := &waitingTraining{
+		cmd:      *cmd,
+		deadline: now.Add(s.waitingCfg.timeout()),
+		status:   domain.TrainingStatusWaitingForInputs,
+		reason:   reason.Error(),
+	}
+	w.cmd.Inputs = append([]domain.Input(nil), cmd.Inputs...)
+
+	s.waitings[id] = w
+	s.waitingNum++
+	s.updateMetrics()
+
+	s.log.Debugf(
+		"training:%s is waiting for inputs, reason:%s",
+		id, reason.Error(),
+	)
+
+	dto.JobId = id
+	dto.Status = w.status.TrainingStatus()
+	dto.ProjectCommit = cmd.ProjectCommit
+
+	return
+}
+
+func (s *trainingService) checkWaitingTrainings() {
+	interval := s.waitingCfg.interval()
+
+	for {
+		time.Sleep(interval)
+
+		for _, w := range s.listWaitings() {
+			s.checkWaiting(w)
+		}
+
+		s.pruneWaitings(time.Now())
+	}
+}
+
+// pruneWaitings removes the trainings which have been finished for
+// longer than the retention, so that the map does not grow forever.
+func (s *trainingService) pruneWaitings(now time.Time) {
+	retention := s.waitingCfg.retention()
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	for id, w := range s.waitings {
+		if w.isExpired(now, retention) {
+			delete(s.waitings, id)
+		}
+	}
+}
+
+// finishWaitingJob marks the training whose job is done as finished.
+func (s *trainingService) finishWaitingJob(jobId string) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	for _, w := range s.waitings {
+		if w.jobId == jobId {
+			w.finishedAt = time.Now()
+
+			return
+		}
+	}
+}
+
+func (s *trainingService) Exit() {
+	var notifies []func()
+
+	s.lock.Lock()
+
+	for _, w := range s.waitings {
+		if w.isWaiting() {
+			notifies = append(notifies, s.failWaiting(
+				w, "the training center exited while waiting for inputs",
+			))
+		}
+	}
+
+	s.lock.Unlock()
+
+	for _, f := range notifies {
+		f()
+	}
+}
+
+func (s *trainingService) listWaitings() []*waitingTraining {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	r := make([]*waitingTraining, 0, s.waitingNum)
+
+	for _, w := range s.waitings {
+		if w.isWaiting() {
+			r = append(r, w)
+		}
+	}
+
+	return r
+}
+
+func (s *trainingService) checkWaiting(w *waitingTraining) {
+	// the cmd of waiting training is never changed after it is
+	// parked, so it is safe to check it without lock.
+	inputs, inputErr := s.checkInputs(&w.cmd)
+
+	var slotErr error
+	if inputErr == nil {
+		if slotErr = s.reserve(); slotErr == nil {
+			cmd := w.cmd
+			cmd.Inputs = inputs
+
+			s.submitWaiting(w, &cmd)
+
+			return
+		}
+	}
+
+	// the inputs may be ready while no slot is free.
+	timeoutReason := ""
+	if inputErr != nil {
+		timeoutReason = "timeout of waiting for inputs, " + inputErr.Error()
+	} else {
+		timeoutReason = "timeout of waiting for a free slot, " + slotErr.Error()
+	}
+
+	s.lock.Lock()
+
+	notify := func() {}
+
+	if w.isWaiting() {
+		if inputErr != nil {
+			w.reason = inputErr.Error()
+		} else {
+			w.reason = "inputs are ready, but " + slotErr.Error()
+		}
+
+		if time.Now().After(w.deadline) {
+			notify = s.failWaiting(w, timeoutReason)
+		}
+	}
+
+	s.lock.Unlock()
+
+	notify()
+}
+
+// submitWaiting submits the cmd in which the commits of inputs are
+// resolved. It must be called with holding a slot reserved.
+func (s *trainingService) submitWaiting(w *waitingTraining, cmd *TrainingCreateCmd) {
+	v, err := s.submit(cmd)
+	if err != nil {
+		s.release()
+	}
+
+	s.lock.Lock()
+
+	// the training may be terminated during the submission.
+	terminated := !w.isWaiting()
+
+	notify := func() {}
+
+	if err != nil {
+		if !terminated {
+			notify = s.failWaiting(w, "submit training failed, err:"+err.Error())
+		}
+	} else {
+		w.jobId = v.JobId
+
+		if !terminated {
+			w.status = domain.TrainingStatusRunning
+			w.reason = ""
+			s.waitingNum--
+			s.updateMetrics()
+		}
+	}
+
+	s.lock.Unlock()
+
+	notify()
+
+	if err == nil && terminated {
+		if err := s.ts.Terminate(v.JobId); err != nil {
+			s.log.Errorf(
+				"terminate job:%s failed, err:%s",
+				v.JobId, err.Error(),
+			)
+		}
+	}
+}
+
+// failWaiting must be called with holding the lock,
+// and the returned notify must be called after releasing it.
+func (s *trainingService) failWaiting(w *waitingTraining, reason string) (notify func()) {
+	s.log.Errorf(
+		"training:%s/%s failed, reason:%s",
+		w.cmd.ProjectId, w.cmd.TrainingId, reason,
+	)
+
+	return s.stopWaiting(w, domain.TrainingStatusFailed, reason)
+}
+
+// stopWaiting records the new status of training. It must be called with
+// holding the lock. The status is notified by the returned func which must
+// be called after releasing the lock, because the notification may be slow
+// and it should not block the other trainings.
+func (s *trainingService) stopWaiting(
+	w *waitingTraining, status domain.TrainingStatus, reason string,
+) (notify func()) {
+	w.status = status
+	w.reason = reason
+	w.finishedAt = time.Now()
+	s.waitingNum--
+	s.updateMetrics()
+
+	trainingFinished(status, w.cmd.Compute.Flavor)
+
+	info := w.toTrainingInfo()
+
+	return func() {
+		if err := s.ws.NotifyTrainingStatus(&info, status); err != nil {
+			s.log.Errorf(
+				"notify status of training:%s/%s failed, err:%s",
+				info.ProjectId, info.TrainingId, err.Error(),
+			)
+		}
+	}
+}
+
+func (s *trainingService) getWaiting(id string) (
+	jobId string, dto JobDetailDTO, err error,
+) {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	w, ok := s.waitings[id]
+	if !ok {
+		err = errors.New("no such training")
+
+		return
+	}
+
+	jobId = w.jobId
+	dto.Status = w.status.TrainingStatus()
+	dto.Reason = w.reason
+	dto.ProjectCommit = w.cmd.ProjectCommit
+
+	return
+}
+
+func (s *trainingService) getWaitingOwner(id string) (domain.Account, error) {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	w, ok := s.waitings[id]
+	if !ok {
+		return nil, jobowner.NewErrorJobOwnerNotExists(errors.New("no such training"))
+	}
+
+	return w.cmd.User, nil
+}
+
+func (s *trainingService) terminateWaiting(id string) error {
+	s.lock.Lock()
+
+	w, ok := s.waitings[id]
+	if !ok {
+		s.lock.Unlock()
+
+		return errors.New("no such training")
+	}
+
+	notify := func() {}
+
+	if w.isWaiting() {
+		notify = s.stopWaiting(
+			w, domain.TrainingStatusTerminated,
+			"terminated while waiting for inputs",
+		)
+	}
+
+	jobId := w.jobId
+
+	s.lock.Unlock()
+
+	notify()
+
+	if jobId == "" {
+		return nil
+	}
+
+	return s.ts.Terminate(jobId)
+}
+
+func (s *trainingService) deleteWaiting(id string) error {
+	s.lock.RLock()
+
+	jobId := ""
+	if w, ok := s.waitings[id]; ok {
+		jobId = w.jobId
+	}
+
+	s.lock.RUnlock()
+
+	if jobId != "" {
+		if err := s.deleteJob(jobId); err != nil {
+			return err
+		}
+	}
+
+	s.lock.Lock()
+
+	if w, ok := s.waitings[id]; ok {
+		if w.isWaiting() {
+			w.status = domain.TrainingStatusTerminated
+			s.waitingNum--
+			s.updateMetrics()
+
+			trainingFinished(w.status, w.cmd.Compute.Flavor)
+		}
+
+		delete(s.waitings, id)
+	}
+
+	s.lock.Unlock()
+
+	return nil
+}
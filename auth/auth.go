@@ -0,0 +1,59 @@
+package auth
+
+import (
+	"errors"
+
+	"github.com/opensourceways/xihe-training-center/domain"
+)
+
+// errorNotMatched means the token is not the kind which the authenticator
+// handles, so the next one should be tried.
+var errorNotMatched = errors.New("token not matched")
+
+// Caller is the one who calls the api. It is a service if User is nil.
+type Caller struct {
+	Service string
+	User    domain.Account
+}
+
+func (c *Caller) IsUser() bool {
+	return c.User != nil
+}
+
+// Authenticator authenticates the caller by the bearer token.
+type Authenticator interface {
+	Authenticate(token string) (Caller, error)
+}
+
+// NewAuthenticator returns the authenticator which tries
+// the static tokens first and then the jwt.
+func NewAuthenticator(cfg *Config) Authenticator {
+	r := authenticators{}
+
+	if len(cfg.Tokens) > 0 {
+		r = append(r, newStaticTokens(cfg.Tokens))
+	}
+
+	if cfg.JWT != nil {
+		r = append(r, newJWT(cfg.JWT))
+	}
+
+	return r
+}
+
+type authenticators []Authenticator
+
+func (a authenticators) Authenticate(token string) (Caller, error) {
+	if token == "" {
+		return Caller{}, errors.New("missing token")
+	}
+
+	for _, v := range a {
+		c, err := v.Authenticate(token)
+		if err != errorNotMatched {
+			return c, err
+		}
+	}
+
+	return Caller{}, errors.New("invalid token")
+}
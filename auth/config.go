@@ -0,0 +1,76 @@
+package auth
+
+import "errors"
+
+type Config struct {
+	// Tokens are the static bearer tokens of the services
+	// which call the training center.
+	Tokens []TokenConfig `json:"tokens"`
+
+	// JWT is the config of the tokens of users. The users can
+	// only access their own trainings and secrets.
+	JWT *JWTConfig `json:"jwt"`
+}
+
+func (cfg *Config) SetDefault() {
+	if cfg.JWT != nil {
+		cfg.JWT.setDefault()
+	}
+}
+
+func (cfg *Config) Validate() error {
+	for i := range cfg.Tokens {
+		if v := &cfg.Tokens[i]; v.Service == "" || v.Token == "" {
+			return errors.New("invalid token of auth, service and token must be set")
+		}
+	}
+
+	if cfg.JWT != nil {
+		return cfg.JWT.validate()
+	}
+
+	if len(cfg.Tokens) == 0 {
+		return errors.New("missing tokens or jwt of auth")
+	}
+
+	return nil
+}
+
+type TokenConfig struct {
+	// Service is the name of service which uses the token.
+	Service string `json:"service"`
+	Token   string `json:"token"`
+}
+
+type JWTConfig struct {
+	// Secret is the key of HS256 which signs the tokens.
+	Secret string `json:"secret" required:"true"`
+
+	// Issuer is the expected issuer of tokens. It is not checked if empty.
+	Issuer string `json:"issuer"`
+
+	// UserClaim is the claim of the user account. It is "sub" by default.
+	UserClaim string `json:"user_claim"`
+
+	// Leeway specifies the seconds of clock skew allowed
+	// when checking the expiry of tokens.
+	Leeway int `json:"leeway"`
+}
+
+func (cfg *JWTConfig) setDefault() {
+	if cfg.UserClaim == "" {
+		cfg.UserClaim = "sub"
+	}
+}
+
+func (cfg *JWTConfig) validate() error {
+	if cfg.Secret == "" {
+		return errors.New("missing secret of jwt")
+	}
+
+	if cfg.Leeway < 0 {
+		return errors.New("the leeway of jwt can't be negative")
+	}
+
+	return nil
+}
@@ -0,0 +1,123 @@
+package auth
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/opensourceways/xihe-training-center/domain"
+)
+
+const jwtAlgHS256 = "HS256"
+
+// jwtAuthenticator authenticates the users by the jwt signed with HS256.
+type jwtAuthenticator struct {
+	cfg JWTConfig
+}
+
+func newJWT(cfg *JWTConfig) jwtAuthenticator {
+	return jwtAuthenticator{cfg: *cfg}
+}
+
+func (j jwtAuthenticator) Authenticate(token string) (Caller, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return Caller{}, errorNotMatched
+	}
+
+	claims, err := j.verify(parts)
+	if err != nil {
+		return Caller{}, fmt.Errorf("invalid jwt, %s", err.Error())
+	}
+
+	if err := j.checkClaims(claims); err != nil {
+		return Caller{}, fmt.Errorf("invalid jwt, %s", err.Error())
+	}
+
+	v, _ := claims[j.cfg.UserClaim].(string)
+	user, err := domain.NewAccount(v)
+	if err != nil {
+		return Caller{}, fmt.Errorf("invalid jwt, invalid user claim: %s", err.Error())
+	}
+
+	return Caller{User: user}, nil
+}
+
+// verify verifies the signature and returns the claims.
+func (j jwtAuthenticator) verify(parts []string) (map[string]interface{}, error) {
+	var header struct {
+		Alg string `json:"alg"`
+	}
+
+	if err := decodeSegment(parts[0], &header); err != nil {
+		return nil, err
+	}
+
+	if header.Alg != jwtAlgHS256 {
+		return nil, fmt.Errorf("unsupported alg: %s", header.Alg)
+	}
+
+	sig, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, errors.New("malformed signature")
+	}
+
+	mac := hmac.New(sha256.New, []byte(j.cfg.Secret))
+	mac.Write([]byte(parts[0] + "." + parts[1]))
+
+	if !hmac.Equal(sig, mac.Sum(nil)) {
+		return nil, errors.New("signature mismatch")
+	}
+
+	var claims map[string]interface{}
+	if err := decodeSegment(parts[1], &claims); err != nil {
+		return nil, err
+	}
+
+	return claims, nil
+}
+
+func (j jwtAuthenticator) checkClaims(claims map[string]interface{}) error {
+	now := time.Now().Unix()
+	leeway := int64(j.cfg.Leeway)
+
+	// the token must expire.
+	exp, ok := claims["exp"].(float64)
+	if !ok {
+		return errors.New("missing exp")
+	}
+
+	if now > int64(exp)+leeway {
+		return errors.New("token expired")
+	}
+
+	if nbf, ok := claims["nbf"].(float64); ok && now+leeway < int64(nbf) {
+		return errors.New("token not valid yet")
+	}
+
+	if j.cfg.Issuer != "" {
+		if iss, _ := claims["iss"].(string); iss != j.cfg.Issuer {
+			return errors.New("unexpected issuer")
+		}
+	}
+
+	return nil
+}
+
+func decodeSegment(s string, v interface{}) error {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return errors.New("malformed segment")
+	}
+
+	if err := json.Unmarshal(b, v); err != nil {
+		return errors.New("malformed segment")
+	}
+
+	return nil
+}
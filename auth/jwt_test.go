@@ -0,0 +1,222 @@
+package auth
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+const testSecret = "secret"
+
+func encodeSegment(t *testing.T, v interface{}) string {
+	b, err := json.Marshal(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func sign(secret, header, payload string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(header + "." + payload))
+
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+func newToken(t *testing.T, alg string, claims map[string]interface{}) string {
+	h := encodeSegment(t, map[string]string{"alg": alg, "typ": "JWT"})
+	p := encodeSegment(t, claims)
+
+	return h + "." + p + "." + sign(testSecret, h, p)
+}
+
+func TestJWTAuthenticate(t *testing.T) {
+	now := time.Now().Unix()
+
+	valid := func() map[string]interface{} {
+		return map[string]interface{}{
+			"sub": "alice",
+			"iss": "xihe",
+			"exp": now + 60,
+		}
+	}
+
+	with := func(k string, v interface{}) map[string]interface{} {
+		c := valid()
+		if v == nil {
+			delete(c, k)
+		} else {
+			c[k] = v
+		}
+
+		return c
+	}
+
+	validToken := newToken(t, jwtAlgHS256, valid())
+	parts := strings.Split(validToken, ".")
+
+	cases := []struct {
+		name  string
+		token string
+		err   string
+	}{
+		{
+			name:  "valid",
+			token: validToken,
+		},
+		{
+			name:  "not jwt",
+			token: "abc",
+			err:   errorNotMatched.Error(),
+		},
+		{
+			name: "bad signature",
+			token: parts[0] + "." + parts[1] + "." +
+				sign("other", parts[0], parts[1]),
+			err: "signature mismatch",
+		},
+		{
+			name:  "tampered payload",
+			token: parts[0] + "." + encodeSegment(t, with("sub", "bob")) + "." + parts[2],
+			err:   "signature mismatch",
+		},
+		{
+			name:  "alg none",
+			token: encodeSegment(t, map[string]string{"alg": "none"}) + "." + parts[1] + ".",
+			err:   "unsupported alg: none",
+		},
+		{
+			name:  "alg RS256",
+			token: newToken(t, "RS256", valid()),
+			err:   "unsupported alg: RS256",
+		},
+		{
+			name:  "missing exp",
+			token: newToken(t, jwtAlgHS256, with("exp", nil)),
+			err:   "missing exp",
+		},
+		{
+			name:  "exp is not number",
+			token: newToken(t, jwtAlgHS256, with("exp", "tomorrow")),
+			err:   "missing exp",
+		},
+		{
+			name:  "expired",
+			token: newToken(t, jwtAlgHS256, with("exp", now-60)),
+			err:   "token expired",
+		},
+		{
+			name:  "expired within leeway",
+			token: newToken(t, jwtAlgHS256, with("exp", now-5)),
+		},
+		{
+			name:  "not valid yet",
+			token: newToken(t, jwtAlgHS256, with("nbf", now+60)),
+			err:   "token not valid yet",
+		},
+		{
+			name:  "not valid yet within leeway",
+			token: newToken(t, jwtAlgHS256, with("nbf", now+5)),
+		},
+		{
+			name:  "issuer mismatch",
+			token: newToken(t, jwtAlgHS256, with("iss", "other")),
+			err:   "unexpected issuer",
+		},
+		{
+			name:  "missing issuer",
+			token: newToken(t, jwtAlgHS256, with("iss", nil)),
+			err:   "unexpected issuer",
+		},
+		{
+			name:  "missing user claim",
+			token: newToken(t, jwtAlgHS256, with("sub", nil)),
+			err:   "invalid user claim",
+		},
+		{
+			name:  "malformed header",
+			token: "!!." + parts[1] + "." + parts[2],
+			err:   "malformed segment",
+		},
+		{
+			name:  "header is not json",
+			token: base64.RawURLEncoding.EncodeToString([]byte("{")) + "." + parts[1] + "." + parts[2],
+			err:   "malformed segment",
+		},
+		{
+			name:  "malformed signature",
+			token: parts[0] + "." + parts[1] + ".!!",
+			err:   "malformed signature",
+		},
+		{
+			name: "malformed payload",
+			token: parts[0] + ".!!." +
+				sign(testSecret, parts[0], "!!"),
+			err: "malformed segment",
+		},
+	}
+
+	a := newJWT(&JWTConfig{
+		Secret:    testSecret,
+		Issuer:    "xihe",
+		UserClaim: "sub",
+		Leeway:    10,
+	})
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			caller, err := a.Authenticate(c.token)
+
+			if c.err == "" {
+				if err != nil {
+					t.Fatalf("unexpected err: %v", err)
+				}
+
+				if !caller.IsUser() || caller.User.Account() != "alice" {
+					t.Fatalf("unexpected caller: %+v", caller)
+				}
+
+				return
+			}
+
+			if err == nil || !strings.Contains(err.Error(), c.err) {
+				t.Fatalf("expect err containing %q, got %v", c.err, err)
+			}
+		})
+	}
+}
+
+func TestNewAuthenticator(t *testing.T) {
+	a := NewAuthenticator(&Config{
+		Tokens: []TokenConfig{{Service: "server", Token: "token"}},
+		JWT:    &JWTConfig{Secret: testSecret, UserClaim: "sub"},
+	})
+
+	c, err := a.Authenticate("token")
+	if err != nil || c.IsUser() || c.Service != "server" {
+		t.Fatalf("unexpected result of static token: %+v, %v", c, err)
+	}
+
+	jwt := newToken(t, jwtAlgHS256, map[string]interface{}{
+		"sub": "alice",
+		"exp": time.Now().Unix() + 60,
+	})
+
+	c, err = a.Authenticate(jwt)
+	if err != nil || !c.IsUser() || c.User.Account() != "alice" {
+		t.Fatalf("unexpected result of jwt: %+v, %v", c, err)
+	}
+
+	if _, err := a.Authenticate("other"); err == nil || err.Error() != "invalid token" {
+		t.Fatalf("expect invalid token, got %v", err)
+	}
+
+	if _, err := a.Authenticate(""); err == nil || err.Error() != "missing token" {
+		t.Fatalf("expect missing token, got %v", err)
+	}
+}
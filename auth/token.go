@@ -0,0 +1,20 @@
+package auth
+
+import "crypto/subtle"
+
+// staticTokens authenticates the services by the tokens of config.
+type staticTokens []TokenConfig
+
+func newStaticTokens(v []TokenConfig) staticTokens {
+	return append(staticTokens(nil), v...)
+}
+
+func (s staticTokens) Authenticate(token string) (Caller, error) {
+	for i := range s {
+		if subtle.ConstantTimeCompare([]byte(s[i].Token), []byte(token)) == 1 {
+			return Caller{Service: s[i].Service}, nil
+		}
+	}
+
+	return Caller{}, errorNotMatched
+}
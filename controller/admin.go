@@ -0,0 +1,148 @@
+package controller
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/opensourceways/xihe-training-center/app"
+	"github.com/opensourceways/xihe-training-center/domain"
+	"github.com/opensourceways/xihe-training-center/domain/synclock"
+)
+
+func AddRouterForAdminController(
+	rg *gin.RouterGroup,
+	ls app.SyncLockService,
+	cs app.ConfigService,
+) {
+	ctl := AdminController{ls: ls, cs: cs}
+
+	// the users are not allowed to access the admin apis.
+	admin := rg.Group("/v1/admin", onlyService())
+
+	admin.GET("/synclocks/:owner/:repo_id", ctl.GetSyncLock)
+	admin.DELETE("/synclocks/:owner/:repo_id", ctl.ReleaseSyncLock)
+	admin.GET("/config", ctl.GetConfig)
+}
+
+type AdminController struct {
+	baseController
+
+	ls app.SyncLockService
+	cs app.ConfigService
+}
+
+// @Summary GetSyncLock
+// @Description get the sync lock of project
+// @Tags  Admin
+// @Param	owner	path	string	true	"owner of project"
+// @Param	repo_id	path	string	true	"repo id of project"
+// @Param	ref	query	string	false	"branch or tag, it is the default branch if empty"
+// @Accept json
+// @Success 200 {object} app.SyncLockDTO
+// @Failure 400 bad_request_param   some parameter is invalid
+// @Failure 404 not_found           the lock doesn't exist
+// @Failure 403 forbidden           only the services are allowed
+// @Failure 500 system_error        system error
+// @Router /v1/admin/synclocks/{owner}/{repo_id} [get]
+func (ctl *AdminController) GetSyncLock(ctx *gin.Context) {
+	owner, err := domain.NewAccount(ctx.Param("owner"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, newResponseCodeError(
+			errorBadRequestParam, err,
+		))
+
+		return
+	}
+
+	ref, err := getRefQuery(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, newResponseCodeError(
+			errorBadRequestParam, err,
+		))
+
+		return
+	}
+
+	v, err := ctl.ls.Get(owner, ctx.Param("repo_id"), ref)
+	if err != nil {
+		ctl.sendSyncLockError(ctx, err)
+
+		return
+	}
+
+	ctx.JSON(http.StatusOK, newResponseData(v))
+}
+
+// @Summary ReleaseSyncLock
+// @Description release the sync lock of project by force
+// @Tags  Admin
+// @Param	owner	path	string	true	"owner of project"
+// @Param	repo_id	path	string	true	"repo id of project"
+// @Param	ref	query	string	false	"branch or tag, it is the default branch if empty"
+// @Accept json
+// @Success 204
+// @Failure 400 bad_request_param   some parameter is invalid
+// @Failure 404 not_found           the lock doesn't exist
+// @Failure 403 forbidden           only the services are allowed
+// @Failure 500 system_error        system error
+// @Router /v1/admin/synclocks/{owner}/{repo_id} [delete]
+func (ctl *AdminController) ReleaseSyncLock(ctx *gin.Context) {
+	owner, err := domain.NewAccount(ctx.Param("owner"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, newResponseCodeError(
+			errorBadRequestParam, err,
+		))
+
+		return
+	}
+
+	ref, err := getRefQuery(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, newResponseCodeError(
+			errorBadRequestParam, err,
+		))
+
+		return
+	}
+
+	if err := ctl.ls.ForceRelease(owner, ctx.Param("repo_id"), ref); err != nil {
+		ctl.sendSyncLockError(ctx, err)
+
+		return
+	}
+
+	ctx.JSON(http.StatusNoContent, newResponseData("success"))
+}
+
+func (ctl *AdminController) sendSyncLockError(ctx *gin.Context, err error) {
+	if synclock.IsRepoSyncLockNotExist(err) {
+		ctx.JSON(http.StatusNotFound, newResponseCodeError(
+			errorNotFound, err,
+		))
+	} else {
+		ctl.sendRespWithInternalError(ctx, newResponseError(err))
+	}
+}
+
+// @Summary GetConfig
+// @Description get the effective config in which the secrets are masked
+// @Tags  Admin
+// @Accept json
+// @Success 200
+// @Failure 403 forbidden           only the services are allowed
+// @Failure 500 system_error        system error
+// @Router /v1/admin/config [get]
+func (ctl *AdminController) GetConfig(ctx *gin.Context) {
+	v := ctl.cs.Get()
+	if v == nil {
+		ctl.sendRespWithInternalError(ctx, newResponseError(
+			errors.New("can't get the config"),
+		))
+
+		return
+	}
+
+	ctx.JSON(http.StatusOK, newResponseData(v))
+}
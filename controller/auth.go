@@ -0,0 +1,90 @@
+package controller
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/opensourceways/xihe-training-center/auth"
+	"github.com/opensourceways/xihe-training-center/domain"
+)
+
+const (
+	callerKey    = "caller"
+	bearerPrefix = "bearer "
+)
+
+var respForbidden = newResponseCodeMsg(
+	errorForbidden, "can't access the resource of other user",
+)
+
+// Authenticate returns the middleware which authenticates the
+// caller by the bearer token in the Authorization header.
+func Authenticate(a auth.Authenticator) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		token := ctx.GetHeader("Authorization")
+		if n := len(bearerPrefix); len(token) <= n || !strings.EqualFold(token[:n], bearerPrefix) {
+			ctx.AbortWithStatusJSON(http.StatusUnauthorized, newResponseCodeMsg(
+				errorUnauthorized, "missing bearer token",
+			))
+
+			return
+		}
+
+		c, err := a.Authenticate(strings.TrimSpace(token[len(bearerPrefix):]))
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusUnauthorized, newResponseCodeError(
+				errorUnauthorized, err,
+			))
+
+			return
+		}
+
+		ctx.Set(callerKey, c)
+
+		ctx.Next()
+	}
+}
+
+// onlyService returns the middleware which forbids the users.
+func onlyService() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		if c, ok := getCaller(ctx); ok && c.IsUser() {
+			ctx.AbortWithStatusJSON(http.StatusForbidden, newResponseCodeError(
+				errorForbidden, errors.New("only the services are allowed"),
+			))
+
+			return
+		}
+
+		ctx.Next()
+	}
+}
+
+// getCaller returns the caller. It returns false if the auth is disabled.
+func getCaller(ctx *gin.Context) (auth.Caller, bool) {
+	v, ok := ctx.Get(callerKey)
+	if !ok {
+		return auth.Caller{}, false
+	}
+
+	c, ok := v.(auth.Caller)
+
+	return c, ok
+}
+
+// checkOwner checks whether the caller can access the resource of owner.
+// The services can access all the resources, but the users can only access
+// their own ones. It responds 403 if the caller can't.
+func (ctl baseController) checkOwner(ctx *gin.Context, owner domain.Account) bool {
+	c, ok := getCaller(ctx)
+	if !ok || !c.IsUser() || c.User.Account() == owner.Account() {
+		return true
+	}
+
+	ctx.JSON(http.StatusForbidden, respForbidden)
+
+	return false
+}
@@ -0,0 +1,141 @@
+package controller
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+
+	"github.com/opensourceways/xihe-training-center/app"
+	"github.com/opensourceways/xihe-training-center/auth"
+	"github.com/opensourceways/xihe-training-center/domain"
+	"github.com/opensourceways/xihe-training-center/domain/jobowner"
+)
+
+func init() {
+	gin.SetMode(gin.TestMode)
+
+	Init(logrus.NewEntry(logrus.New()))
+}
+
+func mustAccount(t *testing.T, v string) domain.Account {
+	a, err := domain.NewAccount(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return a
+}
+
+// serve handles a request by the handler as the caller.
+// The caller is not set if it is nil, as the auth is disabled.
+func serve(caller *auth.Caller, handler gin.HandlerFunc) int {
+	w := httptest.NewRecorder()
+	ctx, _ := gin.CreateTestContext(w)
+	ctx.Request = httptest.NewRequest(http.MethodGet, "/", nil)
+
+	if caller != nil {
+		ctx.Set(callerKey, *caller)
+	}
+
+	handler(ctx)
+
+	return w.Code
+}
+
+func TestCheckOwner(t *testing.T) {
+	owner := mustAccount(t, "alice")
+
+	cases := []struct {
+		name   string
+		caller *auth.Caller
+		code   int
+	}{
+		{"auth disabled", nil, http.StatusOK},
+		{"service", &auth.Caller{Service: "server"}, http.StatusOK},
+		{"owner", &auth.Caller{User: mustAccount(t, "alice")}, http.StatusOK},
+		{"other user", &auth.Caller{User: mustAccount(t, "bob")}, http.StatusForbidden},
+	}
+
+	ctl := baseController{}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			code := serve(c.caller, func(ctx *gin.Context) {
+				if ctl.checkOwner(ctx, owner) {
+					ctx.Status(http.StatusOK)
+				}
+			})
+
+			if code != c.code {
+				t.Fatalf("expect %d, got %d", c.code, code)
+			}
+		})
+	}
+}
+
+// fakeTrainingService only implements GetOwner.
+type fakeTrainingService struct {
+	app.TrainingService
+
+	owners map[string]domain.Account
+	err    error
+}
+
+func (s *fakeTrainingService) GetOwner(jobId string) (domain.Account, error) {
+	if s.err != nil {
+		return nil, s.err
+	}
+
+	v, ok := s.owners[jobId]
+	if !ok {
+		return nil, jobowner.NewErrorJobOwnerNotExists(errors.New("not exists"))
+	}
+
+	return v, nil
+}
+
+func TestCheckJobOwner(t *testing.T) {
+	alice := &auth.Caller{User: mustAccount(t, "alice")}
+
+	cases := []struct {
+		name   string
+		caller *auth.Caller
+		jobId  string
+		err    error
+		code   int
+	}{
+		{"auth disabled", nil, "unknown", nil, http.StatusOK},
+		{"service", &auth.Caller{Service: "server"}, "unknown", nil, http.StatusOK},
+		{"owner", alice, "job1", nil, http.StatusOK},
+		{"other user", &auth.Caller{User: mustAccount(t, "bob")}, "job1", nil, http.StatusForbidden},
+		{"unknown owner", alice, "unknown", nil, http.StatusForbidden},
+		{"failed to get owner", alice, "job1", errors.New("db error"), http.StatusInternalServerError},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			ctl := TrainingController{
+				ts: &fakeTrainingService{
+					owners: map[string]domain.Account{
+						"job1": mustAccount(t, "alice"),
+					},
+					err: c.err,
+				},
+			}
+
+			code := serve(c.caller, func(ctx *gin.Context) {
+				if ctl.checkJobOwner(ctx, c.jobId) {
+					ctx.Status(http.StatusOK)
+				}
+			})
+
+			if code != c.code {
+				t.Fatalf("expect %d, got %d", c.code, code)
+			}
+		})
+	}
+}
@@ -0,0 +1,34 @@
+package controller
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/opensourceways/xihe-training-center/app"
+)
+
+func AddRouterForComputeController(
+	rg *gin.RouterGroup,
+	cs app.ComputeService,
+) {
+	ctl := ComputeController{cs: cs}
+
+	rg.GET("/v1/compute", ctl.List)
+}
+
+type ComputeController struct {
+	baseController
+
+	cs app.ComputeService
+}
+
+// @Summary List
+// @Description list the compute engines, versions and flavors which can be used
+// @Tags  Compute
+// @Accept json
+// @Success 200 {object} app.ComputeCatalogDTO
+// @Router /v1/compute [get]
+func (ctl *ComputeController) List(ctx *gin.Context) {
+	ctx.JSON(http.StatusOK, newResponseData(ctl.cs.List()))
+}
@@ -0,0 +1,55 @@
+package controller
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/opensourceways/xihe-training-center/app"
+)
+
+func AddRouterForHealthController(
+	rg *gin.RouterGroup,
+	hs app.HealthService,
+) {
+	ctl := HealthController{hs: hs}
+
+	rg.GET("/healthz", ctl.Liveness)
+	rg.GET("/readyz", ctl.Readiness)
+}
+
+type HealthController struct {
+	baseController
+
+	hs app.HealthService
+}
+
+// @Summary Liveness
+// @Description check whether the service is alive
+// @Tags  Health
+// @Accept json
+// @Success 200 {object} app.HealthDTO
+// @Failure 503 {object} app.HealthDTO
+// @Router /healthz [get]
+func (ctl *HealthController) Liveness(ctx *gin.Context) {
+	ctl.sendHealth(ctx, ctl.hs.Liveness())
+}
+
+// @Summary Readiness
+// @Description check whether the dependencies of service are available
+// @Tags  Health
+// @Accept json
+// @Success 200 {object} app.HealthDTO
+// @Failure 503 {object} app.HealthDTO
+// @Router /readyz [get]
+func (ctl *HealthController) Readiness(ctx *gin.Context) {
+	ctl.sendHealth(ctx, ctl.hs.Readiness())
+}
+
+func (ctl *HealthController) sendHealth(ctx *gin.Context, v app.HealthDTO) {
+	if v.IsHealthy() {
+		ctx.JSON(http.StatusOK, newResponseData(v))
+	} else {
+		ctx.JSON(http.StatusServiceUnavailable, newResponseData(v))
+	}
+}
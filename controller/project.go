@@ -0,0 +1,125 @@
+package controller
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/opensourceways/xihe-training-center/app"
+	"github.com/opensourceways/xihe-training-center/domain"
+)
+
+func AddRouterForProjectController(
+	rg *gin.RouterGroup,
+	ps app.ProjectService,
+) {
+	ctl := ProjectController{ps: ps}
+
+	rg.POST("/v1/projects/:owner/:repo_id/sync", ctl.Sync)
+	rg.GET("/v1/projects/:owner/:repo_id/sync", ctl.GetSyncStatus)
+}
+
+type ProjectController struct {
+	baseController
+
+	ps app.ProjectService
+}
+
+// @Summary Sync
+// @Description start to sync project in background
+// @Tags  Project
+// @Param	owner	path	string			true	"owner of project"
+// @Param	repo_id	path	string			true	"repo id of project"
+// @Param	body	body 	ProjectSyncRequest	true	"body of syncing project"
+// @Accept json
+// @Success 202 {object} app.ProjectSyncDTO
+// @Failure 400 bad_request_body    can't parse request body
+// @Failure 401 bad_request_param   some parameter of body is invalid
+// @Failure 403 forbidden           can't access the resource of other user
+// @Failure 500 system_error        system error
+// @Router /v1/projects/{owner}/{repo_id}/sync [post]
+func (ctl *ProjectController) Sync(ctx *gin.Context) {
+	req := ProjectSyncRequest{}
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, respBadRequestBody)
+
+		return
+	}
+
+	cmd, err := req.toCmd(ctx.Param("owner"), ctx.Param("repo_id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, newResponseCodeError(
+			errorBadRequestParam, err,
+		))
+
+		return
+	}
+
+	if !ctl.checkOwner(ctx, cmd.Owner) {
+		return
+	}
+
+	v, err := ctl.ps.Sync(&cmd)
+	if err != nil {
+		ctl.sendRespWithInternalError(ctx, newResponseError(err))
+
+		return
+	}
+
+	ctx.JSON(http.StatusAccepted, newResponseData(v))
+}
+
+// @Summary GetSyncStatus
+// @Description get the sync status of project
+// @Tags  Project
+// @Param	owner	path	string	true	"owner of project"
+// @Param	repo_id	path	string	true	"repo id of project"
+// @Param	ref	query	string	false	"branch or tag, it is the default branch if empty"
+// @Accept json
+// @Success 200 {object} app.ProjectSyncDTO
+// @Failure 400 bad_request_param   some parameter is invalid
+// @Failure 403 forbidden           can't access the resource of other user
+// @Failure 500 system_error        system error
+// @Router /v1/projects/{owner}/{repo_id}/sync [get]
+func (ctl *ProjectController) GetSyncStatus(ctx *gin.Context) {
+	owner, err := domain.NewAccount(ctx.Param("owner"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, newResponseCodeError(
+			errorBadRequestParam, err,
+		))
+
+		return
+	}
+
+	ref, err := getRefQuery(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, newResponseCodeError(
+			errorBadRequestParam, err,
+		))
+
+		return
+	}
+
+	if !ctl.checkOwner(ctx, owner) {
+		return
+	}
+
+	v, err := ctl.ps.GetSyncStatus(owner, ctx.Param("repo_id"), ref)
+	if err != nil {
+		ctl.sendRespWithInternalError(ctx, newResponseError(err))
+
+		return
+	}
+
+	ctx.JSON(http.StatusOK, newResponseData(v))
+}
+
+func getRefQuery(ctx *gin.Context) (string, error) {
+	ref := ctx.Query("ref")
+	if ref != "" && !domain.IsRef(ref) {
+		return "", errors.New("invalid ref")
+	}
+
+	return ref, nil
+}
@@ -0,0 +1,65 @@
+package controller
+
+import (
+	"errors"
+
+	"github.com/opensourceways/xihe-training-center/app"
+	"github.com/opensourceways/xihe-training-center/domain"
+)
+
+type ProjectSyncRequest struct {
+	ProjectName string `json:"project_name"`
+
+	// Ref is optional. It is the branch or tag to sync.
+	// The default branch will be synced if it is empty.
+	Ref string `json:"ref"`
+
+	// Commit is optional. The latest commit of ref will be synced if it is empty.
+	Commit string `json:"commit"`
+
+	// CodeDir is optional. Only this directory will be synced if it is set.
+	CodeDir string `json:"code_dir"`
+}
+
+func (req *ProjectSyncRequest) toCmd(owner, repoId string) (cmd app.ProjectSyncCmd, err error) {
+	if repoId == "" {
+		err = errors.New("invalid repo id")
+
+		return
+	}
+
+	if cmd.Owner, err = domain.NewAccount(owner); err != nil {
+		return
+	}
+
+	if cmd.ProjectName, err = domain.NewProjectName(req.ProjectName); err != nil {
+		return
+	}
+
+	if req.Commit != "" && !domain.IsCommit(req.Commit) {
+		err = errors.New("invalid commit")
+
+		return
+	}
+
+	if req.Ref != "" && !domain.IsRef(req.Ref) {
+		err = errors.New("invalid ref")
+
+		return
+	}
+
+	if req.CodeDir != "" {
+		var d domain.Directory
+		if d, err = domain.NewDirectory(req.CodeDir); err != nil {
+			return
+		}
+
+		cmd.CodeDir = d.Directory()
+	}
+
+	cmd.RepoId = repoId
+	cmd.Ref = req.Ref
+	cmd.Commit = req.Commit
+
+	return
+}
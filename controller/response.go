@@ -1,6 +1,9 @@
 package controller
 
 const (
+	errorNotFound        = "not_found"
+	errorForbidden       = "forbidden"
+	errorUnauthorized    = "unauthorized"
 	errorSystemError     = "system_error"
 	errorBadRequestBody  = "bad_request_body"
 	errorBadRequestParam = "bad_request_param"
@@ -0,0 +1,155 @@
+package controller
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/opensourceways/xihe-training-center/app"
+	"github.com/opensourceways/xihe-training-center/domain"
+	"github.com/opensourceways/xihe-training-center/domain/secret"
+)
+
+func AddRouterForSecretController(
+	rg *gin.RouterGroup,
+	ss app.SecretService,
+) {
+	ctl := SecretController{ss: ss}
+
+	rg.PUT("/v1/secrets/:owner/:name", ctl.Save)
+	rg.GET("/v1/secrets/:owner", ctl.List)
+	rg.DELETE("/v1/secrets/:owner/:name", ctl.Delete)
+}
+
+type SecretController struct {
+	baseController
+
+	ss app.SecretService
+}
+
+// @Summary Save
+// @Description create the secret or update its value
+// @Tags  Secret
+// @Param	owner	path	string			true	"owner of secret"
+// @Param	name	path	string			true	"name of secret"
+// @Param	body	body 	SecretSaveRequest	true	"body of saving secret"
+// @Accept json
+// @Success 204
+// @Failure 400 bad_request_body    can't parse request body
+// @Failure 400 bad_request_param   some parameter of body is invalid
+// @Failure 403 forbidden           can't access the resource of other user
+// @Failure 500 system_error        system error
+// @Router /v1/secrets/{owner}/{name} [put]
+func (ctl *SecretController) Save(ctx *gin.Context) {
+	req := SecretSaveRequest{}
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, respBadRequestBody)
+
+		return
+	}
+
+	cmd, err := req.toCmd(ctx.Param("owner"), ctx.Param("name"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, newResponseCodeError(
+			errorBadRequestParam, err,
+		))
+
+		return
+	}
+
+	if !ctl.checkOwner(ctx, cmd.Owner) {
+		return
+	}
+
+	if err := ctl.ss.Save(&cmd); err != nil {
+		ctl.sendRespWithInternalError(ctx, newResponseError(err))
+
+		return
+	}
+
+	ctx.JSON(http.StatusNoContent, newResponseData("success"))
+}
+
+// @Summary List
+// @Description list the secrets of user without the values
+// @Tags  Secret
+// @Param	owner	path	string	true	"owner of secrets"
+// @Accept json
+// @Success 200 {object} app.SecretDTO
+// @Failure 400 bad_request_param   some parameter is invalid
+// @Failure 403 forbidden           can't access the resource of other user
+// @Failure 500 system_error        system error
+// @Router /v1/secrets/{owner} [get]
+func (ctl *SecretController) List(ctx *gin.Context) {
+	owner, err := domain.NewAccount(ctx.Param("owner"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, newResponseCodeError(
+			errorBadRequestParam, err,
+		))
+
+		return
+	}
+
+	if !ctl.checkOwner(ctx, owner) {
+		return
+	}
+
+	v, err := ctl.ss.List(owner)
+	if err != nil {
+		ctl.sendRespWithInternalError(ctx, newResponseError(err))
+
+		return
+	}
+
+	ctx.JSON(http.StatusOK, newResponseData(v))
+}
+
+// @Summary Delete
+// @Description delete the secret
+// @Tags  Secret
+// @Param	owner	path	string	true	"owner of secret"
+// @Param	name	path	string	true	"name of secret"
+// @Accept json
+// @Success 204
+// @Failure 400 bad_request_param   some parameter is invalid
+// @Failure 404 not_found           the secret doesn't exist
+// @Failure 403 forbidden           can't access the resource of other user
+// @Failure 500 system_error        system error
+// @Router /v1/secrets/{owner}/{name} [delete]
+func (ctl *SecretController) Delete(ctx *gin.Context) {
+	owner, err := domain.NewAccount(ctx.Param("owner"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, newResponseCodeError(
+			errorBadRequestParam, err,
+		))
+
+		return
+	}
+
+	name, err := domain.NewSecretName(ctx.Param("name"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, newResponseCodeError(
+			errorBadRequestParam, err,
+		))
+
+		return
+	}
+
+	if !ctl.checkOwner(ctx, owner) {
+		return
+	}
+
+	if err := ctl.ss.Delete(owner, name); err != nil {
+		if secret.IsErrorSecretNotExists(err) {
+			ctx.JSON(http.StatusNotFound, newResponseCodeError(
+				errorNotFound, err,
+			))
+		} else {
+			ctl.sendRespWithInternalError(ctx, newResponseError(err))
+		}
+
+		return
+	}
+
+	ctx.JSON(http.StatusNoContent, newResponseData("success"))
+}
@@ -0,0 +1,26 @@
+package controller
+
+import (
+	"github.com/opensourceways/xihe-training-center/app"
+	"github.com/opensourceways/xihe-training-center/domain"
+)
+
+type SecretSaveRequest struct {
+	Value string `json:"value"`
+}
+
+func (req *SecretSaveRequest) toCmd(owner, name string) (cmd app.SecretSaveCmd, err error) {
+	if cmd.Owner, err = domain.NewAccount(owner); err != nil {
+		return
+	}
+
+	if cmd.Name, err = domain.NewSecretName(name); err != nil {
+		return
+	}
+
+	cmd.Value = req.Value
+
+	err = cmd.Validate()
+
+	return
+}
@@ -6,17 +6,21 @@ import (
 	"github.com/gin-gonic/gin"
 
 	"github.com/opensourceways/xihe-training-center/app"
+	"github.com/opensourceways/xihe-training-center/domain/jobowner"
+	"github.com/opensourceways/xihe-training-center/domain/secret"
 )
 
 func AddRouterForTrainingController(
 	rg *gin.RouterGroup,
 	ts app.TrainingService,
+	cs app.ComputeService,
 ) {
-	ctl := TrainingController{ts: ts}
+	ctl := TrainingController{ts: ts, cs: cs}
 
 	rg.POST("/v1/training", ctl.Create)
 	rg.DELETE("/v1/training/:id", ctl.Delete)
 	rg.PUT("/v1/training/:id", ctl.Terminate)
+	rg.GET("/v1/training/:id", ctl.Get)
 	rg.GET("/v1/training/:id/log", ctl.GetLog)
 }
 
@@ -24,6 +28,7 @@ type TrainingController struct {
 	baseController
 
 	ts app.TrainingService
+	cs app.ComputeService
 }
 
 // @Summary Create
@@ -34,6 +39,7 @@ type TrainingController struct {
 // @Success 201 {object} app.TrainingInfoDTO
 // @Failure 400 bad_request_body    can't parse request body
 // @Failure 401 bad_request_param   some parameter of body is invalid
+// @Failure 403 forbidden           can't create training for other user
 // @Failure 500 system_error        system error
 // @Router /v1/training [post]
 func (ctl *TrainingController) Create(ctx *gin.Context) {
@@ -44,7 +50,7 @@ func (ctl *TrainingController) Create(ctx *gin.Context) {
 		return
 	}
 
-	cmd, err := req.toCmd()
+	cmd, err := req.toCmd(ctl.cs)
 	if err != nil {
 		ctx.JSON(http.StatusBadRequest, newResponseCodeError(
 			errorBadRequestParam, err,
@@ -52,9 +58,19 @@ func (ctl *TrainingController) Create(ctx *gin.Context) {
 		return
 	}
 
+	if !ctl.checkOwner(ctx, cmd.User) {
+		return
+	}
+
 	v, err := ctl.ts.Create(&cmd)
 	if err != nil {
-		ctl.sendRespWithInternalError(ctx, newResponseError(err))
+		if app.IsErrorInvalidCode(err) || secret.IsErrorSecretNotExists(err) {
+			ctx.JSON(http.StatusBadRequest, newResponseCodeError(
+				errorBadRequestParam, err,
+			))
+		} else {
+			ctl.sendRespWithInternalError(ctx, newResponseError(err))
+		}
 
 		return
 	}
@@ -68,10 +84,15 @@ func (ctl *TrainingController) Create(ctx *gin.Context) {
 // @Param	id	path	string	true	"id of training"
 // @Accept json
 // @Success 204
+// @Failure 403 forbidden           can't access the training of other user
 // @Failure 500 system_error        system error
 // @Router /v1/training/{id} [delete]
 func (ctl *TrainingController) Delete(ctx *gin.Context) {
 	jobId := ctx.Param("id")
+	if !ctl.checkJobOwner(ctx, jobId) {
+		return
+	}
+
 	if err := ctl.ts.Delete(jobId); err != nil {
 		ctl.sendRespWithInternalError(ctx, newResponseError(err))
 
@@ -87,10 +108,15 @@ func (ctl *TrainingController) Delete(ctx *gin.Context) {
 // @Param	id	path	string	true	"id of training"
 // @Accept json
 // @Success 202
+// @Failure 403 forbidden           can't access the training of other user
 // @Failure 500 system_error        system error
 // @Router /v1/training/{id} [put]
 func (ctl *TrainingController) Terminate(ctx *gin.Context) {
 	jobId := ctx.Param("id")
+	if !ctl.checkJobOwner(ctx, jobId) {
+		return
+	}
+
 	if err := ctl.ts.Terminate(jobId); err != nil {
 		ctl.sendRespWithInternalError(ctx, newResponseError(err))
 
@@ -100,16 +126,47 @@ func (ctl *TrainingController) Terminate(ctx *gin.Context) {
 	ctx.JSON(http.StatusAccepted, newResponseData("success"))
 }
 
+// @Summary Get
+// @Description get detail of training
+// @Tags  Training
+// @Param	id	path	string	true	"id of training"
+// @Accept json
+// @Success 200 {object} app.JobDetailDTO
+// @Failure 403 forbidden           can't access the training of other user
+// @Failure 500 system_error        system error
+// @Router /v1/training/{id} [get]
+func (ctl *TrainingController) Get(ctx *gin.Context) {
+	jobId := ctx.Param("id")
+	if !ctl.checkJobOwner(ctx, jobId) {
+		return
+	}
+
+	v, err := ctl.ts.GetDetail(jobId)
+	if err != nil {
+		ctl.sendRespWithInternalError(ctx, newResponseError(err))
+
+		return
+	}
+
+	ctx.JSON(http.StatusOK, newResponseData(v))
+}
+
 // @Summary GetLog
 // @Description get log url of training for downloading
 // @Tags  Training
 // @Param	id	path	string	true	"id of training"
 // @Accept json
 // @Success 200 {object} TrainingLogResp
+// @Failure 403 forbidden           can't access the training of other user
 // @Failure 500 system_error        system error
 // @Router /v1/training/{id}/log [get]
 func (ctl *TrainingController) GetLog(ctx *gin.Context) {
-	v, err := ctl.ts.GetLogDownloadURL(ctx.Param("id"))
+	jobId := ctx.Param("id")
+	if !ctl.checkJobOwner(ctx, jobId) {
+		return
+	}
+
+	v, err := ctl.ts.GetLogDownloadURL(jobId)
 	if err != nil {
 		ctl.sendRespWithInternalError(ctx, newResponseError(err))
 
@@ -118,3 +175,25 @@ func (ctl *TrainingController) GetLog(ctx *gin.Context) {
 
 	ctx.JSON(http.StatusAccepted, newResponseData(TrainingLogResp{v}))
 }
+
+// checkJobOwner checks whether the caller can access the training. The user
+// can't access the training whose owner is unknown, such as the one created
+// before the owners are recorded.
+func (ctl *TrainingController) checkJobOwner(ctx *gin.Context, jobId string) bool {
+	if c, ok := getCaller(ctx); !ok || !c.IsUser() {
+		return true
+	}
+
+	owner, err := ctl.ts.GetOwner(jobId)
+	if err != nil {
+		if jobowner.IsErrorJobOwnerNotExists(err) {
+			ctx.JSON(http.StatusForbidden, respForbidden)
+		} else {
+			ctl.sendRespWithInternalError(ctx, newResponseError(err))
+		}
+
+		return false
+	}
+
+	return ctl.checkOwner(ctx, owner)
+}
@@ -18,54 +18,175 @@ type TrainingCreateRequest struct {
 	ProjectName   string `json:"project_name"`
 	ProjectRepoId string `json:"project_repo_id"`
 
+	// Commit is optional. The latest commit of project will be used if it is empty.
+	Commit string `json:"commit"`
+
+	// Ref is optional. It is the branch or tag which the latest commit
+	// is got from. The default branch will be used if it is empty.
+	Ref string `json:"ref"`
+
 	Name string `json:"name"`
 	Desc string `json:"desc"`
 
-	CodeDir  string `json:"code_dir"`
+	CodeDir string `json:"code_dir"`
+
+	// BootFile is optional if the custom image is used.
 	BootFile string `json:"boot_file"`
 
+	// SyncCodeDirOnly specifies whether to sync only the code dir
+	// of project, which is faster for the repo with large files.
+	SyncCodeDirOnly bool `json:"sync_code_dir_only"`
+
+	// Dependencies is optional. They are the python packages, such as
+	// numpy==1.21.0, which are installed before running the boot file.
+	// The requirements.txt of code dir is installed too if it exists.
+	// They can't be set with the custom image.
+	Dependencies []string `json:"dependencies"`
+
 	Hypeparameters []KeyValue `json:"hyperparameter"`
 	Env            []KeyValue `json:"evn"`
 	Inputs         []Input    `json:"inputs"`
 
+	// ParameterRender is optional. It specifies how the hyperparameters
+	// are passed to the boot file, and they are passed as flags if empty.
+	ParameterRender *ParameterRender `json:"parameter_render,omitempty"`
+
 	Compute Compute `json:"compute"`
+
+	// WaitForInputs specifies whether to wait for the inputs
+	// until they are ready instead of failing immediately.
+	WaitForInputs bool `json:"wait_for_inputs"`
 }
 
 type Compute struct {
 	Type    string `json:"type"`
 	Version string `json:"version"`
 	Flavor  string `json:"flavor"`
+
+	// Image is optional. The training runs in the custom image if it is set,
+	// and the type and version must be empty in that case.
+	Image *CustomImage `json:"image,omitempty"`
 }
 
-func (c *Compute) toCompute() (r domain.Compute, err error) {
-	if c.Type == "" || c.Version == "" || c.Flavor == "" {
+type CustomImage struct {
+	// URL is the url of image, such as swr.cn-north-4.myhuaweicloud.com/org/image:tag
+	URL     string `json:"url"`
+	Command string `json:"command"`
+
+	// WorkDir is optional, it is relative to the code dir where the command runs.
+	WorkDir string `json:"work_dir"`
+
+	// UserId is optional, it is the default user of image if it is 0.
+	UserId int `json:"user_id"`
+}
+
+// toCompute converts the compute and checks it against the catalog.
+func (c *Compute) toCompute(cs app.ComputeService) (r domain.Compute, err error) {
+	if c.Flavor == "" {
 		err = errors.New("invalid compute info")
 
 		return
 	}
 
-	if r.Type, err = domain.NewComputeType(c.Type); err != nil {
+	if c.Image != nil {
+		if c.Type != "" || c.Version != "" {
+			err = errors.New("type and version can't be set with custom image")
+
+			return
+		}
+
+		if r.Image, err = c.Image.toCustomImage(); err != nil {
+			return
+		}
+	} else {
+		if c.Type == "" || c.Version == "" {
+			err = errors.New("invalid compute info")
+
+			return
+		}
+
+		if r.Type, err = domain.NewComputeType(c.Type); err != nil {
+			return
+		}
+
+		if r.Version, err = domain.NewComputeVersion(c.Version); err != nil {
+			return
+		}
+	}
+
+	if r.Flavor, err = domain.NewComputeFlavor(c.Flavor); err != nil {
+		return
+	}
+
+	err = cs.Check(&r)
+
+	return
+}
+
+func (c *CustomImage) toCustomImage() (r *domain.CustomImage, err error) {
+	if c.UserId < 0 {
+		return nil, errors.New("invalid user id of custom image")
+	}
+
+	v := domain.CustomImage{UserId: c.UserId}
+
+	if v.URL, err = domain.NewImageURL(c.URL); err != nil {
+		return
+	}
+
+	if v.Command, err = domain.NewImageCommand(c.Command); err != nil {
 		return
 	}
 
-	if r.Version, err = domain.NewComputeVersion(c.Version); err != nil {
+	if v.WorkDir, err = domain.NewDirectory(c.WorkDir); err != nil {
 		return
 	}
 
-	if r.Flavor, err = domain.NewComputeFlavor(c.Flavor); err != nil {
+	return &v, nil
+}
+
+type ParameterRender struct {
+	// Mode is one of flags, file and template, and it is flags if empty.
+	//   flags:    --name=value
+	//   file:     the path of the file which includes the hyperparameters
+	//   template: the arguments rendered from the template
+	Mode string `json:"mode"`
+
+	// FileFormat is json or yaml for the file mode, and it is json if empty.
+	FileFormat string `json:"file_format"`
+
+	// Template is the template of arguments for the template mode,
+	// such as "${data_path} --lr ${lr}".
+	Template string `json:"template"`
+}
+
+func (p *ParameterRender) toParameterRender() (r domain.ParameterRender, err error) {
+	if r.Mode, err = domain.NewParameterMode(p.Mode); err != nil {
 		return
 	}
 
+	switch r.Mode.ParameterMode() {
+	case domain.ParameterModeFile.ParameterMode():
+		r.FileFormat, err = domain.NewParameterFileFormat(p.FileFormat)
+
+	case domain.ParameterModeTemplate.ParameterMode():
+		r.Template, err = domain.NewParameterTemplate(p.Template)
+	}
+
 	return
 }
 
 type KeyValue struct {
 	Key   string `json:"key"`
 	Value string `json:"value"`
+
+	// Secret is the name of secret whose value is the value of env.
+	// It is supported only by the env, and Value must be empty if set.
+	Secret string `json:"secret,omitempty"`
 }
 
 func (kv *KeyValue) toKeyValue() (r domain.KeyValue, err error) {
-	if kv.Key == "" {
+	if kv.Key == "" || kv.Secret != "" {
 		err = errors.New("invalid key value")
 
 		return
@@ -106,6 +227,10 @@ type ResourceRef struct {
 	Type   string `json:"type"`
 	RepoId string `json:"repo_id"`
 	File   string `json:"File"`
+
+	// Commit is optional. The latest commit of resource will be used if it is empty.
+	// The resolved commit is recorded and returned when getting the training.
+	Commit string `json:"commit"`
 }
 
 func (r *ResourceRef) toRef(i *domain.ResourceRef) (err error) {
@@ -129,13 +254,20 @@ func (r *ResourceRef) toRef(i *domain.ResourceRef) (err error) {
 		return
 	}
 
+	if r.Commit != "" && !domain.IsCommit(r.Commit) {
+		err = errors.New("invalid commit of resource input")
+
+		return
+	}
+
 	i.RepoId = r.RepoId
 	i.File = r.File
+	i.Commit = r.Commit
 
 	return
 }
 
-func (req *TrainingCreateRequest) toCmd() (cmd app.TrainingCreateCmd, err error) {
+func (req *TrainingCreateRequest) toCmd(cs app.ComputeService) (cmd app.TrainingCreateCmd, err error) {
 	if cmd.User, err = domain.NewAccount(req.User); err != nil {
 		return
 	}
@@ -147,6 +279,28 @@ func (req *TrainingCreateRequest) toCmd() (cmd app.TrainingCreateCmd, err error)
 	cmd.ProjectId = req.ProjectId
 	cmd.TrainingId = req.TrainingId
 	cmd.ProjectRepoId = req.ProjectRepoId
+	cmd.WaitForInputs = req.WaitForInputs
+	cmd.SyncCodeDirOnly = req.SyncCodeDirOnly
+
+	if req.Commit != "" {
+		if !domain.IsCommit(req.Commit) {
+			err = errors.New("invalid commit")
+
+			return
+		}
+
+		cmd.ProjectCommit = req.Commit
+	}
+
+	if req.Ref != "" {
+		if !domain.IsRef(req.Ref) {
+			err = errors.New("invalid ref")
+
+			return
+		}
+
+		cmd.ProjectRef = req.Ref
+	}
 
 	if cmd.Name, err = domain.NewTrainingName(req.Name); err != nil {
 		return
@@ -160,11 +314,18 @@ func (req *TrainingCreateRequest) toCmd() (cmd app.TrainingCreateCmd, err error)
 		return
 	}
 
-	if cmd.BootFile, err = domain.NewFilePath(req.BootFile); err != nil {
+	// the boot file is optional for the custom image.
+	if req.BootFile != "" || req.Compute.Image == nil {
+		if cmd.BootFile, err = domain.NewFilePath(req.BootFile); err != nil {
+			return
+		}
+	}
+
+	if cmd.Compute, err = req.Compute.toCompute(cs); err != nil {
 		return
 	}
 
-	if cmd.Compute, err = req.Compute.toCompute(); err != nil {
+	if cmd.Dependencies, err = req.toDependencies(); err != nil {
 		return
 	}
 
@@ -172,7 +333,13 @@ func (req *TrainingCreateRequest) toCmd() (cmd app.TrainingCreateCmd, err error)
 		return
 	}
 
-	if cmd.Env, err = req.toKeyValue(req.Env); err != nil {
+	if req.ParameterRender != nil {
+		if cmd.ParameterRender, err = req.toParameterRender(cmd.Hypeparameters); err != nil {
+			return
+		}
+	}
+
+	if cmd.Env, cmd.SecretEnv, err = req.toEnv(); err != nil {
 		return
 	}
 
@@ -183,6 +350,87 @@ func (req *TrainingCreateRequest) toCmd() (cmd app.TrainingCreateCmd, err error)
 	return
 }
 
+func (req *TrainingCreateRequest) toDependencies() (r []domain.Dependency, err error) {
+	n := len(req.Dependencies)
+	if n == 0 {
+		return nil, nil
+	}
+
+	if req.Compute.Image != nil {
+		return nil, errors.New("dependencies can't be set with custom image")
+	}
+
+	r = make([]domain.Dependency, n)
+	for i, v := range req.Dependencies {
+		if r[i], err = domain.NewDependency(v); err != nil {
+			return
+		}
+	}
+
+	return
+}
+
+func (req *TrainingCreateRequest) toParameterRender(
+	hyperparameters []domain.KeyValue,
+) (r domain.ParameterRender, err error) {
+	if r, err = req.ParameterRender.toParameterRender(); err != nil || r.IsFlags() {
+		return
+	}
+
+	if req.Compute.Image != nil {
+		err = errors.New("only the flags mode is supported by custom image")
+
+		return
+	}
+
+	if r.Template != nil {
+		// check whether all the placeholders are hyperparameters.
+		_, err = r.Template.Render(hyperparameters)
+	}
+
+	return
+}
+
+func (req *TrainingCreateRequest) toEnv() (
+	env []domain.KeyValue, secretEnv []domain.SecretEnv, err error,
+) {
+	var v domain.KeyValue
+
+	for i := range req.Env {
+		item := &req.Env[i]
+
+		if item.Secret == "" {
+			if v, err = item.toKeyValue(); err != nil {
+				return
+			}
+
+			env = append(env, v)
+
+			continue
+		}
+
+		if item.Value != "" {
+			err = errors.New("the value of env can't be set with secret")
+
+			return
+		}
+
+		e := domain.SecretEnv{}
+
+		if e.Key, err = domain.NewCustomizedKey(item.Key); err != nil {
+			return
+		}
+
+		if e.Secret, err = domain.NewSecretName(item.Secret); err != nil {
+			return
+		}
+
+		secretEnv = append(secretEnv, e)
+	}
+
+	return
+}
+
 func (req *TrainingCreateRequest) toKeyValue(kv []KeyValue) (r []domain.KeyValue, err error) {
 	n := len(kv)
 	if n == 0 {
@@ -0,0 +1,87 @@
+package domain
+
+import "fmt"
+
+// ComputeEngine is the engine of training and its versions.
+type ComputeEngine struct {
+	Type     string
+	Versions []string
+}
+
+// ComputeFlavorInfo is the flavor of the resource to run training.
+type ComputeFlavorInfo struct {
+	Flavor string
+	Desc   string
+}
+
+// ComputeCatalog includes the engines and flavors which can be used.
+type ComputeCatalog struct {
+	Engines []ComputeEngine
+	Flavors []ComputeFlavorInfo
+
+	// ImageRegistries are the registries of the custom images which
+	// can be used. The custom image is not allowed if it is empty.
+	ImageRegistries []string
+}
+
+// Check checks whether the compute is in the catalog. The engine or
+// flavor is not checked if the corresponding list of catalog is empty.
+func (c *ComputeCatalog) Check(v *Compute) error {
+	if v.Image != nil {
+		if err := c.checkImage(v.Image); err != nil {
+			return err
+		}
+	} else if len(c.Engines) > 0 {
+		if err := c.checkEngine(v); err != nil {
+			return err
+		}
+	}
+
+	if len(c.Flavors) > 0 {
+		f := v.Flavor.ComputeFlavor()
+
+		for i := range c.Flavors {
+			if c.Flavors[i].Flavor == f {
+				return nil
+			}
+		}
+
+		return fmt.Errorf("unsupported compute flavor: %s", f)
+	}
+
+	return nil
+}
+
+func (c *ComputeCatalog) checkEngine(v *Compute) error {
+	t := v.Type.ComputeType()
+
+	for i := range c.Engines {
+		e := &c.Engines[i]
+		if e.Type != t {
+			continue
+		}
+
+		version := v.Version.ComputeVersion()
+		for _, item := range e.Versions {
+			if item == version {
+				return nil
+			}
+		}
+
+		return fmt.Errorf("unsupported version: %s of compute type: %s", version, t)
+	}
+
+	return fmt.Errorf("unsupported compute type: %s", t)
+}
+
+func (c *ComputeCatalog) checkImage(v *CustomImage) error {
+	r := v.URL.Registry()
+
+	for _, item := range c.ImageRegistries {
+		if item == r {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("the registry: %s of custom image is not allowed", r)
+}
@@ -0,0 +1,11 @@
+package compute
+
+import (
+	"github.com/opensourceways/xihe-training-center/domain"
+)
+
+// Compute lists the engines and flavors supported by the training platform.
+type Compute interface {
+	ListEngines() ([]domain.ComputeEngine, error)
+	ListFlavors() ([]domain.ComputeFlavorInfo, error)
+}
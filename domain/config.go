@@ -1,9 +1,27 @@
 package domain
 
-var config Config
+import (
+	"errors"
+	"sync"
+)
 
+var (
+	config     Config
+	configLock sync.RWMutex
+)
+
+// Init sets the config. It can be called again to reload the config.
 func Init(cfg *Config) {
+	configLock.Lock()
 	config = *cfg
+	configLock.Unlock()
+}
+
+func getConfig() Config {
+	configLock.RLock()
+	defer configLock.RUnlock()
+
+	return config
 }
 
 type Config struct {
@@ -25,3 +43,15 @@ func (r *Config) SetDefault() {
 		r.MaxTrainingDescLength = 100
 	}
 }
+
+func (r *Config) Validate() error {
+	if r.MinTrainingNameLength <= 0 || r.MaxTrainingNameLength < r.MinTrainingNameLength {
+		return errors.New("invalid length of training name")
+	}
+
+	if r.MaxTrainingDescLength <= 0 {
+		return errors.New("invalid length of training desc")
+	}
+
+	return nil
+}
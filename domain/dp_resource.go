@@ -2,6 +2,7 @@ package domain
 
 import (
 	"errors"
+	"regexp"
 	"strings"
 )
 
@@ -11,6 +12,11 @@ const (
 	resourceModel   = "model"
 )
 
+var (
+	reCommit = regexp.MustCompile("^[0-9a-f]{40}$")
+	reRef    = regexp.MustCompile("^[a-zA-Z0-9_][a-zA-Z0-9_./-]*$")
+)
+
 var (
 	ResourceTypeProject ResourceType = resourceType(resourceProject)
 	ResourceTypeModel   ResourceType = resourceType(resourceModel)
@@ -54,3 +60,16 @@ type projectName string
 func (r projectName) ProjectName() string {
 	return string(r)
 }
+
+// IsCommit checks whether v is a full commit sha.
+func IsCommit(v string) bool {
+	return reCommit.MatchString(v)
+}
+
+// IsRef checks whether v is a valid name of branch or tag.
+func IsRef(v string) bool {
+	return len(v) <= 255 && reRef.MatchString(v) &&
+		!strings.Contains(v, "..") && !strings.Contains(v, "//") &&
+		!strings.HasSuffix(v, "/") && !strings.HasSuffix(v, ".") &&
+		!strings.HasSuffix(v, ".lock")
+}
@@ -14,10 +14,24 @@ var (
 	reDirectory = regexp.MustCompile("^[a-zA-Z0-9_/-]+$")
 	reFilePath  = regexp.MustCompile("^[a-zA-Z0-9_/.-]+$")
 
+	// reDependency matches the requirement of pip, such as numpy>=1.20,<2.0,
+	// but doesn't match the options of pip or the urls.
+	reDependency = regexp.MustCompile(
+		`^[a-zA-Z0-9][a-zA-Z0-9._-]*(\[[a-zA-Z0-9._,-]+\])?` +
+			`((==|!=|<=|>=|~=|<|>)[a-zA-Z0-9.*+!_-]+)?(,(==|!=|<=|>=|~=|<|>)[a-zA-Z0-9.*+!_-]+)*$`,
+	)
+
+	// reImageURL matches registry/path:tag or registry/path@sha256:digest
+	reImageURL = regexp.MustCompile(
+		`^([a-z0-9.-]+(:[0-9]+)?)/[a-z0-9._/-]+(:[a-zA-Z0-9._-]+|@sha256:[a-f0-9]{64})$`,
+	)
+
 	TrainingStatusFailed     = trainingStatus("Failed")
 	TrainingStatusRunning    = trainingStatus("Running")
 	TrainingStatusCompleted  = trainingStatus("Completed")
 	TrainingStatusTerminated = trainingStatus("Terminated")
+
+	TrainingStatusWaitingForInputs = trainingStatus("WaitingForInputs")
 )
 
 // Account
@@ -45,8 +59,9 @@ type TrainingName interface {
 }
 
 func NewTrainingName(v string) (TrainingName, error) {
-	max := config.MaxTrainingNameLength
-	min := config.MinTrainingNameLength
+	cfg := getConfig()
+	max := cfg.MaxTrainingNameLength
+	min := cfg.MinTrainingNameLength
 
 	if n := len(v); n > max || n < min {
 		return nil, fmt.Errorf("name's length should be between %d to %d", min, max)
@@ -75,7 +90,7 @@ func NewTrainingDesc(v string) (TrainingDesc, error) {
 		return nil, nil
 	}
 
-	max := config.MaxTrainingDescLength
+	max := getConfig().MaxTrainingDescLength
 	if len(v) > max {
 		return nil, fmt.Errorf("the length of desc should be less than %d", max)
 	}
@@ -192,6 +207,74 @@ func (r computeFlavor) ComputeFlavor() string {
 	return string(r)
 }
 
+// ImageURL
+type ImageURL interface {
+	ImageURL() string
+
+	// Registry returns the host of registry which the image belongs to.
+	Registry() string
+}
+
+func NewImageURL(v string) (ImageURL, error) {
+	if v == "" {
+		return nil, errors.New("empty image url")
+	}
+
+	if !reImageURL.MatchString(v) {
+		return nil, errors.New("invalid image url")
+	}
+
+	return imageURL(v), nil
+}
+
+type imageURL string
+
+func (r imageURL) ImageURL() string {
+	return string(r)
+}
+
+func (r imageURL) Registry() string {
+	return reImageURL.FindStringSubmatch(string(r))[1]
+}
+
+// ImageCommand
+type ImageCommand interface {
+	ImageCommand() string
+}
+
+func NewImageCommand(v string) (ImageCommand, error) {
+	if strings.TrimSpace(v) == "" {
+		return nil, errors.New("empty command")
+	}
+
+	return imageCommand(v), nil
+}
+
+type imageCommand string
+
+func (r imageCommand) ImageCommand() string {
+	return string(r)
+}
+
+// Dependency
+type Dependency interface {
+	Dependency() string
+}
+
+func NewDependency(v string) (Dependency, error) {
+	if !reDependency.MatchString(v) {
+		return nil, fmt.Errorf("invalid dependency: %s", v)
+	}
+
+	return dependency(v), nil
+}
+
+type dependency string
+
+func (r dependency) Dependency() string {
+	return string(r)
+}
+
 // CustomizedKey
 type CustomizedKey interface {
 	CustomizedKey() string
@@ -244,9 +327,28 @@ func (s trainingStatus) TrainingStatus() string {
 }
 
 func (s trainingStatus) IsDone() bool {
-	return string(s) != TrainingStatusRunning.TrainingStatus()
+	return s != TrainingStatusRunning && s != TrainingStatusWaitingForInputs
 }
 
 func (s trainingStatus) IsSuccess() bool {
 	return string(s) == TrainingStatusCompleted.TrainingStatus()
 }
+
+// SecretName
+type SecretName interface {
+	SecretName() string
+}
+
+func NewSecretName(v string) (SecretName, error) {
+	if v == "" || len(v) > 64 || !reName.MatchString(v) {
+		return nil, errors.New("invalid secret name")
+	}
+
+	return secretName(v), nil
+}
+
+type secretName string
+
+func (r secretName) SecretName() string {
+	return string(r)
+}
@@ -0,0 +1,42 @@
+package jobowner
+
+import (
+	"github.com/opensourceways/xihe-training-center/domain"
+)
+
+type errorJobOwnerNotExists struct {
+	error
+}
+
+func NewErrorJobOwnerNotExists(err error) errorJobOwnerNotExists {
+	return errorJobOwnerNotExists{err}
+}
+
+func IsErrorJobOwnerNotExists(err error) bool {
+	_, ok := err.(errorJobOwnerNotExists)
+
+	return ok
+}
+
+// JobCommits are the resolved commits which the training job runs on,
+// so that the training can be reproduced.
+type JobCommits struct {
+	Project string
+	Inputs  []InputCommit
+}
+
+// InputCommit is the resolved commit of the input named by Key.
+type InputCommit struct {
+	Key    string
+	Commit string
+}
+
+// JobOwner records the user who creates the training job, so that
+// the job can only be accessed by its owner. The commits which the
+// job runs on are recorded together.
+type JobOwner interface {
+	Save(jobId string, owner domain.Account, commits *JobCommits) error
+	Find(jobId string) (domain.Account, error)
+	FindCommits(jobId string) (JobCommits, error)
+	Delete(jobId string) error
+}
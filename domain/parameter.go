@@ -0,0 +1,156 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+const (
+	parameterModeFlags    = "flags"
+	parameterModeFile     = "file"
+	parameterModeTemplate = "template"
+
+	parameterFileFormatJSON = "json"
+	parameterFileFormatYAML = "yaml"
+)
+
+var (
+	ParameterModeFlags    = parameterMode(parameterModeFlags)
+	ParameterModeFile     = parameterMode(parameterModeFile)
+	ParameterModeTemplate = parameterMode(parameterModeTemplate)
+
+	ParameterFileFormatJSON = parameterFileFormat(parameterFileFormatJSON)
+	ParameterFileFormatYAML = parameterFileFormat(parameterFileFormatYAML)
+
+	// reParameterPlaceholder matches the placeholder, such as ${lr},
+	// of hyperparameter in the template of arguments.
+	reParameterPlaceholder = regexp.MustCompile(`\$\{([^}]*)\}`)
+)
+
+// ParameterRender specifies how the hyperparameters are passed to the boot file.
+type ParameterRender struct {
+	Mode ParameterMode
+
+	// FileFormat is the format of the file which the hyperparameters
+	// are written to. It is used when Mode is file.
+	FileFormat ParameterFileFormat
+
+	// Template is the template of arguments. It is used when Mode is template.
+	Template ParameterTemplate
+}
+
+// IsFlags checks whether the hyperparameters are passed as --name=value.
+func (r *ParameterRender) IsFlags() bool {
+	return r.Mode == nil || r.Mode.ParameterMode() == parameterModeFlags
+}
+
+// ParameterMode
+type ParameterMode interface {
+	ParameterMode() string
+}
+
+// NewParameterMode returns the mode, it is flags if v is empty.
+func NewParameterMode(v string) (ParameterMode, error) {
+	switch v {
+	case "", parameterModeFlags:
+		return ParameterModeFlags, nil
+
+	case parameterModeFile, parameterModeTemplate:
+		return parameterMode(v), nil
+	}
+
+	return nil, fmt.Errorf("unknown parameter mode: %s", v)
+}
+
+type parameterMode string
+
+func (r parameterMode) ParameterMode() string {
+	return string(r)
+}
+
+// ParameterFileFormat
+type ParameterFileFormat interface {
+	ParameterFileFormat() string
+}
+
+// NewParameterFileFormat returns the format, it is json if v is empty.
+func NewParameterFileFormat(v string) (ParameterFileFormat, error) {
+	switch v {
+	case "", parameterFileFormatJSON:
+		return ParameterFileFormatJSON, nil
+
+	case parameterFileFormatYAML:
+		return ParameterFileFormatYAML, nil
+	}
+
+	return nil, fmt.Errorf("unknown parameter file format: %s", v)
+}
+
+type parameterFileFormat string
+
+func (r parameterFileFormat) ParameterFileFormat() string {
+	return string(r)
+}
+
+// ParameterTemplate
+type ParameterTemplate interface {
+	ParameterTemplate() string
+
+	// Render renders the template with the hyperparameters and returns
+	// the arguments. The template is split into arguments by whitespace
+	// before rendering, so a value with whitespace is still one argument.
+	Render([]KeyValue) ([]string, error)
+}
+
+// NewParameterTemplate returns the template, such as "${data} --lr ${lr}".
+func NewParameterTemplate(v string) (ParameterTemplate, error) {
+	if strings.TrimSpace(v) == "" {
+		return nil, errors.New("empty parameter template")
+	}
+
+	for _, m := range reParameterPlaceholder.FindAllStringSubmatch(v, -1) {
+		if m[1] == "" {
+			return nil, errors.New("empty placeholder of parameter template")
+		}
+	}
+
+	return parameterTemplate(v), nil
+}
+
+type parameterTemplate string
+
+func (r parameterTemplate) ParameterTemplate() string {
+	return string(r)
+}
+
+func (r parameterTemplate) Render(kv []KeyValue) ([]string, error) {
+	values := make(map[string]string, len(kv))
+	for i := range kv {
+		v := ""
+		if kv[i].Value != nil {
+			v = kv[i].Value.CustomizedValue()
+		}
+
+		values[kv[i].Key.CustomizedKey()] = v
+	}
+
+	var err error
+
+	args := strings.Fields(string(r))
+	for i := range args {
+		args[i] = reParameterPlaceholder.ReplaceAllStringFunc(args[i], func(s string) string {
+			k := s[2 : len(s)-1]
+
+			v, ok := values[k]
+			if !ok && err == nil {
+				err = fmt.Errorf("unknown hyperparameter: %s in the template", k)
+			}
+
+			return v
+		})
+	}
+
+	return args, err
+}
@@ -0,0 +1,122 @@
+package domain
+
+import (
+	"reflect"
+	"testing"
+)
+
+func newTestKeyValues(t *testing.T, kv ...string) []KeyValue {
+	t.Helper()
+
+	r := make([]KeyValue, len(kv)/2)
+	for i := range r {
+		k, err := NewCustomizedKey(kv[2*i])
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		v, err := NewCustomizedValue(kv[2*i+1])
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		r[i] = KeyValue{Key: k, Value: v}
+	}
+
+	return r
+}
+
+func TestNewParameterTemplate(t *testing.T) {
+	cases := []struct {
+		v       string
+		wantErr bool
+	}{
+		{"--lr ${lr}", false},
+		{"train", false},
+		{"", true},
+		{"  ", true},
+		{"--lr ${}", true},
+	}
+
+	for _, c := range cases {
+		if _, err := NewParameterTemplate(c.v); (err != nil) != c.wantErr {
+			t.Errorf("NewParameterTemplate(%q) err = %v, want error %v", c.v, err, c.wantErr)
+		}
+	}
+}
+
+func TestParameterTemplateRender(t *testing.T) {
+	kv := newTestKeyValues(t, "lr", "0.1", "data", "/a b", "empty", "")
+
+	cases := []struct {
+		name     string
+		template string
+		want     []string
+		wantErr  bool
+	}{
+		{
+			name:     "placeholders",
+			template: "${data} --lr ${lr}",
+			want:     []string{"/a b", "--lr", "0.1"},
+		},
+		{
+			name:     "placeholder in the argument",
+			template: "--lr=${lr} --opt=${lr},${lr}",
+			want:     []string{"--lr=0.1", "--opt=0.1,0.1"},
+		},
+		{
+			name:     "empty value",
+			template: "--x=${empty}",
+			want:     []string{"--x="},
+		},
+		{
+			name:     "no placeholder",
+			template: "  train   --fast ",
+			want:     []string{"train", "--fast"},
+		},
+		{
+			name:     "unknown hyperparameter",
+			template: "--lr ${lr} --epoch ${epoch}",
+			wantErr:  true,
+		},
+	}
+
+	for i := range cases {
+		c := &cases[i]
+
+		tmpl, err := NewParameterTemplate(c.template)
+		if err != nil {
+			t.Fatalf("case %s: %v", c.name, err)
+		}
+
+		v, err := tmpl.Render(kv)
+		if (err != nil) != c.wantErr {
+			t.Errorf("case %s: err = %v, want error %v", c.name, err, c.wantErr)
+
+			continue
+		}
+
+		if !c.wantErr && !reflect.DeepEqual(v, c.want) {
+			t.Errorf("case %s: args = %q, want %q", c.name, v, c.want)
+		}
+	}
+}
+
+func TestParameterRenderIsFlags(t *testing.T) {
+	cases := []struct {
+		mode ParameterMode
+		want bool
+	}{
+		{nil, true},
+		{ParameterModeFlags, true},
+		{ParameterModeFile, false},
+		{ParameterModeTemplate, false},
+	}
+
+	for _, c := range cases {
+		r := ParameterRender{Mode: c.mode}
+		if v := r.IsFlags(); v != c.want {
+			t.Errorf("IsFlags of mode %v = %v, want %v", c.mode, v, c.want)
+		}
+	}
+}
@@ -1,6 +1,19 @@
 package platform
 
+// Credential is the credential of accessing the repo by git.
+type Credential struct {
+	Username string
+	Password string
+}
+
 type Platform interface {
-	GetLastCommit(pid string) (string, error)
+	// GetLastCommit returns the last commit of the ref which
+	// is a branch or tag. ref is the default branch if it is empty.
+	GetLastCommit(pid, ref string) (string, error)
+
+	// GetCloneURL returns the url of repo which doesn't include
+	// the credential. Use GetCloneCredential to get it.
 	GetCloneURL(owner, repo string) string
+
+	GetCloneCredential() Credential
 }
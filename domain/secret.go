@@ -0,0 +1,16 @@
+package domain
+
+// Secret is the secret of user which can be referenced by the env of
+// training. Its value is injected only when submitting the training.
+type Secret struct {
+	Owner     Account
+	Name      SecretName
+	Value     string
+	UpdatedAt int64
+}
+
+// SecretEnv is the env of training whose value is the secret.
+type SecretEnv struct {
+	Key    CustomizedKey
+	Secret SecretName
+}
@@ -0,0 +1,29 @@
+package secret
+
+import (
+	"github.com/opensourceways/xihe-training-center/domain"
+)
+
+type errorSecretNotExists struct {
+	error
+}
+
+func NewErrorSecretNotExists(err error) errorSecretNotExists {
+	return errorSecretNotExists{err}
+}
+
+func IsErrorSecretNotExists(err error) bool {
+	_, ok := err.(errorSecretNotExists)
+
+	return ok
+}
+
+type Secret interface {
+	// Save creates the secret or updates its value if it exists.
+	Save(*domain.Secret) error
+	Find(domain.Account, domain.SecretName) (domain.Secret, error)
+
+	// List lists the secrets of user without the values.
+	List(domain.Account) ([]domain.Secret, error)
+	Delete(domain.Account, domain.SecretName) error
+}
@@ -44,7 +44,22 @@ type RepoSyncLock struct {
 	Id         string
 	Owner      Account
 	RepoId     string
+	Ref        string
 	Status     RepoSyncStatus
 	Version    int
 	LastCommit string
+
+	// Holder is the identity of instance which holds the lock.
+	Holder string
+
+	// Expiry is the unix time when the lease of lock expires.
+	Expiry int64
+}
+
+func (r *RepoSyncLock) IsLocked() bool {
+	return r.Status != nil && !r.Status.IsDone()
+}
+
+func (r *RepoSyncLock) IsExpired(now int64) bool {
+	return r.Expiry <= now
 }
@@ -18,7 +18,23 @@ func IsRepoSyncLockNotExist(err error) bool {
 	return ok
 }
 
+type errorConcurrentUpdating struct {
+	error
+}
+
+func NewErrorConcurrentUpdating(err error) errorConcurrentUpdating {
+	return errorConcurrentUpdating{err}
+}
+
+func IsErrorConcurrentUpdating(err error) bool {
+	_, ok := err.(errorConcurrentUpdating)
+
+	return ok
+}
+
 type RepoSyncLock interface {
-	Find(owner domain.Account, repoId string) (domain.RepoSyncLock, error)
+	// Find finds the lock of the ref of repo. The ref is
+	// empty if it is the default branch.
+	Find(owner domain.Account, repoId, ref string) (domain.RepoSyncLock, error)
 	Save(*domain.RepoSyncLock) (domain.RepoSyncLock, error)
 }
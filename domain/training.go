@@ -21,16 +21,39 @@ type TrainingConfig struct {
 	ProjectName   ProjectName
 	ProjectRepoId string
 
+	// ProjectCommit is the commit of project which the training runs on.
+	// It is the latest commit of project if it is not specified.
+	ProjectCommit string
+
+	// ProjectRef is the branch or tag of project which the latest
+	// commit is got from. It is the default branch if it is not specified.
+	ProjectRef string
+
 	Name TrainingName
 	Desc TrainingDesc
 
 	CodeDir  Directory
 	BootFile FilePath
 
+	// SyncCodeDirOnly specifies whether to sync only the CodeDir
+	// of project instead of the whole repo before training.
+	SyncCodeDirOnly bool
+
+	// Dependencies are the python packages which are installed before
+	// running the boot file, as well as the requirements file of CodeDir.
+	Dependencies []Dependency
+
 	Hypeparameters []KeyValue
 	Env            []KeyValue
 	Inputs         []Input
 
+	// SecretEnv is the env whose values are the secrets of user.
+	SecretEnv []SecretEnv
+
+	// ParameterRender specifies how the Hypeparameters
+	// are passed to the boot file.
+	ParameterRender ParameterRender
+
 	Compute Compute
 }
 
@@ -38,6 +61,23 @@ type Compute struct {
 	Type    ComputeType
 	Version ComputeVersion
 	Flavor  ComputeFlavor
+
+	// Image is the custom image which the training runs in.
+	// Type and Version are not set if it is set.
+	Image *CustomImage
+}
+
+type CustomImage struct {
+	URL     ImageURL
+	Command ImageCommand
+
+	// WorkDir is the directory relative to the code dir
+	// where the command runs.
+	WorkDir Directory
+
+	// UserId is the id of user who runs the command.
+	// It is the default user of image if it is 0.
+	UserId int
 }
 
 type KeyValue struct {
@@ -55,6 +95,11 @@ type ResourceRef struct {
 	Type   ResourceType
 	RepoId string
 	File   string
+
+	// Commit is the commit of resource which the training uses.
+	// It is the latest commit of resource if it is not specified.
+	// The training reads the immutable snapshot of the commit.
+	Commit string
 }
 
 func (r *ResourceRef) ToPath() string {
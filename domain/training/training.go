@@ -2,6 +2,7 @@ package training
 
 import (
 	"github.com/opensourceways/xihe-training-center/domain"
+	"github.com/opensourceways/xihe-training-center/domain/platform"
 )
 
 type ProjectInfo struct {
@@ -10,6 +11,21 @@ type ProjectInfo struct {
 	RepoId      string
 	RepoURL     string
 	StartCommit string
+
+	// Ref is the branch or tag which Commit belongs to.
+	// It is the default branch if it is empty.
+	Ref string
+
+	// CodeDir is the directory of repo to sync. The partial snapshot
+	// which includes only this directory will be synced if it is not
+	// empty, and it is saved apart from the snapshot of whole repo.
+	CodeDir string
+
+	// RepoCredential is the credential of cloning RepoURL.
+	RepoCredential platform.Credential
+
+	// Commit is the commit which the snapshot of project is synced to.
+	Commit string
 }
 
 type Training interface {
@@ -30,6 +46,28 @@ type Training interface {
 	// and return the obs path of that file.
 	GenAim(aimDir string) (string, error)
 
+	// SyncProject syncs the project to an immutable snapshot of
+	// ProjectInfo.Commit and returns the synced commit.
+	// ProjectInfo.StartCommit is used only when syncing the whole repo.
 	SyncProject(*ProjectInfo) (lastCommit string, err error)
+
+	// IsProjectSynced checks whether the snapshot of
+	// ProjectInfo.Commit has been synced completely.
+	IsProjectSynced(*ProjectInfo) (bool, error)
+
+	// IsCodeDirEmpty checks whether there is no file under the code dir
+	// of training in the synced snapshot of project.
+	IsCodeDirEmpty(*domain.UserTraining) (bool, error)
+
+	// IsBootFileExist checks whether the boot file of training
+	// exists in the synced snapshot of project.
+	IsBootFileExist(*domain.UserTraining) (bool, error)
+
 	GetRepoSyncedCommit(*domain.ResourceRef) (c string, err error)
+
+	// SnapshotInput saves the synced resource to the immutable snapshot of
+	// ResourceRef.Commit which the training reads, so that the later syncs
+	// of resource won't change the input of running training. It fails if
+	// the resource is synced to another commit while saving the snapshot.
+	SnapshotInput(*domain.ResourceRef) error
 }
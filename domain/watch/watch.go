@@ -7,10 +7,21 @@ type TrainingInfo struct {
 	ProjectId  string
 	TrainingId string
 
+	// Flavor is the compute flavor which the training runs on.
+	Flavor domain.ComputeFlavor
+
+	// Status is the final status of training. It is set
+	// before the training is notified as done.
+	Status domain.TrainingStatus
+
 	domain.JobInfo
 }
 
 type WatchService interface {
 	WatchTraining(*TrainingInfo)
 	RegisterTrainingDone(func(*TrainingInfo))
+
+	// NotifyTrainingStatus notifies the status of training
+	// which has not been submitted and will not be watched.
+	NotifyTrainingStatus(*TrainingInfo, domain.TrainingStatus) error
 }
@@ -52,6 +52,8 @@ func genClient(c *Config, ao golangsdk.AuthOptionsProvider) (*golangsdk.Provider
 		Transport: &LogRoundTripper{
 			Rt:         transport,
 			MaxRetries: c.MaxRetries,
+			Log:        c.Log,
+			Config:     c.LogConfig,
 		},
 		CheckRedirect: func(req *http.Request, via []*http.Request) error {
 			if client.AKSKAuthOptions.AccessKey != "" {
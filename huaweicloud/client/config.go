@@ -8,6 +8,7 @@ import (
 	"time"
 
 	"github.com/chnsz/golangsdk"
+	"github.com/sirupsen/logrus"
 )
 
 type Config struct {
@@ -37,6 +38,12 @@ type Config struct {
 	// metadata security key expires at
 	SecurityKeyExpiresAt time.Time
 
+	// Log is the logger of the requests and responses.
+	Log *logrus.Entry
+
+	// LogConfig specifies what are redacted when logging.
+	LogConfig *LogConfig
+
 	HwClient     *golangsdk.ProviderClient
 	DomainClient *golangsdk.ProviderClient
 
@@ -0,0 +1,112 @@
+package client
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/chnsz/golangsdk"
+)
+
+const metadataSecurityKeyURL = "http://169.254.169.254/openstack/latest/securitykey"
+
+// Credential is the temporary credential got from STS.
+type Credential struct {
+	AccessKey     string
+	SecretKey     string
+	SecurityToken string
+	ExpiresAt     time.Time
+}
+
+type credentialResp struct {
+	Credential struct {
+		Access        string `json:"access"`
+		Secret        string `json:"secret"`
+		SecurityToken string `json:"securitytoken"`
+		ExpiresAt     string `json:"expires_at"`
+	} `json:"credential"`
+}
+
+func (r *credentialResp) toCredential() (c Credential, err error) {
+	v := &r.Credential
+	if v.Access == "" || v.Secret == "" || v.SecurityToken == "" {
+		err = errors.New("invalid temporary credential")
+
+		return
+	}
+
+	if c.ExpiresAt, err = time.Parse(time.RFC3339, v.ExpiresAt); err != nil {
+		return
+	}
+
+	c.AccessKey = v.Access
+	c.SecretKey = v.Secret
+	c.SecurityToken = v.SecurityToken
+
+	return
+}
+
+// GetCredentialFromMetadata returns the temporary credential of the agency
+// which is bound to the ECS where the process runs.
+func GetCredentialFromMetadata() (Credential, error) {
+	return getCredentialFromMetadata(metadataSecurityKeyURL)
+}
+
+func getCredentialFromMetadata(url string) (Credential, error) {
+	cli := http.Client{Timeout: 10 * time.Second}
+
+	resp, err := cli.Get(url)
+	if err != nil {
+		return Credential{}, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return Credential{}, fmt.Errorf(
+			"get security key from metadata failed, status code:%d", resp.StatusCode,
+		)
+	}
+
+	var v credentialResp
+	if err := json.NewDecoder(resp.Body).Decode(&v); err != nil {
+		return Credential{}, err
+	}
+
+	return v.toCredential()
+}
+
+// AssumeAgency returns the temporary credential of the agency which is
+// created by the domain. The client must be built by the AK/SK of user.
+func (c *Config) AssumeAgency(domainName, agencyName string, duration int) (Credential, error) {
+	body := map[string]interface{}{
+		"auth": map[string]interface{}{
+			"identity": map[string]interface{}{
+				"methods": []string{"assume_role"},
+				"assume_role": map[string]interface{}{
+					"domain_name":      domainName,
+					"agency_name":      agencyName,
+					"duration_seconds": duration,
+				},
+			},
+		},
+	}
+
+	// the identity endpoint likes https://iam.{Region}.myhuaweicloud.com:443/v3
+	url := strings.TrimSuffix(strings.TrimSuffix(c.IdentityEndpoint, "/"), "/v3") +
+		"/v3.0/OS-CREDENTIAL/securitytokens"
+
+	var v credentialResp
+	_, err := c.HwClient.Request("POST", url, &golangsdk.RequestOpts{
+		JSONBody:     body,
+		JSONResponse: &v,
+		OkCodes:      []int{201},
+	})
+	if err != nil {
+		return Credential{}, err
+	}
+
+	return v.toCredential()
+}
@@ -0,0 +1,159 @@
+package client
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/chnsz/golangsdk"
+)
+
+const testExpiresAt = "2026-10-19T12:00:00Z"
+
+func newTestCredentialResp(ak, sk, token, expiresAt string) map[string]interface{} {
+	return map[string]interface{}{
+		"credential": map[string]string{
+			"access":        ak,
+			"secret":        sk,
+			"securitytoken": token,
+			"expires_at":    expiresAt,
+		},
+	}
+}
+
+func TestGetCredentialFromMetadata(t *testing.T) {
+	cases := []struct {
+		name    string
+		code    int
+		body    interface{}
+		wantErr bool
+	}{
+		{
+			name: "ok",
+			code: http.StatusOK,
+			body: newTestCredentialResp("ak", "sk", "tk", testExpiresAt),
+		},
+		{
+			name:    "no agency is bound",
+			code:    http.StatusNotFound,
+			body:    map[string]string{},
+			wantErr: true,
+		},
+		{
+			name:    "missing security token",
+			code:    http.StatusOK,
+			body:    newTestCredentialResp("ak", "sk", "", testExpiresAt),
+			wantErr: true,
+		},
+		{
+			name:    "invalid expiry",
+			code:    http.StatusOK,
+			body:    newTestCredentialResp("ak", "sk", "tk", "tomorrow"),
+			wantErr: true,
+		},
+		{
+			name:    "invalid body",
+			code:    http.StatusOK,
+			body:    "credential",
+			wantErr: true,
+		},
+	}
+
+	for i := range cases {
+		c := &cases[i]
+
+		s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path != "/openstack/latest/securitykey" {
+				w.WriteHeader(http.StatusNotFound)
+
+				return
+			}
+
+			w.WriteHeader(c.code)
+			json.NewEncoder(w).Encode(c.body)
+		}))
+
+		v, err := getCredentialFromMetadata(s.URL + "/openstack/latest/securitykey")
+
+		s.Close()
+
+		if (err != nil) != c.wantErr {
+			t.Errorf("case %s: err = %v, want error %v", c.name, err, c.wantErr)
+
+			continue
+		}
+
+		if c.wantErr {
+			continue
+		}
+
+		want := Credential{
+			AccessKey:     "ak",
+			SecretKey:     "sk",
+			SecurityToken: "tk",
+			ExpiresAt:     time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC),
+		}
+
+		if !v.ExpiresAt.Equal(want.ExpiresAt) || v.AccessKey != want.AccessKey ||
+			v.SecretKey != want.SecretKey || v.SecurityToken != want.SecurityToken {
+			t.Errorf("case %s: credential = %+v, want %+v", c.name, v, want)
+		}
+	}
+}
+
+func TestAssumeAgency(t *testing.T) {
+	var req struct {
+		Auth struct {
+			Identity struct {
+				Methods    []string `json:"methods"`
+				AssumeRole struct {
+					DomainName string `json:"domain_name"`
+					AgencyName string `json:"agency_name"`
+					Duration   int    `json:"duration_seconds"`
+				} `json:"assume_role"`
+			} `json:"identity"`
+		} `json:"auth"`
+	}
+
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/v3.0/OS-CREDENTIAL/securitytokens" {
+			w.WriteHeader(http.StatusNotFound)
+
+			return
+		}
+
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(newTestCredentialResp("ak", "sk", "tk", testExpiresAt))
+	}))
+	defer s.Close()
+
+	cfg := Config{
+		IdentityEndpoint: s.URL + "/v3/",
+		HwClient:         &golangsdk.ProviderClient{},
+	}
+
+	v, err := cfg.AssumeAgency("domain", "agency", 900)
+	if err != nil {
+		t.Fatalf("assume agency failed, err:%v", err)
+	}
+
+	if v.SecurityToken != "tk" {
+		t.Errorf("credential = %+v, want the one of response", v)
+	}
+
+	r := &req.Auth.Identity
+	if len(r.Methods) != 1 || r.Methods[0] != "assume_role" ||
+		r.AssumeRole.DomainName != "domain" || r.AssumeRole.AgencyName != "agency" ||
+		r.AssumeRole.Duration != 900 {
+		t.Errorf("request = %+v, want assuming the agency", req)
+	}
+}
@@ -6,24 +6,106 @@ import (
 	"fmt"
 	"io"
 	"io/ioutil"
-	"log"
 	"math"
 	"net/http"
 	"sort"
 	"strings"
 	"time"
+	"unicode/utf8"
+
+	"github.com/sirupsen/logrus"
 )
 
 // MAXFieldLength is the maximum string length of single field when logging
 const MAXFieldLength int = 1024
 
+// defaultMaxBodySize is the maximum size of body when logging
+// if it is not configured.
+const defaultMaxBodySize = 4096
+
+const redactedValue = "***"
+
 var maxTimeout = 10 * time.Minute
 
+// defaultRedactedFields are the fields which are always redacted.
+// The env of training may include the secrets of user.
+var defaultRedactedFields = []string{"algorithm.environments.*"}
+
+// LogConfig specifies what are redacted when logging
+// the requests and responses.
+type LogConfig struct {
+	// RedactedHeaders are the names of headers whose values are redacted,
+	// besides the ones containing token or authorization. The names are
+	// case insensitive.
+	RedactedHeaders []string `json:"redacted_headers"`
+
+	// RedactedFields are the paths of fields of JSON body whose values are
+	// redacted, such as "metadata.desc". "*" matches any key of object, and
+	// the path applies to each item if the value on the path is an array.
+	// The fields containing password, secret, token and so on are always
+	// redacted.
+	RedactedFields []string `json:"redacted_fields"`
+
+	// MaxBodySize is the maximum size of body which is logged.
+	// The redundant part is truncated.
+	MaxBodySize int `json:"max_body_size"`
+}
+
+func (cfg *LogConfig) maxBodySize() int {
+	if cfg == nil || cfg.MaxBodySize <= 0 {
+		return defaultMaxBodySize
+	}
+
+	return cfg.MaxBodySize
+}
+
+func (cfg *LogConfig) redactedFields() [][]string {
+	v := defaultRedactedFields
+	if cfg != nil {
+		v = append(append([]string{}, v...), cfg.RedactedFields...)
+	}
+
+	r := make([][]string, len(v))
+	for i := range v {
+		r[i] = strings.Split(v[i], ".")
+	}
+
+	return r
+}
+
+// FormatHeaders processes a headers object plus a deliminator, returning a string
+// in which the headers of config are redacted too.
+func (cfg *LogConfig) FormatHeaders(headers http.Header, seperator string) string {
+	var v []string
+	if cfg != nil {
+		v = cfg.RedactedHeaders
+	}
+
+	redactedHeaders := redactHeaders(headers, v)
+	sort.Strings(redactedHeaders)
+
+	return strings.Join(redactedHeaders, seperator)
+}
+
 // LogRoundTripper satisfies the http.RoundTripper interface and is used to
 // customize the default http client RoundTripper to allow for logging.
 type LogRoundTripper struct {
 	Rt         http.RoundTripper
 	MaxRetries int
+
+	// Log is the logger of component. The standard logger is used if nil.
+	Log *logrus.Entry
+
+	// Config is the config of redaction. The default one is used if nil.
+	Config *LogConfig
+}
+
+func (lrt *LogRoundTripper) log() *logrus.Entry {
+	if lrt.Log == nil {
+		return logrus.NewEntry(logrus.StandardLogger())
+	}
+
+	return lrt.Log
 }
 
 func retryTimeout(count int) time.Duration {
@@ -48,8 +130,9 @@ func (lrt *LogRoundTripper) RoundTrip(request *http.Request) (*http.Response, er
 
 	var err error
 
-	log.Printf("[DEBUG] API Request URL: %s %s", request.Method, request.URL)
-	log.Printf("[DEBUG] API Request Headers:\n%s", FormatHeaders(request.Header, "\n"))
+	log := lrt.log()
+	log.Debugf("API Request URL: %s %s", request.Method, request.URL)
+	log.Debugf("API Request Headers:\n%s", lrt.Config.FormatHeaders(request.Header, "\n"))
 
 	if request.Body != nil {
 		request.Body, err = lrt.logRequest(request.Body, request.Header.Get("Content-Type"))
@@ -71,12 +154,12 @@ func (lrt *LogRoundTripper) RoundTrip(request *http.Request) (*http.Response, er
 	for response == nil {
 
 		if retry > lrt.MaxRetries {
-			log.Printf("[DEBUG] connection error, retries exhausted. Aborting")
+			log.Debug("connection error, retries exhausted. Aborting")
 			err = fmt.Errorf("connection error, retries exhausted. Aborting. Last error was: %s", err)
 			return nil, err
 		}
 
-		log.Printf("[DEBUG] connection error, retry number %d: %s", retry, err)
+		log.Debugf("connection error, retry number %d: %s", retry, err)
 
 		//lintignore:R018
 		time.Sleep(retryTimeout(retry))
@@ -84,8 +167,8 @@ func (lrt *LogRoundTripper) RoundTrip(request *http.Request) (*http.Response, er
 		retry++
 	}
 
-	log.Printf("[DEBUG] API Response Code: %d", response.StatusCode)
-	log.Printf("[DEBUG] API Response Headers:\n%s", FormatHeaders(response.Header, "\n"))
+	log.Debugf("API Response Code: %d", response.StatusCode)
+	log.Debugf("API Response Headers:\n%s", lrt.Config.FormatHeaders(response.Header, "\n"))
 
 	response.Body, err = lrt.logResponse(response.Body, response.Header.Get("Content-Type"))
 
@@ -105,10 +188,10 @@ func (lrt *LogRoundTripper) logRequest(original io.ReadCloser, contentType strin
 
 	// Handle request contentType
 	if strings.HasPrefix(contentType, "application/json") {
-		debugInfo := formatJSON(bs.Bytes(), true)
-		log.Printf("[DEBUG] API Request Body: %s", debugInfo)
+		debugInfo := lrt.formatJSON(bs.Bytes(), true)
+		lrt.log().Debugf("API Request Body: %s", debugInfo)
 	} else {
-		log.Printf("[DEBUG] Not logging because the request body isn't JSON")
+		lrt.log().Debug("Not logging because the request body isn't JSON")
 	}
 
 	return ioutil.NopCloser(strings.NewReader(bs.String())), nil
@@ -124,20 +207,48 @@ func (lrt *LogRoundTripper) logResponse(original io.ReadCloser, contentType stri
 		if err != nil {
 			return nil, err
 		}
-		debugInfo := formatJSON(bs.Bytes(), true)
+		debugInfo := lrt.formatJSON(bs.Bytes(), true)
 		if debugInfo != "" {
-			log.Printf("[DEBUG] API Response Body: %s", debugInfo)
+			lrt.log().Debugf("API Response Body: %s", debugInfo)
 		}
 		return ioutil.NopCloser(strings.NewReader(bs.String())), nil
 	}
 
-	log.Printf("[DEBUG] Not logging because the response body isn't JSON")
+	lrt.log().Debug("Not logging because the response body isn't JSON")
 	return original, nil
 }
 
-// formatJSON will try to pretty-format a JSON body.
+// formatJSON will try to pretty-format a JSON body and truncate it
+// to the max body size.
+func (lrt *LogRoundTripper) formatJSON(raw []byte, maskBody bool) string {
+	s := lrt.doFormatJSON(raw, maskBody)
+
+	if max := lrt.Config.maxBodySize(); len(s) > max {
+		v := truncate(s, max)
+
+		return fmt.Sprintf("%s ** truncated %d bytes **", v, len(s)-len(v))
+	}
+
+	return s
+}
+
+// truncate returns the longest prefix of s which is at most max bytes
+// and doesn't split a UTF-8 encoded rune.
+func truncate(s string, max int) string {
+	if len(s) <= max {
+		return s
+	}
+
+	for max > 0 && !utf8.RuneStart(s[max]) {
+		max--
+	}
+
+	return s[:max]
+}
+
+// doFormatJSON will try to pretty-format a JSON body.
 // It will also mask known fields which contain sensitive information.
-func formatJSON(raw []byte, maskBody bool) string {
+func (lrt *LogRoundTripper) doFormatJSON(raw []byte, maskBody bool) string {
 	var data map[string]interface{}
 
 	if len(raw) == 0 {
@@ -146,13 +257,23 @@ func formatJSON(raw []byte, maskBody bool) string {
 
 	err := json.Unmarshal(raw, &data)
 	if err != nil {
-		log.Printf("[DEBUG] Unable to parse JSON: %s", err)
+		lrt.log().Debugf("Unable to parse JSON: %s", err)
+
+		// the body may include sensitive information.
+		if maskBody {
+			return "{ **unparsable** }"
+		}
+
 		return string(raw)
 	}
 
 	// Mask known password fields
 	if maskBody {
 		maskSecurityFields(data)
+
+		for _, path := range lrt.Config.redactedFields() {
+			redactField(data, path)
+		}
 	}
 
 	// Ignore the catalog
@@ -167,7 +288,7 @@ func formatJSON(raw []byte, maskBody bool) string {
 
 	pretty, err := json.MarshalIndent(data, "", "  ")
 	if err != nil {
-		log.Printf("[DEBUG] Unable to re-marshal JSON: %s", err)
+		lrt.log().Debugf("Unable to re-marshal JSON: %s", err)
 		return string(raw)
 	}
 
@@ -176,13 +297,19 @@ func formatJSON(raw []byte, maskBody bool) string {
 
 // RedactHeaders processes a headers object, returning a redacted list.
 func RedactHeaders(headers http.Header) (processedHeaders []string) {
+	return redactHeaders(headers, nil)
+}
+
+// redactHeaders redacts the headers whose names are one of the names too.
+func redactHeaders(headers http.Header, names []string) (processedHeaders []string) {
 	// sensitiveWords is a list of headers that need to be redacted.
 	var sensitiveWords = []string{"token", "authorization"}
 
 	for name, header := range headers {
 		for _, v := range header {
-			if IsStrContainsSliceElement(name, sensitiveWords, true, false) {
-				processedHeaders = append(processedHeaders, fmt.Sprintf("%v: %v", name, "***"))
+			if IsStrContainsSliceElement(name, sensitiveWords, true, false) ||
+				IsStrContainsSliceElement(name, names, true, true) {
+				processedHeaders = append(processedHeaders, fmt.Sprintf("%v: %v", name, redactedValue))
 			} else {
 				processedHeaders = append(processedHeaders, fmt.Sprintf("%v: %v", name, v))
 			}
@@ -193,10 +320,38 @@ func RedactHeaders(headers http.Header) (processedHeaders []string) {
 
 // FormatHeaders processes a headers object plus a deliminator, returning a string
 func FormatHeaders(headers http.Header, seperator string) string {
-	redactedHeaders := RedactHeaders(headers)
-	sort.Strings(redactedHeaders)
+	var cfg *LogConfig
 
-	return strings.Join(redactedHeaders, seperator)
+	return cfg.FormatHeaders(headers, seperator)
+}
+
+// redactField redacts the value on the path of data.
+func redactField(data interface{}, path []string) {
+	if len(path) == 0 {
+		return
+	}
+
+	switch v := data.(type) {
+	case []interface{}:
+		for i := range v {
+			redactField(v[i], path)
+		}
+
+	case map[string]interface{}:
+		k, last := path[0], len(path) == 1
+
+		for key, val := range v {
+			if k != "*" && k != key {
+				continue
+			}
+
+			if last {
+				v[key] = redactedValue
+			} else {
+				redactField(val, path[1:])
+			}
+		}
+	}
 }
 
 func maskSecurityFields(data map[string]interface{}) bool {
@@ -204,7 +359,7 @@ func maskSecurityFields(data map[string]interface{}) bool {
 		switch val := val.(type) {
 		case string:
 			if isSecurityFields(k) {
-				data[k] = "***"
+				data[k] = redactedValue
 			} else if len(val) > MAXFieldLength {
 				data[k] = "** large string **"
 			}
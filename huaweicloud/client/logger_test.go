@@ -0,0 +1,138 @@
+package client
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/sirupsen/logrus"
+)
+
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(r *http.Request) (*http.Response, error) {
+	return f(r)
+}
+
+func TestTruncate(t *testing.T) {
+	cases := []struct {
+		s    string
+		max  int
+		want string
+	}{
+		{"abc", 5, "abc"},
+		{"abcdef", 3, "abc"},
+		{"中文", 3, "中"},
+		{"中文", 4, "中"},
+		{"中文", 5, "中"},
+		{"中文", 6, "中文"},
+		{"中文", 2, ""},
+		{"a中", 2, "a"},
+	}
+
+	for _, c := range cases {
+		v := truncate(c.s, c.max)
+		if v != c.want {
+			t.Errorf("truncate(%q, %d) = %q, want %q", c.s, c.max, v, c.want)
+		}
+
+		if !utf8.ValidString(v) {
+			t.Errorf("truncate(%q, %d) = %q, it is not valid UTF-8", c.s, c.max, v)
+		}
+	}
+}
+
+func TestFormatJSONTruncatesOnRuneBoundary(t *testing.T) {
+	lrt := LogRoundTripper{Config: &LogConfig{MaxBodySize: 16}}
+
+	v := lrt.formatJSON([]byte(`{"desc": "训练任务描述信息"}`), false)
+
+	if !utf8.ValidString(v) {
+		t.Errorf("the truncated body is not valid UTF-8: %q", v)
+	}
+
+	if !strings.Contains(v, "** truncated") {
+		t.Errorf("the body is not truncated: %s", v)
+	}
+}
+
+// TestSecretsAreNotLogged checks that the secrets in the headers and
+// body of request and response never reach the log.
+func TestSecretsAreNotLogged(t *testing.T) {
+	const (
+		envSecret   = "env-secret-value"
+		fieldSecret = "field-secret-value"
+		authValue   = "auth-value"
+		tokenValue  = "token-value"
+		custom      = "custom-header-value"
+	)
+
+	buf := new(bytes.Buffer)
+
+	logger := logrus.New()
+	logger.SetOutput(buf)
+	logger.SetLevel(logrus.DebugLevel)
+
+	lrt := LogRoundTripper{
+		Log: logrus.NewEntry(logger),
+		Config: &LogConfig{
+			RedactedHeaders: []string{"X-Custom"},
+			RedactedFields:  []string{"metadata.desc"},
+		},
+		Rt: roundTripFunc(func(r *http.Request) (*http.Response, error) {
+			h := http.Header{}
+			h.Set("Content-Type", "application/json")
+			h.Set("X-Subject-Token", tokenValue)
+
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Header:     h,
+				Body: ioutil.NopCloser(strings.NewReader(
+					`{"credential": {"access": "ak", "secret": "` + fieldSecret +
+						`", "securitytoken": "` + tokenValue + `"}}`,
+				)),
+			}, nil
+		}),
+	}
+
+	body := `{
+		"metadata": {"name": "job", "desc": "` + fieldSecret + `"},
+		"algorithm": {"environments": {"TOKEN": "` + envSecret + `", "X": "` + envSecret + `"}},
+		"auth": {"password": "` + fieldSecret + `"}
+	}`
+
+	req, err := http.NewRequest(http.MethodPost, "https://example.com/v2/jobs", strings.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", authValue)
+	req.Header.Set("X-Auth-Token", tokenValue)
+	req.Header.Set("X-Custom", custom)
+
+	resp, err := lrt.RoundTrip(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the body of response is still readable after logged.
+	if v, _ := ioutil.ReadAll(resp.Body); !strings.Contains(string(v), fieldSecret) {
+		t.Errorf("the body of response is changed: %s", v)
+	}
+
+	out := buf.String()
+
+	for _, v := range []string{envSecret, fieldSecret, authValue, tokenValue, custom} {
+		if strings.Contains(out, v) {
+			t.Errorf("%s is logged:\n%s", v, out)
+		}
+	}
+
+	if !strings.Contains(out, "API Request Body") || !strings.Contains(out, "job") {
+		t.Errorf("the request is not logged:\n%s", out)
+	}
+}
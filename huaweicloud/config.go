@@ -1,12 +1,20 @@
 package main
 
 import (
+	"errors"
+
 	"github.com/opensourceways/community-robot-lib/utils"
+	"github.com/sirupsen/logrus"
 
+	"github.com/opensourceways/xihe-training-center/app"
+	"github.com/opensourceways/xihe-training-center/auth"
 	"github.com/opensourceways/xihe-training-center/domain"
 	"github.com/opensourceways/xihe-training-center/huaweicloud/trainingimpl"
 	"github.com/opensourceways/xihe-training-center/infrastructure/mysql"
 	"github.com/opensourceways/xihe-training-center/infrastructure/platformimpl"
+	"github.com/opensourceways/xihe-training-center/infrastructure/postgresql"
+	"github.com/opensourceways/xihe-training-center/infrastructure/secretimpl"
+	"github.com/opensourceways/xihe-training-center/infrastructure/sqlite"
 	"github.com/opensourceways/xihe-training-center/infrastructure/watchimpl"
 )
 
@@ -23,21 +31,71 @@ type configuration struct {
 	// which the training center can support
 	MaxTrainingNum int `json:"max_training_num"`
 
+	// ConfigReloadInterval specifies the interval of second between two
+	// checks of whether the config file is changed. Only max_training_num,
+	// the interval and stall timeout of watch, domain and the AK/SK of
+	// modelarts and obs can be changed at runtime.
+	ConfigReloadInterval int `json:"config_reload_interval"`
+
 	Train  trainingimpl.Config `json:"train"     required:"true"`
 	Watch  watchimpl.Config    `json:"watch"     required:"true"`
-	Mysql  mysql.Config        `json:"mysql"     required:"true"`
-	Gitlab platformimpl.Config `json:"gitlab"    required:"true"`
 	Domain domain.Config       `json:"domain"`
+
+	// Platform is the config of code platform which
+	// can be gitlab, gitea, github or git server.
+	Platform platformimpl.Config `json:"platform"  required:"true"`
+
+	// Gitlab is the deprecated name of Platform. It is used
+	// only if Platform is not set.
+	Gitlab *platformimpl.Config `json:"gitlab,omitempty"`
+
+	// DBDriver specifies the database which saves the sync lock.
+	// It is one of mysql, postgresql and sqlite, and mysql by default.
+	// The config of the selected database must be set.
+	DBDriver   string             `json:"db_driver"`
+	Mysql      *mysql.Config      `json:"mysql"`
+	Postgresql *postgresql.Config `json:"postgresql"`
+	Sqlite     *sqlite.Config     `json:"sqlite"`
+
+	Waiting  app.WaitingConfig  `json:"wait_for_inputs"`
+	SyncLock app.SyncLockConfig `json:"sync_lock"`
+	Compute  app.ComputeConfig  `json:"compute"`
+	Health   app.HealthConfig   `json:"health"`
+
+	// Secret is the config of the secrets of users which are saved in the
+	// database above. The secrets can't be used if it is not set.
+	Secret *secretimpl.Config `json:"secret"`
+
+	// Auth is the config of authenticating the callers of apis.
+	// It must be set unless AuthDisabled is true.
+	Auth *auth.Config `json:"auth"`
+
+	// AuthDisabled allows running without Auth, and then all the
+	// apis are not authenticated. It can't be true if Auth is set.
+	AuthDisabled bool `json:"auth_disabled"`
 }
 
 func (cfg *configuration) configItems() []interface{} {
-	return []interface{}{
+	items := []interface{}{
 		&cfg.Watch,
-		&cfg.Mysql,
-		&cfg.Gitlab,
+		&cfg.Platform,
 		&cfg.Domain,
 		&cfg.Train,
+		&cfg.Waiting,
+		&cfg.SyncLock,
+		&cfg.Compute,
+		&cfg.Health,
+	}
+
+	if cfg.Secret != nil {
+		items = append(items, cfg.Secret)
 	}
+
+	if cfg.Auth != nil {
+		items = append(items, cfg.Auth)
+	}
+
+	return append(items, cfg.dbConfigItems()...)
 }
 
 func (cfg *configuration) validate() error {
@@ -45,6 +103,14 @@ func (cfg *configuration) validate() error {
 		return err
 	}
 
+	if err := cfg.validateDB(); err != nil {
+		return err
+	}
+
+	if err := cfg.validateAuth(); err != nil {
+		return err
+	}
+
 	items := cfg.configItems()
 
 	for _, i := range items {
@@ -58,7 +124,38 @@ func (cfg *configuration) validate() error {
 	return nil
 }
 
+func (cfg *configuration) validateAuth() error {
+	if cfg.Auth == nil && !cfg.AuthDisabled {
+		return errors.New("missing auth, set auth_disabled to run without it")
+	}
+
+	if cfg.Auth != nil && cfg.AuthDisabled {
+		return errors.New("auth_disabled can't be set with auth")
+	}
+
+	return nil
+}
+
+// applyDeprecated moves the values of deprecated fields to the new ones.
+func (cfg *configuration) applyDeprecated() {
+	if cfg.Gitlab == nil {
+		return
+	}
+
+	if cfg.Platform == (platformimpl.Config{}) {
+		cfg.Platform = *cfg.Gitlab
+
+		logrus.Warn("the config of gitlab is deprecated, use platform instead")
+	} else {
+		logrus.Warn("the config of gitlab is ignored, because platform is set")
+	}
+
+	cfg.Gitlab = nil
+}
+
 func (cfg *configuration) setDefault() {
+	cfg.applyDeprecated()
+
 	items := cfg.configItems()
 
 	for _, i := range items {
@@ -70,6 +167,14 @@ func (cfg *configuration) setDefault() {
 	if cfg.MaxTrainingNum <= 0 {
 		cfg.MaxTrainingNum = 100
 	}
+
+	if cfg.ConfigReloadInterval <= 0 {
+		cfg.ConfigReloadInterval = 10
+	}
+
+	if cfg.DBDriver == "" {
+		cfg.DBDriver = dbDriverMysql
+	}
 }
 
 func loadConfig(path string) (*configuration, error) {
@@ -0,0 +1,118 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/opensourceways/xihe-training-center/infrastructure/jobownerimpl"
+	"github.com/opensourceways/xihe-training-center/infrastructure/mysql"
+	"github.com/opensourceways/xihe-training-center/infrastructure/postgresql"
+	"github.com/opensourceways/xihe-training-center/infrastructure/secretimpl"
+	"github.com/opensourceways/xihe-training-center/infrastructure/sqlite"
+	"github.com/opensourceways/xihe-training-center/infrastructure/synclockimpl"
+)
+
+const (
+	dbDriverMysql      = "mysql"
+	dbDriverSqlite     = "sqlite"
+	dbDriverPostgresql = "postgresql"
+)
+
+// database is the database selected by the db driver.
+type database struct {
+	autoMigrate bool
+	migrate     func() ([]string, error)
+	newMapper   func() synclockimpl.SyncLockMapper
+	ping        func(context.Context) error
+
+	newSecretMapper   func() secretimpl.SecretMapper
+	newJobOwnerMapper func() jobownerimpl.JobOwnerMapper
+}
+
+func (cfg *configuration) validateDB() error {
+	missing := false
+
+	switch cfg.DBDriver {
+	case dbDriverMysql:
+		missing = cfg.Mysql == nil
+
+	case dbDriverPostgresql:
+		missing = cfg.Postgresql == nil
+
+	case dbDriverSqlite:
+		missing = cfg.Sqlite == nil
+
+	default:
+		return fmt.Errorf("unknown db driver: %s", cfg.DBDriver)
+	}
+
+	if missing {
+		return fmt.Errorf("missing the config of db driver: %s", cfg.DBDriver)
+	}
+
+	return nil
+}
+
+// dbConfigItems returns the configs of databases which are set.
+func (cfg *configuration) dbConfigItems() []interface{} {
+	var r []interface{}
+
+	if cfg.Mysql != nil {
+		r = append(r, cfg.Mysql)
+	}
+
+	if cfg.Postgresql != nil {
+		r = append(r, cfg.Postgresql)
+	}
+
+	if cfg.Sqlite != nil {
+		r = append(r, cfg.Sqlite)
+	}
+
+	return r
+}
+
+func initDB(cfg *configuration) (db database, err error) {
+	switch cfg.DBDriver {
+	case dbDriverMysql:
+		err = mysql.Init(cfg.Mysql)
+		db = database{
+			autoMigrate: cfg.Mysql.AutoMigrate,
+			migrate:     mysql.Migrate,
+			newMapper:   mysql.NewSyncLockMapper,
+			ping:        mysql.Ping,
+
+			newSecretMapper:   mysql.NewSecretMapper,
+			newJobOwnerMapper: mysql.NewJobOwnerMapper,
+		}
+
+	case dbDriverPostgresql:
+		err = postgresql.Init(cfg.Postgresql)
+		db = database{
+			autoMigrate: cfg.Postgresql.AutoMigrate,
+			migrate:     postgresql.Migrate,
+			newMapper:   postgresql.NewSyncLockMapper,
+			ping:        postgresql.Ping,
+
+			newSecretMapper:   postgresql.NewSecretMapper,
+			newJobOwnerMapper: postgresql.NewJobOwnerMapper,
+		}
+
+	case dbDriverSqlite:
+		err = sqlite.Init(cfg.Sqlite)
+		db = database{
+			autoMigrate: cfg.Sqlite.AutoMigrate,
+			migrate:     sqlite.Migrate,
+			newMapper:   sqlite.NewSyncLockMapper,
+			ping:        sqlite.Ping,
+
+			newSecretMapper:   sqlite.NewSecretMapper,
+			newJobOwnerMapper: sqlite.NewJobOwnerMapper,
+		}
+
+	default:
+		err = fmt.Errorf("unknown db driver: %s", cfg.DBDriver)
+	}
+
+	return
+}
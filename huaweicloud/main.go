@@ -9,12 +9,16 @@ import (
 	"github.com/sirupsen/logrus"
 
 	"github.com/opensourceways/xihe-training-center/app"
+	"github.com/opensourceways/xihe-training-center/auth"
 	"github.com/opensourceways/xihe-training-center/controller"
 	"github.com/opensourceways/xihe-training-center/docs"
 	"github.com/opensourceways/xihe-training-center/domain"
+	"github.com/opensourceways/xihe-training-center/domain/compute"
+	"github.com/opensourceways/xihe-training-center/domain/secret"
 	"github.com/opensourceways/xihe-training-center/huaweicloud/trainingimpl"
-	"github.com/opensourceways/xihe-training-center/infrastructure/mysql"
+	"github.com/opensourceways/xihe-training-center/infrastructure/jobownerimpl"
 	"github.com/opensourceways/xihe-training-center/infrastructure/platformimpl"
+	"github.com/opensourceways/xihe-training-center/infrastructure/secretimpl"
 	"github.com/opensourceways/xihe-training-center/infrastructure/synclockimpl"
 	"github.com/opensourceways/xihe-training-center/infrastructure/watchimpl"
 	"github.com/opensourceways/xihe-training-center/server"
@@ -47,6 +51,12 @@ func main() {
 	logrusutil.ComponentInit("xihe-training-center")
 	log := logrus.NewEntry(logrus.StandardLogger())
 
+	if len(os.Args) > 1 && os.Args[1] == cmdMigrate {
+		runMigrate(os.Args[0], os.Args[2:])
+
+		return
+	}
+
 	o := gatherOptions(
 		flag.NewFlagSet(os.Args[0], flag.ExitOnError),
 		os.Args[1:]...,
@@ -72,41 +82,138 @@ func main() {
 	// controller
 	controller.Init(log)
 
-	// gitlab
-	p, err := platformimpl.NewPlatform(&cfg.Gitlab)
+	// code platform
+	p, err := platformimpl.NewPlatform(&cfg.Platform)
 	if err != nil {
-		logrus.Fatalf("init gitlab failed, err:%s", err.Error())
+		logrus.Fatalf("init %s failed, err:%s", cfg.Platform.Type, err.Error())
 	}
 
 	// sync lock
-	if err := mysql.Init(&cfg.Mysql); err != nil {
-		logrus.Fatalf("init gitlab failed, err:%s", err.Error())
+	db, err := initDB(cfg)
+	if err != nil {
+		logrus.Fatalf("init %s failed, err:%s", cfg.DBDriver, err.Error())
 	}
 
-	lock := synclockimpl.NewRepoSyncLock(mysql.NewSyncLockMapper())
+	if db.autoMigrate {
+		migrate(&db)
+	}
+
+	lock := synclockimpl.NewRepoSyncLock(db.newMapper())
+
+	// secret
+	var ss secret.Secret
+	if cfg.Secret != nil {
+		if ss, err = secretimpl.NewSecret(db.newSecretMapper(), cfg.Secret); err != nil {
+			logrus.Fatalf("new secret, err:%s", err.Error())
+		}
+	}
+
+	// job owner
+	jo := jobownerimpl.NewJobOwner(db.newJobOwnerMapper())
 
 	// training
-	ts, err := trainingimpl.NewTraining(&cfg.Train)
+	modelartsLog := log.WithField("component", "modelarts")
+
+	creds, err := trainingimpl.NewCredentials(&cfg.Train, modelartsLog)
+	if err != nil {
+		logrus.Fatalf("new credentials, err:%s", err.Error())
+	}
+
+	ts, err := trainingimpl.NewTraining(&cfg.Train, creds, modelartsLog)
 	if err != nil {
 		logrus.Fatalf("new training center, err:%s", err.Error())
 	}
 
 	// watch
-	ws, err := watchimpl.NewWatcher(&cfg.Watch, ts, cfg.MaxTrainingNum, log)
+	ws, err := watchimpl.NewWatcher(&cfg.Watch, ts, log)
 	if err != nil {
 		log.Errorf("new watch service failed, err:%s", err.Error())
 	}
 
-	service := app.NewTrainingService(ts, p, ws, log, lock, cfg.MaxTrainingNum)
+	// compute
+	var c compute.Compute
+	if cfg.Compute.RefreshInterval > 0 {
+		if c, err = trainingimpl.NewCompute(&cfg.Train, creds, modelartsLog); err != nil {
+			logrus.Fatalf("new compute, err:%s", err.Error())
+		}
+	}
+
+	cs := app.NewComputeService(c, &cfg.Compute, log)
+
+	ps := app.NewProjectService(ts, p, log, lock, &cfg.SyncLock)
+
+	service := app.NewTrainingService(
+		ts, p, ps, ws, ss, jo, log, cfg.MaxTrainingNum, &cfg.Waiting,
+	)
+
+	// health
+	modelartsCheck, err := trainingimpl.NewHealthCheck(&cfg.Train, creds, modelartsLog)
+	if err != nil {
+		logrus.Fatalf("new health check of modelarts, err:%s", err.Error())
+	}
+
+	hs := app.NewHealthService(
+		[]app.HealthCheck{
+			{Name: "watcher", Check: ws.CheckAlive},
+		},
+		[]app.HealthCheck{
+			{Name: cfg.DBDriver, Check: db.ping},
+			{Name: cfg.Platform.Type, Check: platformimpl.NewHealthCheck(&cfg.Platform)},
+			{Name: "modelarts", Check: modelartsCheck},
+			{Name: "xihe-server", Check: ws.CheckConnection},
+		},
+		&cfg.Health,
+	)
 
 	go ws.Run()
 
 	defer ws.Exit()
 
+	// it must exit before the watcher which notifies the status.
+	defer service.Exit()
+
+	// config
+	reloader, err := newConfigReloader(
+		o.service.ConfigFile, cfg, log,
+		func(v *configuration) error {
+			// the credentials may fail to be updated,
+			// so they are applied before the others.
+			if err := creds.Update(&v.Train); err != nil {
+				return err
+			}
+
+			domain.Init(&v.Domain)
+			service.SetMaxTrainingNum(v.MaxTrainingNum)
+			ws.UpdateConfig(&v.Watch)
+
+			return nil
+		},
+	)
+	if err != nil {
+		logrus.Fatalf("new config reloader, err:%s", err.Error())
+	}
+
+	go reloader.run()
+
+	// auth
+	var authenticator auth.Authenticator
+	if cfg.Auth != nil {
+		authenticator = auth.NewAuthenticator(cfg.Auth)
+	} else {
+		log.Warn("the auth is disabled, all the apis are not authenticated")
+	}
+
 	server.StartWebServer(docs.SwaggerInfo, &server.Service{
 		Port:     o.service.Port,
 		Timeout:  o.service.GracePeriod,
 		Log:      log,
+		Auth:     authenticator,
 		Training: service,
+		Compute:  cs,
+		Project:  ps,
+		SyncLock: app.NewSyncLockService(lock, log),
+		Secret:   app.NewSecretService(ss),
+		Config:   reloader,
+		Health:   hs,
 	})
 }
@@ -0,0 +1,42 @@
+package main
+
+import (
+	"flag"
+
+	"github.com/sirupsen/logrus"
+)
+
+const cmdMigrate = "migrate"
+
+// runMigrate applies the migrations of tables and exits.
+// It is run as: xihe-training-center migrate --config-file=...
+func runMigrate(name string, args []string) {
+	o := gatherOptions(flag.NewFlagSet(name+" "+cmdMigrate, flag.ExitOnError), args...)
+	if err := o.Validate(); err != nil {
+		logrus.Fatalf("Invalid options, err:%s", err.Error())
+	}
+
+	cfg, err := loadConfig(o.service.ConfigFile)
+	if err != nil {
+		logrus.Fatalf("load config, err:%s", err.Error())
+	}
+
+	db, err := initDB(cfg)
+	if err != nil {
+		logrus.Fatalf("init %s failed, err:%s", cfg.DBDriver, err.Error())
+	}
+
+	migrate(&db)
+}
+
+func migrate(db *database) {
+	v, err := db.migrate()
+
+	for _, name := range v {
+		logrus.Infof("applied migration: %s", name)
+	}
+
+	if err != nil {
+		logrus.Fatalf("migrate tables failed, err:%s", err.Error())
+	}
+}
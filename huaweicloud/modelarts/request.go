@@ -1,6 +1,14 @@
 package modelarts
 
-import "github.com/chnsz/golangsdk"
+import (
+	"time"
+
+	"github.com/chnsz/golangsdk"
+
+	"github.com/opensourceways/xihe-training-center/metrics"
+)
+
+const serviceName = "modelarts"
 
 type JobCreateOption struct {
 	Kind      string          `json:"kind" required:"true"`
@@ -20,7 +28,10 @@ type MetadataOption struct {
 
 type AlgorithmOption struct {
 	CodeDir      string              `json:"code_dir"`
-	BootFile     string              `json:"boot_file"`
+	BootFile     string              `json:"boot_file,omitempty"`
+	Command      string              `json:"command,omitempty"`
+	LocalCodeDir string              `json:"local_code_dir,omitempty"`
+	WorkingDir   string              `json:"working_dir,omitempty"`
 	Engine       EngineOption        `json:"engine"`
 	Parameters   []ParameterOption   `json:"parameters"`
 	Environments map[string]string   `json:"environments"`
@@ -29,8 +40,10 @@ type AlgorithmOption struct {
 }
 
 type EngineOption struct {
-	EngineName    string `json:"engine_name"`
-	EngineVersion string `json:"engine_version"`
+	EngineName    string `json:"engine_name,omitempty"`
+	EngineVersion string `json:"engine_version,omitempty"`
+	ImageURL      string `json:"image_url,omitempty"`
+	RunUser       string `json:"run_user,omitempty"`
 }
 
 type ParameterOption struct {
@@ -65,7 +78,9 @@ type LogExportPathOption struct {
 	OBSURL string `json:"obs_url,omitempty"`
 }
 
-func CreateJob(client *golangsdk.ServiceClient, opts JobCreateOption) (string, error) {
+func CreateJob(client *golangsdk.ServiceClient, opts JobCreateOption) (_ string, err error) {
+	defer metrics.ObserveCall(serviceName, "create_job", time.Now(), &err)
+
 	reqBody, err := opts.toMap()
 	if err != nil {
 		return "", err
@@ -85,7 +100,9 @@ func CreateJob(client *golangsdk.ServiceClient, opts JobCreateOption) (string, e
 	return job.Metadata.Id, nil
 }
 
-func DeleteJob(client *golangsdk.ServiceClient, jobId string) error {
+func DeleteJob(client *golangsdk.ServiceClient, jobId string) (err error) {
+	defer metrics.ObserveCall(serviceName, "delete_job", time.Now(), &err)
+
 	v, err := client.Delete(
 		jobURL(client, jobId),
 		&golangsdk.RequestOpts{OkCodes: []int{202}},
@@ -98,8 +115,10 @@ func DeleteJob(client *golangsdk.ServiceClient, jobId string) error {
 	return err
 }
 
-func TerminateJob(client *golangsdk.ServiceClient, jobId string) error {
-	_, err := client.Post(
+func TerminateJob(client *golangsdk.ServiceClient, jobId string) (err error) {
+	defer metrics.ObserveCall(serviceName, "terminate_job", time.Now(), &err)
+
+	_, err = client.Post(
 		actionURL(client, jobId),
 		map[string]interface{}{
 			"action_type": "terminate",
@@ -111,6 +130,8 @@ func TerminateJob(client *golangsdk.ServiceClient, jobId string) error {
 }
 
 func GetJob(client *golangsdk.ServiceClient, jobId string) (j Job, err error) {
+	defer metrics.ObserveCall(serviceName, "get_job", time.Now(), &err)
+
 	r := golangsdk.Result{}
 	_, r.Err = client.Get(
 		jobURL(client, jobId), &r.Body,
@@ -122,7 +143,9 @@ func GetJob(client *golangsdk.ServiceClient, jobId string) (j Job, err error) {
 	return j, err
 }
 
-func GetLogDownloadURL(client *golangsdk.ServiceClient, jobId string) (string, error) {
+func GetLogDownloadURL(client *golangsdk.ServiceClient, jobId string) (_ string, err error) {
+	defer metrics.ObserveCall(serviceName, "get_log_url", time.Now(), &err)
+
 	r := golangsdk.Result{}
 	_, r.Err = client.Get(
 		logURL(client, jobId), &r.Body,
@@ -137,7 +160,41 @@ func GetLogDownloadURL(client *golangsdk.ServiceClient, jobId string) (string, e
 	var v struct {
 		OBSURL string `json:"obs_url"`
 	}
-	err := r.ExtractInto(&v)
+	err = r.ExtractInto(&v)
 
 	return v.OBSURL, err
 }
+
+func ListEngines(client *golangsdk.ServiceClient) (_ []Engine, err error) {
+	defer metrics.ObserveCall(serviceName, "list_engines", time.Now(), &err)
+
+	r := golangsdk.Result{}
+	_, r.Err = client.Get(
+		enginesURL(client), &r.Body,
+		&golangsdk.RequestOpts{OkCodes: []int{200}},
+	)
+
+	var v struct {
+		Items []Engine `json:"items"`
+	}
+	err = r.ExtractInto(&v)
+
+	return v.Items, err
+}
+
+func ListFlavors(client *golangsdk.ServiceClient) (_ []Flavor, err error) {
+	defer metrics.ObserveCall(serviceName, "list_flavors", time.Now(), &err)
+
+	r := golangsdk.Result{}
+	_, r.Err = client.Get(
+		flavorsURL(client), &r.Body,
+		&golangsdk.RequestOpts{OkCodes: []int{200}},
+	)
+
+	var v struct {
+		Flavors []Flavor `json:"flavors"`
+	}
+	err = r.ExtractInto(&v)
+
+	return v.Flavors, err
+}
@@ -14,3 +14,15 @@ type JobStatus struct {
 	Duration  int    `json:"duration"`
 	StartTime int    `json:"start_time"`
 }
+
+type Engine struct {
+	EngineId      string `json:"engine_id"`
+	EngineName    string `json:"engine_name"`
+	EngineVersion string `json:"engine_version"`
+}
+
+type Flavor struct {
+	FlavorId   string `json:"flavor_id"`
+	FlavorName string `json:"flavor_name"`
+	FlavorType string `json:"flavor_type"`
+}
@@ -2,7 +2,11 @@ package modelarts
 
 import "github.com/chnsz/golangsdk"
 
-const base = "training-jobs"
+const (
+	base        = "training-jobs"
+	engineBase  = "training-job-engines"
+	flavorsBase = "training-job-flavors"
+)
 
 func createURL(sc *golangsdk.ServiceClient) string {
 	return sc.ServiceURL(base)
@@ -19,3 +23,11 @@ func actionURL(sc *golangsdk.ServiceClient, jobId string) string {
 func logURL(sc *golangsdk.ServiceClient, jobId string) string {
 	return sc.ServiceURL(base, jobId, "tasks/worker-0/logs/url")
 }
+
+func enginesURL(sc *golangsdk.ServiceClient) string {
+	return sc.ServiceURL(engineBase)
+}
+
+func flavorsURL(sc *golangsdk.ServiceClient) string {
+	return sc.ServiceURL(flavorsBase)
+}
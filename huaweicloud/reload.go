@@ -0,0 +1,362 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/opensourceways/community-robot-lib/utils"
+	"github.com/sirupsen/logrus"
+)
+
+const maskedValue = "***"
+
+// reloadableFields are the fields of config which can be changed at
+// runtime. The field ending with "." stands for all the fields under it.
+var reloadableFields = []string{
+	"max_training_num",
+	"config_reload_interval",
+	"watch.interval",
+	"watch.stall_timeout",
+	"domain.",
+	"train.modelarts.access_key",
+	"train.modelarts.secret_key",
+	"train.obs.access_key",
+	"train.obs.secret_key",
+}
+
+// sensitiveFields are the fields whose values are masked. Besides them,
+// the userinfo of every url is masked, such as the one of index_url.
+var sensitiveFields = []string{
+	"conn", "token", "tokens", "password", "secret",
+	"access_key", "secret_key", "encryption_key",
+}
+
+// configReloader watches the config file and applies the changes
+// of reloadable fields at runtime. The update which is invalid or
+// changes the other fields is rejected.
+type configReloader struct {
+	path  string
+	log   *logrus.Entry
+	apply func(*configuration) error
+
+	lock    sync.RWMutex
+	current *configuration
+	modTime time.Time
+}
+
+func newConfigReloader(
+	path string, cfg *configuration, log *logrus.Entry, apply func(*configuration) error,
+) (*configReloader, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return &configReloader{
+		path:    path,
+		log:     log,
+		apply:   apply,
+		current: cfg,
+		modTime: info.ModTime(),
+	}, nil
+}
+
+// Get returns the effective config in which the secrets are masked.
+func (r *configReloader) Get() map[string]interface{} {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+
+	m, err := configToMap(r.current)
+	if err != nil {
+		r.log.Errorf("convert config failed, err:%s", err.Error())
+
+		return nil
+	}
+
+	maskConfig(m)
+
+	return m
+}
+
+func (r *configReloader) interval() time.Duration {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+
+	return time.Duration(r.current.ConfigReloadInterval) * time.Second
+}
+
+func (r *configReloader) run() {
+	for {
+		time.Sleep(r.interval())
+
+		if err := r.reload(); err != nil {
+			r.log.Errorf("reload config failed, err:%s", err.Error())
+		}
+	}
+}
+
+func (r *configReloader) reload() error {
+	info, err := os.Stat(r.path)
+	if err != nil {
+		return err
+	}
+
+	modTime := info.ModTime()
+	if modTime.Equal(r.modTime) {
+		return nil
+	}
+
+	// the file may be being written, so it is read again next time.
+	cfg := new(configuration)
+	if err := utils.LoadFromYaml(r.path, cfg); err != nil {
+		return fmt.Errorf("load config failed, err:%s", err.Error())
+	}
+
+	cfg.setDefault()
+
+	retry, err := r.update(cfg)
+	if !retry {
+		r.modTime = modTime
+	}
+
+	return err
+}
+
+// update validates the new config and applies it if only the reloadable
+// fields are changed. The diff is logged even if the config is rejected.
+// It returns true if the config should be tried again next time.
+func (r *configReloader) update(cfg *configuration) (bool, error) {
+	r.lock.RLock()
+	diff, err := diffConfig(r.current, cfg)
+	r.lock.RUnlock()
+
+	if err != nil {
+		return false, err
+	}
+
+	if len(diff) == 0 {
+		return false, nil
+	}
+
+	var changed, rejected []string
+	for _, d := range diff {
+		changed = append(changed, d.String())
+
+		if !d.reloadable() {
+			rejected = append(rejected, d.field)
+		}
+	}
+
+	diffs := strings.Join(changed, "\n")
+
+	if err := cfg.validate(); err != nil {
+		return false, fmt.Errorf(
+			"invalid config, err:%s, the diff:\n%s", err.Error(), diffs,
+		)
+	}
+
+	if len(rejected) > 0 {
+		return false, fmt.Errorf(
+			"the fields: %s can't be changed at runtime, the diff:\n%s",
+			strings.Join(rejected, ", "), diffs,
+		)
+	}
+
+	// the failure may be temporary, such as the agency can't be assumed
+	// because of the network, so the config is applied again next time.
+	if err := r.apply(cfg); err != nil {
+		return true, fmt.Errorf(
+			"apply config failed, err:%s, the diff:\n%s", err.Error(), diffs,
+		)
+	}
+
+	r.lock.Lock()
+	r.current = cfg
+	r.lock.Unlock()
+
+	r.log.Infof("config is reloaded, the diff:\n%s", diffs)
+
+	return false, nil
+}
+
+type configDiff struct {
+	field    string
+	oldValue string
+	newValue string
+}
+
+func (d *configDiff) reloadable() bool {
+	for _, f := range reloadableFields {
+		if d.field == f || (strings.HasSuffix(f, ".") && strings.HasPrefix(d.field, f)) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (d *configDiff) String() string {
+	o, n := d.oldValue, d.newValue
+	if isSensitiveField(d.field) {
+		o, n = maskedValue, maskedValue
+	} else {
+		o, n = maskJSONURL(o), maskJSONURL(n)
+	}
+
+	return fmt.Sprintf("%s: %s -> %s", d.field, o, n)
+}
+
+func diffConfig(oldCfg, newCfg *configuration) ([]configDiff, error) {
+	o, err := flattenConfig(oldCfg)
+	if err != nil {
+		return nil, err
+	}
+
+	n, err := flattenConfig(newCfg)
+	if err != nil {
+		return nil, err
+	}
+
+	for k := range n {
+		if _, ok := o[k]; !ok {
+			o[k] = ""
+		}
+	}
+
+	r := []configDiff{}
+	for k, v := range o {
+		if nv := n[k]; nv != v {
+			r = append(r, configDiff{field: k, oldValue: v, newValue: nv})
+		}
+	}
+
+	sort.Slice(r, func(i, j int) bool {
+		return r[i].field < r[j].field
+	})
+
+	return r, nil
+}
+
+func configToMap(cfg *configuration) (map[string]interface{}, error) {
+	b, err := json.Marshal(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	var m map[string]interface{}
+	err = json.Unmarshal(b, &m)
+
+	return m, err
+}
+
+// flattenConfig returns the values of fields whose names are joined
+// by ".". The array is treated as a single value.
+func flattenConfig(cfg *configuration) (map[string]string, error) {
+	m, err := configToMap(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	r := map[string]string{}
+
+	var flatten func(string, map[string]interface{})
+	flatten = func(prefix string, m map[string]interface{}) {
+		for k, v := range m {
+			if sub, ok := v.(map[string]interface{}); ok {
+				flatten(prefix+k+".", sub)
+
+				continue
+			}
+
+			b, _ := json.Marshal(v)
+			r[prefix+k] = string(b)
+		}
+	}
+
+	flatten("", m)
+
+	return r, nil
+}
+
+func maskConfig(m map[string]interface{}) {
+	for k, v := range m {
+		switch sub := v.(type) {
+		case map[string]interface{}:
+			maskConfig(sub)
+
+		case []interface{}:
+			// the items of array may have the sensitive fields too.
+			for _, item := range sub {
+				if im, ok := item.(map[string]interface{}); ok {
+					maskConfig(im)
+				}
+			}
+
+		case string:
+			if isSensitiveField(k) && sub != "" {
+				m[k] = maskedValue
+			} else {
+				m[k] = maskURL(sub)
+			}
+
+		default:
+			if isSensitiveField(k) {
+				m[k] = maskedValue
+			}
+		}
+	}
+}
+
+// maskURL masks the userinfo of url, such as the token in the
+// url of mirror of pip. It returns v if it is not such a url.
+func maskURL(v string) string {
+	if !strings.Contains(v, "@") {
+		return v
+	}
+
+	u, err := url.Parse(v)
+	if err != nil || u.User == nil {
+		return v
+	}
+
+	// url.User would escape the masked value.
+	u.User = nil
+
+	return strings.Replace(u.String(), "//", "//"+maskedValue+"@", 1)
+}
+
+// maskJSONURL masks the userinfo of url which is encoded as json string.
+func maskJSONURL(v string) string {
+	var s string
+	if err := json.Unmarshal([]byte(v), &s); err != nil {
+		return v
+	}
+
+	if m := maskURL(s); m != s {
+		b, _ := json.Marshal(m)
+
+		return string(b)
+	}
+
+	return v
+}
+
+func isSensitiveField(field string) bool {
+	if i := strings.LastIndex(field, "."); i >= 0 {
+		field = field[i+1:]
+	}
+
+	for _, f := range sensitiveFields {
+		if field == f {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,170 @@
+package trainingimpl
+
+import (
+	"bytes"
+	_ "embed"
+	"encoding/json"
+	"path/filepath"
+	"text/template"
+
+	"sigs.k8s.io/yaml"
+
+	"github.com/opensourceways/xihe-training-center/domain"
+	"github.com/opensourceways/xihe-training-center/utils"
+)
+
+const (
+	paramsFilePrefix = "xihe_params_"
+
+	// codeInputKey is the name of input by which the code dir is
+	// downloaded if the boot file is generated.
+	codeInputKey = "xihe_code"
+)
+
+//go:embed tools/boot.py.tmpl
+var bootTemplate string
+
+var bootTmpl = template.Must(template.New("boot").Parse(bootTemplate))
+
+// the values are rendered as json which is a valid literal of python.
+type bootValues struct {
+	CodeInput        string
+	BootFile         string
+	RequirementsFile string
+	Dependencies     string
+	PipOptions       string
+
+	// PassArgs is True if the arguments of boot file are passed through.
+	PassArgs   string
+	Args       string
+	ParamsFile string
+}
+
+// bootArgs is how the boot file of user is invoked.
+type bootArgs struct {
+	passArgs   bool
+	args       []string
+	paramsFile string
+}
+
+// genBootFile generates the boot file of training and saves it under dir
+// which is outside of the snapshot of code. It installs the dependencies
+// before running the boot file of user, if there are dependencies or the
+// requirements file in code dir. It is generated too if the
+// hyperparameters are not passed as flags. It returns empty if the boot
+// file is not generated.
+func (impl trainingImpl) genBootFile(t *domain.UserTraining, dir string) (string, error) {
+	cfg := &impl.dependency
+	code := impl.codePath(t)
+
+	exist, err := impl.isObjectExist(filepath.Join(code, cfg.RequirementsFile))
+	if err != nil {
+		return "", err
+	}
+
+	if !exist && len(t.Dependencies) == 0 && t.ParameterRender.IsFlags() {
+		return "", nil
+	}
+
+	args, err := impl.genBootArgs(t, dir)
+	if err != nil {
+		return "", err
+	}
+
+	content, err := genBootContent(cfg, t.BootFile.FilePath(), t.Dependencies, &args)
+	if err != nil {
+		return "", err
+	}
+
+	name := cfg.BootFilePrefix + utils.GenMD5(content) + ".py"
+
+	return name, impl.putObject(filepath.Join(dir, name), content)
+}
+
+func (impl trainingImpl) genBootArgs(t *domain.UserTraining, dir string) (r bootArgs, err error) {
+	p := &t.ParameterRender
+
+	switch {
+	case p.IsFlags():
+		r.passArgs = true
+
+	case p.Template != nil:
+		r.args, err = p.Template.Render(t.Hypeparameters)
+
+	case p.FileFormat != nil:
+		var content []byte
+		if content, err = genParamsContent(t.Hypeparameters, p.FileFormat); err != nil {
+			return
+		}
+
+		r.paramsFile = paramsFilePrefix + utils.GenMD5(content) + "." +
+			p.FileFormat.ParameterFileFormat()
+
+		err = impl.putObject(filepath.Join(dir, r.paramsFile), content)
+	}
+
+	return
+}
+
+func genParamsContent(kv []domain.KeyValue, format domain.ParameterFileFormat) ([]byte, error) {
+	m := make(map[string]string, len(kv))
+	for i := range kv {
+		v := ""
+		if kv[i].Value != nil {
+			v = kv[i].Value.CustomizedValue()
+		}
+
+		m[kv[i].Key.CustomizedKey()] = v
+	}
+
+	if format.ParameterFileFormat() == domain.ParameterFileFormatYAML.ParameterFileFormat() {
+		return yaml.Marshal(m)
+	}
+
+	return json.MarshalIndent(m, "", "  ")
+}
+
+func genBootContent(
+	cfg *DependencyConfig, boot string, deps []domain.Dependency, args *bootArgs,
+) ([]byte, error) {
+	v := make([]string, len(deps))
+	for i := range deps {
+		v[i] = deps[i].Dependency()
+	}
+
+	opts := []string{}
+	if cfg.IndexURL != "" {
+		opts = append(opts, "-i", cfg.IndexURL)
+	}
+	if cfg.TrustedHost != "" {
+		opts = append(opts, "--trusted-host", cfg.TrustedHost)
+	}
+
+	toJSON := func(i interface{}) string {
+		b := new(bytes.Buffer)
+		enc := json.NewEncoder(b)
+		enc.SetEscapeHTML(false)
+		enc.Encode(i)
+
+		return string(bytes.TrimSpace(b.Bytes()))
+	}
+
+	passArgs := "False"
+	if args.passArgs {
+		passArgs = "True"
+	}
+
+	buf := new(bytes.Buffer)
+	err := bootTmpl.Execute(buf, bootValues{
+		CodeInput:        toJSON(codeInputKey),
+		BootFile:         toJSON(boot),
+		RequirementsFile: toJSON(cfg.RequirementsFile),
+		Dependencies:     toJSON(v),
+		PipOptions:       toJSON(opts),
+		PassArgs:         passArgs,
+		Args:             toJSON(append([]string{}, args.args...)),
+		ParamsFile:       toJSON(args.paramsFile),
+	})
+
+	return buf.Bytes(), err
+}
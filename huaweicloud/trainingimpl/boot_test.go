@@ -0,0 +1,319 @@
+package trainingimpl
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/opensourceways/xihe-training-center/domain"
+	"github.com/opensourceways/xihe-training-center/huaweicloud/modelarts"
+)
+
+func newTestDependencies(t *testing.T, v ...string) []domain.Dependency {
+	t.Helper()
+
+	r := make([]domain.Dependency, len(v))
+	for i := range v {
+		d, err := domain.NewDependency(v[i])
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		r[i] = d
+	}
+
+	return r
+}
+
+func TestGenBootContent(t *testing.T) {
+	cases := []struct {
+		name string
+		cfg  DependencyConfig
+		deps []string
+		args bootArgs
+		want []string
+	}{
+		{
+			name: "flags",
+			cfg:  DependencyConfig{RequirementsFile: "requirements.txt"},
+			deps: []string{"numpy==1.21.0"},
+			args: bootArgs{passArgs: true},
+			want: []string{
+				`code_dir = pop_arg(argv, "xihe_code") or boot_dir`,
+				`boot_file = os.path.join(code_dir, "src/train.py")`,
+				`requirements = os.path.join(code_dir, "requirements.txt")`,
+				`dependencies = ["numpy==1.21.0"]`,
+				`pip_options = []`,
+				`pass_args = True`,
+				`args = []`,
+				`params_file = ""`,
+			},
+		},
+		{
+			name: "file",
+			cfg: DependencyConfig{
+				RequirementsFile: "req.txt",
+				IndexURL:         "http://mirror/simple",
+				TrustedHost:      "mirror",
+			},
+			args: bootArgs{paramsFile: "xihe_params_1.yaml"},
+			want: []string{
+				`requirements = os.path.join(code_dir, "req.txt")`,
+				`dependencies = []`,
+				`pip_options = ["-i","http://mirror/simple","--trusted-host","mirror"]`,
+				`pass_args = False`,
+				`params_file = "xihe_params_1.yaml"`,
+			},
+		},
+		{
+			name: "template",
+			args: bootArgs{args: []string{"--lr", "0.1", `a"b`, "<x>"}},
+			want: []string{
+				`pass_args = False`,
+				`args = ["--lr","0.1","a\"b","<x>"]`,
+				`params_file = ""`,
+			},
+		},
+	}
+
+	for i := range cases {
+		c := &cases[i]
+
+		v, err := genBootContent(
+			&c.cfg, "src/train.py", newTestDependencies(t, c.deps...), &c.args,
+		)
+		if err != nil {
+			t.Fatalf("case %s: %v", c.name, err)
+		}
+
+		lines := make(map[string]bool)
+		for _, line := range strings.Split(string(v), "\n") {
+			lines[line] = true
+		}
+
+		for _, w := range c.want {
+			if !lines[w] {
+				t.Errorf("case %s: missing line %q in:\n%s", c.name, w, v)
+			}
+		}
+	}
+}
+
+func TestGenParamsContent(t *testing.T) {
+	kv := make([]domain.KeyValue, 2)
+	for i, s := range [][2]string{{"lr", "0.1"}, {"data", ""}} {
+		kv[i].Key, _ = domain.NewCustomizedKey(s[0])
+		kv[i].Value, _ = domain.NewCustomizedValue(s[1])
+	}
+
+	cases := []struct {
+		format domain.ParameterFileFormat
+		want   string
+	}{
+		{domain.ParameterFileFormatJSON, "{\n  \"data\": \"\",\n  \"lr\": \"0.1\"\n}"},
+		{domain.ParameterFileFormatYAML, "data: \"\"\nlr: \"0.1\"\n"},
+	}
+
+	for _, c := range cases {
+		v, err := genParamsContent(kv, c.format)
+		if err != nil {
+			t.Fatalf("format %s: %v", c.format.ParameterFileFormat(), err)
+		}
+
+		if string(v) != c.want {
+			t.Errorf("format %s: content = %q, want %q", c.format.ParameterFileFormat(), v, c.want)
+		}
+	}
+}
+
+// TestBootFileRuns runs the generated boot file in the way of ModelArts,
+// which passes the hyperparameters and the local paths of inputs as flags.
+func TestBootFileRuns(t *testing.T) {
+	python, err := exec.LookPath("python3")
+	if err != nil {
+		t.Skip("python3 is not installed")
+	}
+
+	const userBoot = `import json, os, sys
+print(json.dumps({"argv": sys.argv[1:], "cwd": os.getcwd()}))
+`
+
+	cases := []struct {
+		name     string
+		args     bootArgs
+		wantArgs []string
+	}{
+		{
+			name:     "flags",
+			args:     bootArgs{passArgs: true},
+			wantArgs: []string{"--lr=0.1", "--data=/inputs/data"},
+		},
+		{
+			name:     "template",
+			args:     bootArgs{args: []string{"--lr", "0.1"}},
+			wantArgs: []string{"--lr", "0.1"},
+		},
+		{
+			name:     "file",
+			args:     bootArgs{paramsFile: "params.json"},
+			wantArgs: []string{"BOOT_DIR/params.json"},
+		},
+	}
+
+	for i := range cases {
+		c := &cases[i]
+
+		dir := t.TempDir()
+		code := filepath.Join(dir, "code")
+		boot := filepath.Join(dir, "boot")
+
+		writeTestFile(t, filepath.Join(code, "src", "train.py"), userBoot)
+
+		content, err := genBootContent(
+			&DependencyConfig{RequirementsFile: "requirements.txt"},
+			"src/train.py", nil, &c.args,
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		writeTestFile(t, filepath.Join(boot, "boot.py"), string(content))
+
+		// both of the forms of flag are accepted.
+		codeArgs := []string{"--" + codeInputKey + "=" + code}
+		if i%2 == 1 {
+			codeArgs = []string{"--" + codeInputKey, code}
+		}
+
+		args := append([]string{filepath.Join(boot, "boot.py"), "--lr=0.1"}, codeArgs...)
+		args = append(args, "--data=/inputs/data")
+
+		out, err := exec.Command(python, args...).CombinedOutput()
+		if err != nil {
+			t.Fatalf("case %s: run failed, err:%v, out:%s", c.name, err, out)
+		}
+
+		var r struct {
+			Argv []string `json:"argv"`
+			Cwd  string   `json:"cwd"`
+		}
+
+		if err := json.Unmarshal(out, &r); err != nil {
+			t.Fatalf("case %s: unexpected output: %s", c.name, out)
+		}
+
+		want := make([]string, len(c.wantArgs))
+		for j, v := range c.wantArgs {
+			want[j] = strings.Replace(v, "BOOT_DIR", boot, 1)
+		}
+
+		if !reflect.DeepEqual(r.Argv, want) {
+			t.Errorf("case %s: args = %q, want %q", c.name, r.Argv, want)
+		}
+
+		if cwd, _ := filepath.EvalSymlinks(r.Cwd); cwd != mustEvalSymlinks(t, code) {
+			t.Errorf("case %s: cwd = %s, want the code dir", c.name, r.Cwd)
+		}
+	}
+}
+
+func writeTestFile(t *testing.T, path, content string) {
+	t.Helper()
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := ioutil.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func mustEvalSymlinks(t *testing.T, path string) string {
+	t.Helper()
+
+	v, err := filepath.EvalSymlinks(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return v
+}
+
+func TestGenEngineWithCustomImage(t *testing.T) {
+	url, err := domain.NewImageURL("swr.example.com/org/image:v1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cmd, err := domain.NewImageCommand("bash run.sh")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		name        string
+		workDir     string
+		userId      int
+		wantWorkDir string
+		wantUser    string
+	}{
+		{
+			name:        "default",
+			wantWorkDir: "/home/ma-user/code/src",
+		},
+		{
+			name:        "work dir and user",
+			workDir:     "scripts/train",
+			userId:      1000,
+			wantWorkDir: "/home/ma-user/code/src/scripts/train",
+			wantUser:    "1000",
+		},
+	}
+
+	impl := trainingImpl{
+		config: TrainingConfig{LocalCodeDir: "/home/ma-user/code"},
+	}
+
+	for i := range cases {
+		c := &cases[i]
+
+		workDir, err := domain.NewDirectory(c.workDir)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		ut := domain.UserTraining{}
+		ut.Compute.Image = &domain.CustomImage{
+			URL:     url,
+			Command: cmd,
+			WorkDir: workDir,
+			UserId:  c.userId,
+		}
+
+		var opt modelarts.AlgorithmOption
+
+		if err := impl.genEngine(&ut, "bucket/repo/snapshot/src", "boot", &opt); err != nil {
+			t.Fatalf("case %s: %v", c.name, err)
+		}
+
+		want := modelarts.AlgorithmOption{
+			Command:      "bash run.sh",
+			LocalCodeDir: "/home/ma-user/code",
+			WorkingDir:   c.wantWorkDir,
+			Engine: modelarts.EngineOption{
+				ImageURL: "swr.example.com/org/image:v1",
+				RunUser:  c.wantUser,
+			},
+		}
+
+		if !reflect.DeepEqual(opt, want) {
+			t.Errorf("case %s: option = %+v, want %+v", c.name, opt, want)
+		}
+	}
+}
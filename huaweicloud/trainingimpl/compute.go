@@ -0,0 +1,65 @@
+package trainingimpl
+
+import (
+	"github.com/sirupsen/logrus"
+
+	"github.com/opensourceways/xihe-training-center/domain"
+	"github.com/opensourceways/xihe-training-center/domain/compute"
+	"github.com/opensourceways/xihe-training-center/huaweicloud/modelarts"
+)
+
+func NewCompute(cfg *Config, creds *Credentials, log *logrus.Entry) (compute.Compute, error) {
+	cli, err := newClient(cfg, creds.modelarts, log)
+	if err != nil {
+		return nil, err
+	}
+
+	return computeImpl{cli}, nil
+}
+
+type computeImpl struct {
+	cli *serviceClient
+}
+
+func (impl computeImpl) ListEngines() ([]domain.ComputeEngine, error) {
+	v, err := modelarts.ListEngines(impl.cli.get())
+	if err != nil {
+		return nil, err
+	}
+
+	// the versions of the same engine are put together.
+	index := map[string]int{}
+	r := []domain.ComputeEngine{}
+
+	for i := range v {
+		item := &v[i]
+
+		j, ok := index[item.EngineName]
+		if !ok {
+			j = len(r)
+			index[item.EngineName] = j
+			r = append(r, domain.ComputeEngine{Type: item.EngineName})
+		}
+
+		r[j].Versions = append(r[j].Versions, item.EngineVersion)
+	}
+
+	return r, nil
+}
+
+func (impl computeImpl) ListFlavors() ([]domain.ComputeFlavorInfo, error) {
+	v, err := modelarts.ListFlavors(impl.cli.get())
+	if err != nil {
+		return nil, err
+	}
+
+	r := make([]domain.ComputeFlavorInfo, len(v))
+	for i := range v {
+		r[i] = domain.ComputeFlavorInfo{
+			Flavor: v[i].FlavorId,
+			Desc:   v[i].FlavorName,
+		}
+	}
+
+	return r, nil
+}
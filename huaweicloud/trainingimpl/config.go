@@ -3,6 +3,9 @@ package trainingimpl
 import (
 	"errors"
 	"path/filepath"
+	"time"
+
+	"github.com/opensourceways/xihe-training-center/huaweicloud/client"
 )
 
 type configSetDefault interface {
@@ -18,6 +21,7 @@ type Config struct {
 	Train         TrainingConfig      `json:"train"       required:"true"`
 	Modelarts     ModelartsConfig     `json:"modelarts"   required:"true"`
 	SyncAndUpload SyncAndUploadConfig `json:"sync"        required:"true"`
+	Dependency    DependencyConfig    `json:"dependency"`
 }
 
 func (cfg *Config) configItems() []interface{} {
@@ -26,6 +30,7 @@ func (cfg *Config) configItems() []interface{} {
 		&cfg.Train,
 		&cfg.Modelarts,
 		&cfg.SyncAndUpload,
+		&cfg.Dependency,
 	}
 }
 
@@ -40,6 +45,13 @@ func (cfg *Config) Validate() error {
 		}
 	}
 
+	// the temporary credential is used by obs too.
+	if !cfg.Modelarts.Credential.isTemporary() {
+		if cfg.OBS.AccessKey == "" || cfg.OBS.SecretKey == "" {
+			return errors.New("missing access_key or secret_key of obs")
+		}
+	}
+
 	return nil
 }
 
@@ -54,14 +66,112 @@ func (cfg *Config) SetDefault() {
 }
 
 type ModelartsConfig struct {
-	AccessKey   string `json:"access_key" required:"true"`
-	SecretKey   string `json:"secret_key" required:"true"`
+	// AccessKey and SecretKey are not needed if the credential
+	// is got from the metadata of ECS.
+	AccessKey   string `json:"access_key"`
+	SecretKey   string `json:"secret_key"`
 	Region      string `json:"region" required:"true"`
 	ProjectName string `json:"project_name" required:"true"`
 	ProjectId   string `json:"project_id" required:"true"`
 
 	// modelarts endpoint
 	Endpoint string `json:"endpoint" required:"true"`
+
+	// Log specifies what are redacted when logging the requests.
+	Log client.LogConfig `json:"log"`
+
+	// Credential specifies where the credential of ModelArts and OBS
+	// is got from.
+	Credential CredentialConfig `json:"credential"`
+}
+
+func (cfg *ModelartsConfig) setDefault() {
+	cfg.Credential.setDefault()
+}
+
+func (cfg *ModelartsConfig) validate() error {
+	if cfg.Credential.Source != credentialSourceMetadata {
+		if cfg.AccessKey == "" || cfg.SecretKey == "" {
+			return errors.New("missing access_key or secret_key of modelarts")
+		}
+	}
+
+	return cfg.Credential.validate()
+}
+
+const (
+	credentialSourceAKSK     = "aksk"
+	credentialSourceAgency   = "agency"
+	credentialSourceMetadata = "metadata"
+)
+
+type CredentialConfig struct {
+	// Source is one of aksk, agency and metadata. It is aksk by default
+	// which means the AK/SK of ModelArts and OBS are used. The temporary
+	// credential is got by assuming the agency with the AK/SK of ModelArts
+	// if it is agency, or from the metadata of ECS which the agency is
+	// bound to if it is metadata. The temporary credential is used by
+	// both ModelArts and OBS, and refreshed before it expires.
+	Source string `json:"source"`
+
+	// AgencyDomain is the name of domain which created the agency.
+	AgencyDomain string `json:"agency_domain"`
+	AgencyName   string `json:"agency_name"`
+
+	// Duration is the seconds which the temporary credential
+	// got by assuming the agency is valid for.
+	Duration int `json:"duration"`
+
+	// RefreshAhead is the seconds before the temporary
+	// credential expires when it is refreshed.
+	RefreshAhead int `json:"refresh_ahead"`
+}
+
+func (cfg *CredentialConfig) isTemporary() bool {
+	return cfg.Source != "" && cfg.Source != credentialSourceAKSK
+}
+
+func (cfg *CredentialConfig) refreshAhead() time.Duration {
+	return time.Duration(cfg.RefreshAhead) * time.Second
+}
+
+func (cfg *CredentialConfig) setDefault() {
+	if cfg.Source == "" {
+		cfg.Source = credentialSourceAKSK
+	}
+
+	if cfg.Duration <= 0 {
+		cfg.Duration = 3600
+	}
+
+	if cfg.RefreshAhead <= 0 {
+		cfg.RefreshAhead = 300
+	}
+}
+
+func (cfg *CredentialConfig) validate() error {
+	switch cfg.Source {
+	case credentialSourceAKSK, credentialSourceMetadata:
+
+	case credentialSourceAgency:
+		if cfg.AgencyDomain == "" || cfg.AgencyName == "" {
+			return errors.New("missing agency_domain or agency_name")
+		}
+
+		// the range which IAM supports
+		if cfg.Duration < 900 || cfg.Duration > 86400 {
+			return errors.New("duration must be between 900 and 86400")
+		}
+
+		if cfg.RefreshAhead >= cfg.Duration {
+			return errors.New("refresh_ahead must be less than duration")
+		}
+
+	default:
+		return errors.New("invalid credential source")
+	}
+
+	return nil
 }
 
 type TrainingConfig struct {
@@ -70,6 +180,14 @@ type TrainingConfig struct {
 	AimDir    string `json:"aim_dir"`
 	OutputKey string `json:"output_key"`
 	OutputDir string `json:"output_dir"`
+
+	// BootDir is the directory under the obs path of training where the
+	// generated boot file and the file of hyperparameters are saved.
+	BootDir string `json:"boot_dir"`
+
+	// LocalCodeDir is the directory of container where
+	// the code dir is downloaded to for the custom image.
+	LocalCodeDir string `json:"local_code_dir"`
 }
 
 func (cfg *TrainingConfig) setDefault() {
@@ -78,11 +196,15 @@ func (cfg *TrainingConfig) setDefault() {
 	cfg.AimDir = "tain-aim"
 	cfg.OutputKey = "output_path"
 	cfg.OutputDir = "train-output"
+	cfg.BootDir = "train-boot"
+	cfg.LocalCodeDir = "/home/ma-user/modelarts/user-job-dir"
 }
 
 type OBSConfig struct {
-	AccessKey string `json:"access_key"    required:"true"`
-	SecretKey string `json:"secret_key"    required:"true"`
+	// AccessKey and SecretKey are not needed if
+	// the temporary credential is used.
+	AccessKey string `json:"access_key"`
+	SecretKey string `json:"secret_key"`
 	Endpoint  string `json:"endpoint"      required:"true"`
 	Bucket    string `json:"bucket"        required:"true"`
 }
@@ -96,6 +218,34 @@ type SyncAndUploadConfig struct {
 
 	UploadWorkDir     string `json:"upload_work_dir"      required:"true"`
 	UploadFolderShell string `json:"upload_folder_shell"  required:"true"`
+
+	// SnapshotDir is the directory under the obs path of project
+	// where the immutable snapshot of each commit is saved.
+	SnapshotDir string `json:"snapshot_dir"`
+
+	// PartialSnapshotDir is the directory under the obs path of project
+	// where the snapshots which include only a directory of repo are saved.
+	PartialSnapshotDir string `json:"partial_snapshot_dir"`
+
+	// InputSnapshotPath is the obs path where the immutable snapshot of each
+	// commit of the dataset or model used by trainings is saved. It is apart
+	// from RepoPath in which the resources are synced by the other service.
+	InputSnapshotPath  string `json:"input_snapshot_path"`
+	SnapshotInputShell string `json:"snapshot_input_shell"    required:"true"`
+}
+
+func (c *SyncAndUploadConfig) setDefault() {
+	if c.SnapshotDir == "" {
+		c.SnapshotDir = "code-snapshot"
+	}
+
+	if c.PartialSnapshotDir == "" {
+		c.PartialSnapshotDir = "code-snapshot-partial"
+	}
+
+	if c.InputSnapshotPath == "" {
+		c.InputSnapshotPath = "xihe-input-snapshot"
+	}
 }
 
 func (c *SyncAndUploadConfig) validate() error {
@@ -123,5 +273,41 @@ func (c *SyncAndUploadConfig) validate() error {
 		return errors.New("upload_folder_shell must be an absolute path")
 	}
 
+	if filepath.IsAbs(c.InputSnapshotPath) {
+		return errors.New("input_snapshot_path can't start with /")
+	}
+
+	if !filepath.IsAbs(c.SnapshotInputShell) {
+		return errors.New("snapshot_input_shell must be an absolute path")
+	}
+
 	return nil
 }
+
+type DependencyConfig struct {
+	// IndexURL is the url of the mirror of pip which the dependencies
+	// are installed from. The default index of pip is used if empty.
+	IndexURL string `json:"index_url"`
+
+	// TrustedHost is the host of IndexURL if it is not a https url.
+	TrustedHost string `json:"trusted_host"`
+
+	// RequirementsFile is the file under the code dir which
+	// lists the dependencies.
+	RequirementsFile string `json:"requirements_file"`
+
+	// BootFilePrefix is the prefix of the generated boot file which installs
+	// the dependencies and passes the hyperparameters in the specified way
+	// before running the boot file of user.
+	BootFilePrefix string `json:"boot_file_prefix"`
+}
+
+func (cfg *DependencyConfig) setDefault() {
+	if cfg.RequirementsFile == "" {
+		cfg.RequirementsFile = "requirements.txt"
+	}
+
+	if cfg.BootFilePrefix == "" {
+		cfg.BootFilePrefix = "xihe_boot_"
+	}
+}
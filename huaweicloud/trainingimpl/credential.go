@@ -0,0 +1,271 @@
+package trainingimpl
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/opensourceways/xihe-training-center/huaweicloud/client"
+)
+
+// minRefreshInterval is the minimum interval between two refreshes,
+// in case the refresh failed or the new credential expires soon too.
+const minRefreshInterval = time.Minute
+
+// Credentials are the credentials of ModelArts and OBS which
+// are shared by the clients of training and compute.
+type Credentials struct {
+	source    string
+	log       *logrus.Entry
+	modelarts *credential
+	obs       *credential
+
+	// keys are the AK/SK of ModelArts and OBS in the config which is
+	// applied last time. They are only changed by Update.
+	keys [2]client.Credential
+
+	// newAgencyFetch returns the func which assumes the agency with the
+	// AK/SK of ModelArts, it is newAgencyFetch except in tests.
+	newAgencyFetch func(*ModelartsConfig, *logrus.Entry) (fetchFunc, error)
+}
+
+// NewCredentials returns the credentials. The temporary credential
+// is refreshed in background before it expires.
+func NewCredentials(cfg *Config, log *logrus.Entry) (*Credentials, error) {
+	mc := &cfg.Modelarts
+
+	c := &Credentials{
+		source:         mc.Credential.Source,
+		log:            log,
+		keys:           configKeys(cfg),
+		newAgencyFetch: newAgencyFetch,
+	}
+
+	if !mc.Credential.isTemporary() {
+		c.modelarts = newStaticCredential(mc.AccessKey, mc.SecretKey, log)
+		c.obs = newStaticCredential(cfg.OBS.AccessKey, cfg.OBS.SecretKey, log)
+
+		return c, nil
+	}
+
+	v, err := newTemporaryCredential(mc, log)
+	if err != nil {
+		return nil, err
+	}
+
+	go v.keepRefreshing()
+
+	c.modelarts = v
+	c.obs = v
+
+	return c, nil
+}
+
+func configKeys(cfg *Config) [2]client.Credential {
+	return [2]client.Credential{
+		{AccessKey: cfg.Modelarts.AccessKey, SecretKey: cfg.Modelarts.SecretKey},
+		{AccessKey: cfg.OBS.AccessKey, SecretKey: cfg.OBS.SecretKey},
+	}
+}
+
+// Update applies the AK/SK of config to the clients. If the temporary
+// credential is got by assuming the agency, the agency is assumed with
+// the new AK/SK of ModelArts at once, and the error is returned if it
+// fails. The AK/SK which are not used by the temporary credential are
+// ignored with a warning. It must not be called concurrently.
+func (c *Credentials) Update(cfg *Config) error {
+	keys := configKeys(cfg)
+	if keys == c.keys {
+		return nil
+	}
+
+	switch c.source {
+	case credentialSourceAgency:
+		if keys[0] != c.keys[0] {
+			fetch, err := c.newAgencyFetch(&cfg.Modelarts, c.log)
+			if err != nil {
+				return err
+			}
+
+			if err := c.modelarts.resetFetch(fetch); err != nil {
+				return fmt.Errorf(
+					"assume agency with the new AK/SK of modelarts failed, err:%s",
+					err.Error(),
+				)
+			}
+
+			c.log.Info("the agency is assumed with the new AK/SK of modelarts")
+		}
+
+		if keys[1] != c.keys[1] {
+			c.log.Warn("the AK/SK of obs are ignored, the temporary credential is used")
+		}
+
+	case credentialSourceMetadata:
+		c.log.Warn("the AK/SK are ignored, the credential is got from the metadata of ECS")
+
+	default:
+		c.modelarts.set(keys[0])
+		c.obs.set(keys[1])
+	}
+
+	c.keys = keys
+
+	return nil
+}
+
+// credential keeps the credential and notifies the subscribers after it
+// is changed. The temporary credential is refreshed before it expires.
+type credential struct {
+	cfg CredentialConfig
+	log *logrus.Entry
+
+	// after waits for the duration, it is time.After except in tests.
+	after func(time.Duration) <-chan time.Time
+
+	mu          sync.RWMutex
+	fetch       fetchFunc
+	current     client.Credential
+	subscribers []func(*client.Credential) error
+}
+
+type fetchFunc func() (client.Credential, error)
+
+func newStaticCredential(ak, sk string, log *logrus.Entry) *credential {
+	return &credential{
+		log: log,
+		current: client.Credential{
+			AccessKey: ak,
+			SecretKey: sk,
+		},
+	}
+}
+
+func newTemporaryCredential(cfg *ModelartsConfig, log *logrus.Entry) (*credential, error) {
+	c := &credential{
+		cfg:   cfg.Credential,
+		log:   log,
+		after: time.After,
+	}
+
+	if cfg.Credential.Source == credentialSourceMetadata {
+		c.fetch = client.GetCredentialFromMetadata
+	} else {
+		f, err := newAgencyFetch(cfg, log)
+		if err != nil {
+			return nil, err
+		}
+
+		c.fetch = f
+	}
+
+	v, err := c.fetch()
+	if err != nil {
+		return nil, err
+	}
+
+	c.current = v
+
+	return c, nil
+}
+
+// newAgencyFetch returns the func which assumes the agency with the AK/SK of cfg.
+func newAgencyFetch(cfg *ModelartsConfig, log *logrus.Entry) (fetchFunc, error) {
+	v := genClientConfig(cfg, nil, log)
+	if err := v.LoadAndValidate(); err != nil {
+		return nil, err
+	}
+
+	cc := cfg.Credential
+
+	return func() (client.Credential, error) {
+		return v.AssumeAgency(cc.AgencyDomain, cc.AgencyName, cc.Duration)
+	}, nil
+}
+
+func (c *credential) get() client.Credential {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return c.current
+}
+
+func (c *credential) subscribe(f func(*client.Credential) error) {
+	c.mu.Lock()
+	c.subscribers = append(c.subscribers, f)
+	c.mu.Unlock()
+}
+
+func (c *credential) keepRefreshing() {
+	for {
+		<-c.after(c.nextRefresh())
+
+		if err := c.refresh(); err != nil {
+			c.log.Errorf("refresh temporary credential failed, err:%s", err.Error())
+		}
+	}
+}
+
+func (c *credential) nextRefresh() time.Duration {
+	d := time.Until(c.get().ExpiresAt) - c.cfg.refreshAhead()
+	if d < minRefreshInterval {
+		return minRefreshInterval
+	}
+
+	return d
+}
+
+func (c *credential) refresh() error {
+	c.mu.RLock()
+	fetch := c.fetch
+	c.mu.RUnlock()
+
+	v, err := fetch()
+	if err != nil {
+		return err
+	}
+
+	c.set(v)
+
+	c.log.Infof("temporary credential is refreshed, it expires at %s", v.ExpiresAt)
+
+	return nil
+}
+
+// resetFetch fetches the credential by f, and refreshes the credential
+// by f from now on if it succeeds. The previous one is kept if it fails.
+func (c *credential) resetFetch(f fetchFunc) error {
+	v, err := f()
+	if err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	c.fetch = f
+	c.mu.Unlock()
+
+	c.set(v)
+
+	return nil
+}
+
+func (c *credential) set(v client.Credential) {
+	c.mu.Lock()
+	if c.current == v {
+		c.mu.Unlock()
+
+		return
+	}
+
+	c.current = v
+	subscribers := c.subscribers
+	c.mu.Unlock()
+
+	for _, f := range subscribers {
+		if err := f(&v); err != nil {
+			c.log.Errorf("apply credential failed, err:%s", err.Error())
+		}
+	}
+}
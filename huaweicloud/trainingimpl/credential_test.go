@@ -0,0 +1,251 @@
+package trainingimpl
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/opensourceways/xihe-training-center/huaweicloud/client"
+)
+
+func TestNextRefresh(t *testing.T) {
+	cases := []struct {
+		name      string
+		expiresIn time.Duration
+		ahead     int
+		want      time.Duration
+	}{
+		{"before expiry", time.Hour, 300, 55 * time.Minute},
+		{"no ahead", time.Hour, 0, time.Hour},
+		{"expires soon", 30 * time.Second, 300, minRefreshInterval},
+		{"expired", -time.Hour, 300, minRefreshInterval},
+	}
+
+	for _, c := range cases {
+		cred := credential{
+			cfg:     CredentialConfig{RefreshAhead: c.ahead},
+			current: client.Credential{ExpiresAt: time.Now().Add(c.expiresIn)},
+		}
+
+		// allow the time passed since the credential is set.
+		if v := cred.nextRefresh(); v > c.want || v < c.want-time.Second {
+			t.Errorf("case %s: next refresh = %s, want %s", c.name, v, c.want)
+		}
+	}
+}
+
+// testClock hands the waits of refresh loop over to the test.
+type testClock struct {
+	waits chan time.Duration
+	ticks chan time.Time
+}
+
+func newTestClock() *testClock {
+	return &testClock{
+		waits: make(chan time.Duration),
+		ticks: make(chan time.Time),
+	}
+}
+
+func (c *testClock) after(d time.Duration) <-chan time.Time {
+	c.waits <- d
+
+	return c.ticks
+}
+
+// next waits for the loop to sleep and returns how long it sleeps.
+func (c *testClock) next(t *testing.T) time.Duration {
+	t.Helper()
+
+	select {
+	case d := <-c.waits:
+		return d
+
+	case <-time.After(5 * time.Second):
+		t.Fatal("the refresh loop doesn't wait")
+	}
+
+	return 0
+}
+
+func TestKeepRefreshing(t *testing.T) {
+	const ahead = 600
+
+	var (
+		mu      sync.Mutex
+		fetched int
+		fail    bool
+	)
+
+	fetch := func() (client.Credential, error) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		if fail {
+			return client.Credential{}, errors.New("metadata is unavailable")
+		}
+
+		fetched++
+
+		return client.Credential{
+			AccessKey:     fmt.Sprintf("ak%d", fetched),
+			SecretKey:     "sk",
+			SecurityToken: "tk",
+			ExpiresAt:     time.Now().Add(time.Hour),
+		}, nil
+	}
+
+	clock := newTestClock()
+	cred := &credential{
+		cfg:   CredentialConfig{RefreshAhead: ahead},
+		log:   logrus.NewEntry(logrus.StandardLogger()),
+		fetch: fetch,
+		after: clock.after,
+	}
+
+	v, err := fetch()
+	if err != nil {
+		t.Fatal(err)
+	}
+	cred.current = v
+
+	// each subscriber receives the refreshed credential.
+	received := make([]chan string, 2)
+	for i := range received {
+		ch := make(chan string, 10)
+		received[i] = ch
+
+		cred.subscribe(func(c *client.Credential) error {
+			ch <- c.AccessKey
+
+			return errors.New("the failure of subscriber is only logged")
+		})
+	}
+
+	go cred.keepRefreshing()
+
+	// the credential is refreshed before it expires.
+	want := time.Hour - ahead*time.Second
+	if d := clock.next(t); d > want || d < want-time.Second {
+		t.Fatalf("sleep %s before refreshing, want %s", d, want)
+	}
+
+	clock.ticks <- time.Now()
+
+	for i, ch := range received {
+		select {
+		case v := <-ch:
+			if v != "ak2" {
+				t.Errorf("subscriber %d received %s, want ak2", i, v)
+			}
+
+		case <-time.After(5 * time.Second):
+			t.Fatalf("subscriber %d is not notified", i)
+		}
+	}
+
+	if v := cred.get().AccessKey; v != "ak2" {
+		t.Errorf("current credential = %s, want ak2", v)
+	}
+
+	// the loop goes on after the refresh failed.
+	clock.next(t)
+
+	mu.Lock()
+	fail = true
+	mu.Unlock()
+
+	clock.ticks <- time.Now()
+
+	clock.next(t)
+
+	if v := cred.get().AccessKey; v != "ak2" {
+		t.Errorf("current credential = %s, want the previous one", v)
+	}
+
+	for i, ch := range received {
+		if len(ch) != 0 {
+			t.Errorf("subscriber %d is notified without change", i)
+		}
+	}
+}
+
+func TestUpdateAgencyCredential(t *testing.T) {
+	log := logrus.NewEntry(logrus.StandardLogger())
+
+	// the credential of agency is named by the AK which assumes it.
+	var failed bool
+	newFetch := func(cfg *ModelartsConfig, _ *logrus.Entry) (fetchFunc, error) {
+		ak := cfg.AccessKey
+
+		return func() (client.Credential, error) {
+			if failed {
+				return client.Credential{}, errors.New("iam is unavailable")
+			}
+
+			return client.Credential{
+				AccessKey: "tmp-" + ak,
+				ExpiresAt: time.Now().Add(time.Hour),
+			}, nil
+		}, nil
+	}
+
+	cfg := &Config{}
+	cfg.Modelarts.AccessKey = "ak1"
+	cfg.Modelarts.SecretKey = "sk1"
+	cfg.Modelarts.Credential.Source = credentialSourceAgency
+
+	fetch, _ := newFetch(&cfg.Modelarts, log)
+	cred := &credential{log: log, fetch: fetch}
+	if err := cred.refresh(); err != nil {
+		t.Fatal(err)
+	}
+
+	c := &Credentials{
+		source:         credentialSourceAgency,
+		log:            log,
+		modelarts:      cred,
+		obs:            cred,
+		keys:           configKeys(cfg),
+		newAgencyFetch: newFetch,
+	}
+
+	// the agency is assumed with the new AK/SK at once.
+	cfg.Modelarts.AccessKey = "ak2"
+	if err := c.Update(cfg); err != nil {
+		t.Fatalf("update failed, err:%v", err)
+	}
+
+	if v := cred.get().AccessKey; v != "tmp-ak2" {
+		t.Fatalf("credential = %s, want tmp-ak2", v)
+	}
+
+	// the new AK/SK are used by the later refreshes.
+	if err := cred.refresh(); err != nil || cred.get().AccessKey != "tmp-ak2" {
+		t.Fatalf("refreshed credential = %s, err:%v, want tmp-ak2", cred.get().AccessKey, err)
+	}
+
+	// the update is rejected if the agency can't be assumed with the new AK/SK.
+	failed = true
+	cfg.Modelarts.AccessKey = "ak3"
+
+	if err := c.Update(cfg); err == nil {
+		t.Fatal("update with the AK/SK which can't assume the agency")
+	}
+
+	failed = false
+
+	if err := cred.refresh(); err != nil || cred.get().AccessKey != "tmp-ak2" {
+		t.Fatalf("refreshed credential = %s, err:%v, want the previous tmp-ak2",
+			cred.get().AccessKey, err)
+	}
+
+	// the same config is applied again after the failure.
+	if err := c.Update(cfg); err != nil || cred.get().AccessKey != "tmp-ak3" {
+		t.Fatalf("credential = %s, err:%v, want tmp-ak3", cred.get().AccessKey, err)
+	}
+}
@@ -0,0 +1,32 @@
+package trainingimpl
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/opensourceways/xihe-training-center/huaweicloud/modelarts"
+)
+
+// NewHealthCheck returns the check of whether the credential of ModelArts
+// is valid. It lists the flavors which is the cheapest authorized api.
+func NewHealthCheck(
+	cfg *Config, creds *Credentials, log *logrus.Entry,
+) (func(context.Context) error, error) {
+	cli, err := newClient(cfg, creds.modelarts, log)
+	if err != nil {
+		return nil, err
+	}
+
+	return func(context.Context) error {
+		if t := creds.modelarts.get().ExpiresAt; !t.IsZero() && time.Now().After(t) {
+			return fmt.Errorf("the temporary credential expired at %s", t)
+		}
+
+		_, err := modelarts.ListFlavors(cli.get())
+
+		return err
+	}, nil
+}
@@ -0,0 +1,108 @@
+package trainingimpl
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	obsutilConfigFile    = ".obsutilconfig"
+	obsutilConfigSection = "[Default]"
+)
+
+// obsutilConfigPath returns the config file which obsutil reads by default.
+func obsutilConfigPath() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(home, obsutilConfigFile), nil
+}
+
+// configOBSUtil writes the endpoint and credential to the config file of
+// obsutil directly instead of running "obsutil config", so that they will
+// not appear in the process listings. The other settings are kept.
+func configOBSUtil(endpoint, ak, sk, token string) error {
+	path, err := obsutilConfigPath()
+	if err != nil {
+		return err
+	}
+
+	if err := writeOBSUtilConfig(path, endpoint, ak, sk, token); err != nil {
+		return fmt.Errorf("config obsutil failed, err:%s", err.Error())
+	}
+
+	return nil
+}
+
+func writeOBSUtilConfig(path, endpoint, ak, sk, token string) error {
+	content, err := ioutil.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	v := updateOBSUtilConfig(string(content), map[string]string{
+		"endpoint": endpoint,
+		"ak":       ak,
+		"sk":       sk,
+		"token":    token,
+	})
+
+	// write to a temporary file and rename it, so that obsutil
+	// will never read the partial config.
+	f, err := ioutil.TempFile(filepath.Dir(path), obsutilConfigFile)
+	if err != nil {
+		return err
+	}
+
+	tmp := f.Name()
+	defer os.Remove(tmp)
+
+	_, err = f.WriteString(v)
+	if err1 := f.Close(); err == nil {
+		err = err1
+	}
+
+	if err != nil {
+		return err
+	}
+
+	return os.Rename(tmp, path)
+}
+
+// updateOBSUtilConfig sets the items of config whose lines are like
+// "key=value". The items which are not found are appended. The
+// encrypted credential is cleared, because it takes precedence.
+func updateOBSUtilConfig(content string, items map[string]string) string {
+	cleared := map[string]bool{"akCrypto": true, "skCrypto": true}
+
+	lines := strings.Split(strings.TrimRight(content, "\n"), "\n")
+	if content == "" {
+		lines = []string{obsutilConfigSection}
+	}
+
+	done := make(map[string]bool, len(items))
+
+	for i, line := range lines {
+		k := strings.TrimSpace(strings.SplitN(line, "=", 2)[0])
+
+		if v, ok := items[k]; ok {
+			lines[i] = k + "=" + v
+			done[k] = true
+		} else if cleared[k] {
+			lines[i] = k + "="
+		}
+	}
+
+	for _, k := range []string{"endpoint", "ak", "sk", "token"} {
+		if !done[k] {
+			lines = append(lines, k+"="+items[k])
+		}
+	}
+
+	return strings.Join(lines, "\n") + "\n"
+}
@@ -0,0 +1,84 @@
+package trainingimpl
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestUpdateOBSUtilConfig(t *testing.T) {
+	items := map[string]string{
+		"endpoint": "obs.example.com",
+		"ak":       "ak1",
+		"sk":       "sk1",
+		"token":    "",
+	}
+
+	cases := []struct {
+		name    string
+		content string
+		want    string
+	}{
+		{
+			name: "new file",
+			want: "[Default]\nendpoint=obs.example.com\nak=ak1\nsk=sk1\ntoken=\n",
+		},
+		{
+			name: "the other settings are kept",
+			content: "[Default]\nendpoint=old\nak=ak0\nsk=sk0\n" +
+				"token=tk0\nakCrypto=xx\nskCrypto=yy\nconnectTimeout=30\n",
+			want: "[Default]\nendpoint=obs.example.com\nak=ak1\nsk=sk1\n" +
+				"token=\nakCrypto=\nskCrypto=\nconnectTimeout=30\n",
+		},
+		{
+			name:    "the missing items are appended",
+			content: "[Default]\nconnectTimeout=30",
+			want: "[Default]\nconnectTimeout=30\n" +
+				"endpoint=obs.example.com\nak=ak1\nsk=sk1\ntoken=\n",
+		},
+	}
+
+	for i := range cases {
+		c := &cases[i]
+
+		if v := updateOBSUtilConfig(c.content, items); v != c.want {
+			t.Errorf("case %s: config = %q, want %q", c.name, v, c.want)
+		}
+	}
+}
+
+func TestWriteOBSUtilConfig(t *testing.T) {
+	path := filepath.Join(t.TempDir(), obsutilConfigFile)
+
+	if err := writeOBSUtilConfig(path, "e", "ak1", "sk1", "tk1"); err != nil {
+		t.Fatalf("write failed, err:%v", err)
+	}
+
+	if err := writeOBSUtilConfig(path, "e", "ak2", "sk2", "tk2"); err != nil {
+		t.Fatalf("rewrite failed, err:%v", err)
+	}
+
+	v, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if want := "[Default]\nendpoint=e\nak=ak2\nsk=sk2\ntoken=tk2\n"; string(v) != want {
+		t.Errorf("config = %q, want %q", v, want)
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if m := info.Mode().Perm(); m != 0600 {
+		t.Errorf("mode = %o, want 600", m)
+	}
+
+	// the temporary files are removed.
+	if files, _ := ioutil.ReadDir(filepath.Dir(path)); len(files) != 1 {
+		t.Errorf("%d files are left, want 1", len(files))
+	}
+}
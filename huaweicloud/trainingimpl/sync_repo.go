@@ -1,35 +1,43 @@
 package trainingimpl
 
 import (
+	"bytes"
+	"errors"
 	"fmt"
 	"io/ioutil"
 	"os"
 	"path/filepath"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/huaweicloud/huaweicloud-sdk-go-obs/obs"
-	libutils "github.com/opensourceways/community-robot-lib/utils"
 
 	"github.com/opensourceways/xihe-training-center/domain"
 	"github.com/opensourceways/xihe-training-center/domain/training"
+	"github.com/opensourceways/xihe-training-center/huaweicloud/client"
+	"github.com/opensourceways/xihe-training-center/metrics"
 	"github.com/opensourceways/xihe-training-center/utils"
 )
 
-func newHelper(cfg *Config) (*helper, error) {
+const serviceOBS = "obs"
+
+// newHelper returns the helper whose client of obs
+// is refreshed once the credential is changed.
+func newHelper(cfg *Config, cred *credential) (*helper, error) {
 	obsCfg := &cfg.OBS
-	cli, err := obs.New(obsCfg.AccessKey, obsCfg.SecretKey, obsCfg.Endpoint)
+	suc := &cfg.SyncAndUpload
+
+	v := cred.get()
+	ak, sk, token := v.AccessKey, v.SecretKey, v.SecurityToken
+
+	cli, err := obs.New(ak, sk, obsCfg.Endpoint, obs.WithSecurityToken(token))
 	if err != nil {
 		return nil, fmt.Errorf("new obs client failed, err:%s", err.Error())
 	}
 
-	suc := &cfg.SyncAndUpload
-
-	_, err, _ = libutils.RunCmd(
-		suc.OBSUtilPath, "config",
-		"-i="+obsCfg.AccessKey, "-k="+obsCfg.SecretKey, "-e="+obsCfg.Endpoint,
-	)
-	if err != nil {
-		return nil, fmt.Errorf("obsutil config failed, err:%s", err.Error())
+	if err := configOBSUtil(obsCfg.Endpoint, ak, sk, token); err != nil {
+		return nil, err
 	}
 
 	if err := os.Mkdir(suc.SyncWorkDir, 0755); err != nil {
@@ -40,17 +48,43 @@ func newHelper(cfg *Config) (*helper, error) {
 		return nil, err
 	}
 
-	return &helper{
+	h := &helper{
 		obsClient: cli,
 		bucket:    obsCfg.Bucket,
 		suc:       *suc,
-	}, nil
+	}
+
+	cred.subscribe(func(c *client.Credential) error {
+		cli.Refresh(c.AccessKey, c.SecretKey, c.SecurityToken)
+
+		h.obsutilLock.Lock()
+		defer h.obsutilLock.Unlock()
+
+		return configOBSUtil(
+			obsCfg.Endpoint, c.AccessKey, c.SecretKey, c.SecurityToken,
+		)
+	})
+
+	return h, nil
 }
 
 type helper struct {
 	obsClient *obs.ObsClient
 	bucket    string
 	suc       SyncAndUploadConfig
+
+	// obsutilLock makes the config of obsutil be rewritten only when
+	// there is no running script which uses obsutil, so that a script
+	// won't run with the mixed credentials.
+	obsutilLock sync.RWMutex
+}
+
+// runOBSUtilScript runs the script which uses obsutil.
+func (s *helper) runOBSUtilScript(env []string, params ...string) ([]byte, error) {
+	s.obsutilLock.RLock()
+	defer s.obsutilLock.RUnlock()
+
+	return utils.RunCmdWithEnv(env, params...)
 }
 
 func (s *helper) GetRepoSyncedCommit(i *domain.ResourceRef) (
@@ -70,7 +104,70 @@ func (s *helper) GetRepoSyncedCommit(i *domain.ResourceRef) (
 	return
 }
 
-func (s *helper) getObject(path string) ([]byte, error) {
+// inputSnapshotDir returns the directory where the snapshots of resource are saved.
+func (s *helper) inputSnapshotDir(i *domain.ResourceRef) string {
+	return filepath.Join(
+		s.suc.InputSnapshotPath, i.User.Account(),
+		i.Type.ResourceType(), i.RepoId,
+	)
+}
+
+// inputPath returns the obs path of input in the snapshot of its commit.
+func (s *helper) inputPath(i *domain.ResourceRef) string {
+	p := filepath.Join(s.inputSnapshotDir(i), i.Commit, i.File)
+	if i.File == "" {
+		// make sure the path is a directory for object storage service.
+		return p + "/"
+	}
+
+	return p
+}
+
+func (s *helper) SnapshotInput(i *domain.ResourceRef) error {
+	cfg := &s.suc
+	dir := s.inputSnapshotDir(i)
+
+	synced := false
+	err := utils.Retry(func() error {
+		v, err := s.getObject(filepath.Join(dir, i.Commit+"_"+cfg.CommitFile))
+		if err == nil {
+			synced = string(v) == i.Commit
+		}
+
+		return err
+	})
+	if err != nil || synced {
+		return err
+	}
+
+	tempDir, err := ioutil.TempDir(cfg.SyncWorkDir, "snapshot")
+	if err != nil {
+		return err
+	}
+
+	defer os.RemoveAll(tempDir)
+
+	params := []string{
+		cfg.SnapshotInputShell, tempDir,
+		cfg.OBSUtilPath, s.bucket,
+		filepath.Join(cfg.RepoPath, i.User.Account(), i.Type.ResourceType(), i.RepoId),
+		filepath.Join(cfg.RepoPath, i.ToPath(), cfg.CommitFile),
+		dir, cfg.CommitFile, i.Commit,
+	}
+
+	if v, err := s.runOBSUtilScript(nil, params...); err != nil {
+		return fmt.Errorf(
+			"run snapshot shell, err=%s, params=%v, output=%s",
+			err.Error(), params, string(v),
+		)
+	}
+
+	return nil
+}
+
+func (s *helper) getObject(path string) (_ []byte, err error) {
+	defer metrics.ObserveCall(serviceOBS, "get_object", time.Now(), &err)
+
 	input := &obs.GetObjectInput{}
 	input.Bucket = s.bucket
 	input.Key = path
@@ -92,6 +189,62 @@ func (s *helper) getObject(path string) ([]byte, error) {
 	return v, err
 }
 
+func (s *helper) projectSnapshotDir(owner domain.Account, repoId string) string {
+	return filepath.Join(
+		s.suc.RepoPath, owner.Account(),
+		domain.ResourceTypeProject.ResourceType(), repoId,
+		s.suc.SnapshotDir,
+	)
+}
+
+func (s *helper) projectSnapshotPath(owner domain.Account, repoId, commit string) string {
+	return filepath.Join(s.projectSnapshotDir(owner, repoId), commit)
+}
+
+// projectPartialSnapshotDir returns the directory where the snapshots of
+// codeDir are saved. Each snapshot keeps the same layout as the repo, and
+// the commit file under this directory records the latest synced commit.
+func (s *helper) projectPartialSnapshotDir(owner domain.Account, repoId, codeDir string) string {
+	return filepath.Join(
+		s.suc.RepoPath, owner.Account(),
+		domain.ResourceTypeProject.ResourceType(), repoId,
+		s.suc.PartialSnapshotDir, utils.GenMD5([]byte(codeDir)),
+	)
+}
+
+func (s *helper) projectPartialSnapshotPath(
+	owner domain.Account, repoId, codeDir, commit string,
+) string {
+	return filepath.Join(s.projectPartialSnapshotDir(owner, repoId, codeDir), commit)
+}
+
+// snapshotDirOf returns the snapshot directory of repo and the code dir to sync.
+// The code dir is empty if the whole repo is synced.
+func (s *helper) snapshotDirOf(repo *training.ProjectInfo) (string, string) {
+	codeDir := strings.Trim(repo.CodeDir, "/")
+	if codeDir == "" {
+		return s.projectSnapshotDir(repo.Owner, repo.RepoId), ""
+	}
+
+	return s.projectPartialSnapshotDir(repo.Owner, repo.RepoId, codeDir), codeDir
+}
+
+func (s *helper) IsProjectSynced(repo *training.ProjectInfo) (synced bool, err error) {
+	dir, _ := s.snapshotDirOf(repo)
+	p := filepath.Join(dir, repo.Commit, s.suc.CommitFile)
+
+	err = utils.Retry(func() error {
+		v, err := s.getObject(p)
+		if err == nil {
+			synced = string(v) == repo.Commit
+		}
+
+		return err
+	})
+
+	return
+}
+
 func (s *helper) SyncProject(repo *training.ProjectInfo) (lastCommit string, err error) {
 	cfg := &s.suc
 
@@ -102,30 +255,125 @@ func (s *helper) SyncProject(repo *training.ProjectInfo) (lastCommit string, err
 
 	defer os.RemoveAll(tempDir)
 
-	obsRepoPath := filepath.Join(
-		cfg.RepoPath,
-		repo.Owner.Account(),
-		domain.ResourceTypeProject.ResourceType(), repo.RepoId,
-	)
+	snapshotDir, codeDir := s.snapshotDirOf(repo)
+
+	startCommit := repo.StartCommit
+	if codeDir != "" {
+		// the partial snapshot is based on the latest one of the same directory.
+		var v []byte
+		if v, err = s.getObject(filepath.Join(snapshotDir, cfg.CommitFile)); err != nil {
+			return
+		}
+
+		startCommit = string(v)
+	}
 
 	params := []string{
 		cfg.SyncFileShell, tempDir,
 		repo.RepoURL, repo.Name.ProjectName(),
-		cfg.OBSUtilPath, s.bucket, obsRepoPath,
-		repo.StartCommit,
+		cfg.OBSUtilPath, s.bucket, snapshotDir,
+		cfg.CommitFile, repo.Commit,
+		repo.Ref, startCommit, codeDir,
 	}
 
-	v, err, _ := libutils.RunCmd(params...)
+	// the credential is supplied to git by environment variables,
+	// so that it will not appear in the process listings.
+	credential := &repo.RepoCredential
+	env := utils.GitCredentialEnv(credential.Username, credential.Password)
+
+	v, err := s.runOBSUtilScript(env, params...)
 	if err != nil {
-		err = fmt.Errorf(
-			"run sync shell, err=%s, params=%v",
-			err.Error(), params,
-		)
+		err = errors.New(utils.Redact(
+			fmt.Sprintf(
+				"run sync shell, err=%s, params=%v, output=%s",
+				err.Error(), params, string(v),
+			),
+			credential.Password,
+		))
 
 		return
 	}
 
-	lastCommit = strings.TrimSuffix(string(v), "\n")
+	// the commit is the last line of output, and the ones before it
+	// may be the warnings of git, such as the filter is not supported.
+	lines := strings.Split(strings.TrimSpace(string(v)), "\n")
+	lastCommit = strings.TrimSpace(lines[len(lines)-1])
+
+	return
+}
+
+// codePath returns the obs path of code dir of training.
+func (s *helper) codePath(t *domain.UserTraining) string {
+	snapshot := s.projectSnapshotPath(t.User, t.ProjectRepoId, t.ProjectCommit)
+	if codeDir := strings.Trim(t.CodeDir.Directory(), "/"); t.SyncCodeDirOnly && codeDir != "" {
+		snapshot = s.projectPartialSnapshotPath(
+			t.User, t.ProjectRepoId, codeDir, t.ProjectCommit,
+		)
+	}
+
+	return filepath.Join(snapshot, t.CodeDir.Directory())
+}
+
+func (s *helper) IsCodeDirEmpty(t *domain.UserTraining) (empty bool, err error) {
+	defer metrics.ObserveCall(serviceOBS, "list_objects", time.Now(), &err)
+
+	input := &obs.ListObjectsInput{}
+	input.Bucket = s.bucket
+	input.Prefix = s.codePath(t) + "/"
+	input.MaxKeys = 1
+
+	err = utils.Retry(func() error {
+		v, err := s.obsClient.ListObjects(input)
+		if err == nil {
+			empty = len(v.Contents) == 0
+		}
+
+		return err
+	})
 
 	return
 }
+
+func (s *helper) IsBootFileExist(t *domain.UserTraining) (bool, error) {
+	return s.isObjectExist(filepath.Join(s.codePath(t), t.BootFile.FilePath()))
+}
+
+func (s *helper) isObjectExist(path string) (exist bool, err error) {
+	defer metrics.ObserveCall(serviceOBS, "get_object_metadata", time.Now(), &err)
+
+	input := &obs.GetObjectMetadataInput{}
+	input.Bucket = s.bucket
+	input.Key = path
+
+	err = utils.Retry(func() error {
+		_, err := s.obsClient.GetObjectMetadata(input)
+		if err == nil {
+			exist = true
+
+			return nil
+		}
+
+		if v, ok := err.(obs.ObsError); ok && v.BaseModel.StatusCode == 404 {
+			return nil
+		}
+
+		return err
+	})
+
+	return
+}
+
+func (s *helper) putObject(path string, content []byte) (err error) {
+	defer metrics.ObserveCall(serviceOBS, "put_object", time.Now(), &err)
+
+	return utils.Retry(func() error {
+		input := &obs.PutObjectInput{}
+		input.Bucket = s.bucket
+		input.Key = path
+		input.Body = bytes.NewReader(content)
+
+		_, err := s.obsClient.PutObject(input)
+
+		return err
+	})
+}
@@ -0,0 +1,53 @@
+#!/bin/bash
+
+set -eu
+
+work_dir=$1
+obsutil=$2 # the path of obsutil
+bucket=$3
+src_dir=$4 # the object path of directory where the resource is synced to.
+src_commit_file=$5 # the object path of file which records the synced commit.
+snapshot_dir=$6 # the object path of directory which saves the snapshots of each commit.
+commit_file=$7
+commit=$8
+
+src_dir=${src_dir%/}
+snapshot_dir=${snapshot_dir%/}
+obspath="obs://$bucket/$snapshot_dir/$commit/"
+
+test -d $work_dir || mkdir -p $work_dir
+cd $work_dir
+
+# synced_commit prints the commit which the resource is synced to.
+synced_commit() {
+    rm -f ./synced_commit
+
+    $obsutil cp "obs://$bucket/$src_commit_file" ./synced_commit > /dev/null 2>&1 || true
+
+    cat ./synced_commit 2>/dev/null || true
+}
+
+if [ "$(synced_commit)" != "$commit" ]; then
+    echo "the resource is not synced to $commit"
+
+    exit 1
+fi
+
+$obsutil cp "obs://$bucket/$src_dir/" $obspath -r -f -flat > /dev/null 2>&1
+
+# the resource may be synced to another commit while copying it,
+# and then the snapshot may include the files of both commits.
+if [ "$(synced_commit)" != "$commit" ]; then
+    $obsutil rm $obspath -r -f > /dev/null 2>&1 || true
+
+    echo "the resource is synced to another commit while saving the snapshot"
+
+    exit 1
+fi
+
+# the commit file is saved beside the snapshot rather than in it, so that
+# it won't be overwritten by the one of resource. It means the snapshot
+# is complete.
+echo -n "$commit" > ./$commit_file
+
+$obsutil cp ./$commit_file "obs://$bucket/$snapshot_dir/${commit}_$commit_file" > /dev/null 2>&1
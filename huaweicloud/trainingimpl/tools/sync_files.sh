@@ -12,17 +12,25 @@ work_dir=$1
 repo_url=$2
 repo_name=$3
 obsutil=$4 # the path of obsutil
-obspath="obs://$5/$6" # obspath should has suffix of /. $5 is obs bucket. $6 is the object path.
+bucket=$5
+snapshot_dir=$6 # the object path of directory which saves the snapshots of each commit.
+commit_file=$7
+commit=$8
+ref="" # the branch or tag which commit belongs to, it may be empty
+if [ $# -ge 9 ]; then
+    ref=$9
+fi
 start_commit="" # start_commit may be empty
-if [ $# -eq 7 ]; then
-    start_commit=$7
+if [ $# -ge 10 ]; then
+    start_commit=${10}
+fi
+code_dir="" # only sync this directory of repo if it is not empty
+if [ $# -ge 11 ]; then
+    code_dir=${11}
 fi
 
-v=0
-case $obspath in */)
-    v=1
-esac
-test $v -eq 0 && obspath="${obspath}/"
+snapshot_dir=${snapshot_dir%/}
+obspath="obs://$bucket/$snapshot_dir/$commit/"
 
 test -d $work_dir || mkdir -p $work_dir
 cd $work_dir
@@ -30,28 +38,80 @@ cd $work_dir
 # work_dir can't has suffix of / and must be an absolute path
 work_dir=$(pwd)
 
-git clone -q $repo_url
-cd $repo_name
+# repo_url doesn't include the credential, it is supplied to git
+# by the credential helper set in the environment variables.
+clone_opts="-q"
+if [ -n "$ref" ]; then
+    clone_opts="$clone_opts --branch $ref"
+fi
+
+if [ -n "$code_dir" ]; then
+    # fetch the blobs of code_dir only if the server supports the filter.
+    git clone $clone_opts --no-checkout --filter=blob:none $repo_url
+    cd $repo_name
+
+    git config core.sparseCheckout true
+    echo "/$code_dir/" > .git/info/sparse-checkout
+else
+    git clone $clone_opts $repo_url
+    cd $repo_name
+fi
+
+git checkout -q $commit
 
 last_commit=$(git log --format="%H" -n 1)
 file_prefix=$work_dir/$last_commit
 
+# finish writes the commit file which means the snapshot is complete.
+finish() {
+    echo -n "$last_commit" > $work_dir/$commit_file
+
+    $obsutil cp $work_dir/$commit_file ${obspath}$commit_file > /dev/null 2>&1
+
+    # record the latest partial snapshot which the next one is based on.
+    if [ -n "$code_dir" ]; then
+        $obsutil cp $work_dir/$commit_file "obs://$bucket/$snapshot_dir/$commit_file" > /dev/null 2>&1
+    fi
+
+    echo_message "$last_commit"
+
+    exit 0
+}
+
+# the snapshot of start commit is the base of the new snapshot.
+# it can be used only if it is complete and exists in the repo.
+if [ -n "$start_commit" ]; then
+    set +e
+
+    git cat-file -e "${start_commit}^{commit}" > /dev/null 2>&1
+    test $? -eq 0 || start_commit=""
+
+    if [ -n "$start_commit" ]; then
+        $obsutil stat "obs://$bucket/$snapshot_dir/$start_commit/$commit_file" > /dev/null 2>&1
+        test $? -eq 0 || start_commit=""
+    fi
+
+    set -e
+fi
+
+if [ -n "$start_commit" ] && [ "$start_commit" != "$last_commit" ]; then
+    $obsutil cp "obs://$bucket/$snapshot_dir/$start_commit/" $obspath -r -f -flat > /dev/null 2>&1
+fi
+
 all_files=${file_prefix}_files
 if [ -z "$start_commit" ]; then
     rm .git -fr
 
-    find . -type f > $all_files
+    find ./$code_dir -type f > $all_files
     sed -i 's/^\.\///' $all_files
 else
-    git diff $start_commit..$last_commit --name-only > $all_files
+    git diff $start_commit..$last_commit --name-only -- ./$code_dir > $all_files
 
     rm .git -fr
 fi
 
 if [ ! -s $all_files ]; then
-    echo_message "$last_commit"
-
-    exit 0
+    finish
 fi
 
 lfs_files=${file_prefix}_lfs
@@ -151,4 +211,6 @@ if [ -s $deleted_files ]; then
     done < $deleted_files
 fi
 
-echo_message "$last_commit"
+set -e
+
+finish
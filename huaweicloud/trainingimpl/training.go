@@ -5,9 +5,11 @@ import (
 	"path/filepath"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/chnsz/golangsdk"
+	"github.com/sirupsen/logrus"
 
 	"github.com/opensourceways/xihe-training-center/domain"
 	"github.com/opensourceways/xihe-training-center/domain/training"
@@ -15,7 +17,10 @@ import (
 	"github.com/opensourceways/xihe-training-center/huaweicloud/modelarts"
 )
 
-const obsPrefix = "obs://"
+const (
+	obsPrefix        = "obs://"
+	modelartsService = "modelarts"
+)
 
 var statusMap = map[string]domain.TrainingStatus{
 	"failed":      domain.TrainingStatusFailed,
@@ -28,32 +33,13 @@ var statusMap = map[string]domain.TrainingStatus{
 	"terminating": domain.TrainingStatusTerminated,
 }
 
-func NewTraining(cfg *Config) (training.Training, error) {
-	s := "modelarts"
-	mc := &cfg.Modelarts
-	v := client.Config{
-		AccessKey:  mc.AccessKey,
-		SecretKey:  mc.SecretKey,
-		TenantName: mc.ProjectName,
-		TenantID:   mc.ProjectId,
-		Region:     mc.Region,
-		Endpoints: map[string]string{
-			s: mc.Endpoint,
-		},
-		IdentityEndpoint: fmt.Sprintf("https://iam.%s.myhuaweicloud.com:443/v3", mc.Region),
-	}
-	if err := v.LoadAndValidate(); err != nil {
-		return nil, err
-	}
-
-	cli, err := v.NewServiceClient(s, client.ServiceCatalog{
-		Version: "v2",
-	})
+func NewTraining(cfg *Config, creds *Credentials, log *logrus.Entry) (training.Training, error) {
+	cli, err := newClient(cfg, creds.modelarts, log)
 	if err != nil {
 		return nil, err
 	}
 
-	h, err := newHelper(cfg)
+	h, err := newHelper(cfg, creds.obs)
 	if err != nil {
 		return nil, err
 	}
@@ -61,21 +47,107 @@ func NewTraining(cfg *Config) (training.Training, error) {
 	return trainingImpl{
 		cli:         cli,
 		config:      cfg.Train,
+		dependency:  cfg.Dependency,
 		helper:      h,
 		obsRepoPath: filepath.Join(cfg.OBS.Bucket, cfg.SyncAndUpload.RepoPath),
 	}, nil
 }
 
+// newClient returns the client of ModelArts. It is rebuilt
+// once the credential is changed.
+func newClient(cfg *Config, cred *credential, log *logrus.Entry) (*serviceClient, error) {
+	v := cred.get()
+	cli, err := buildClient(&cfg.Modelarts, &v, log)
+	if err != nil {
+		return nil, err
+	}
+
+	sc := &serviceClient{cli: cli}
+
+	cred.subscribe(func(c *client.Credential) error {
+		cli, err := buildClient(&cfg.Modelarts, c, log)
+		if err == nil {
+			sc.set(cli)
+		}
+
+		return err
+	})
+
+	return sc, nil
+}
+
+func buildClient(
+	mc *ModelartsConfig, cred *client.Credential, log *logrus.Entry,
+) (*golangsdk.ServiceClient, error) {
+	v := genClientConfig(mc, cred, log)
+	if err := v.LoadAndValidate(); err != nil {
+		return nil, err
+	}
+
+	return v.NewServiceClient(modelartsService, client.ServiceCatalog{
+		Version: "v2",
+	})
+}
+
+// genClientConfig returns the config of client which uses
+// the credential instead of the AK/SK of config if it is not nil.
+func genClientConfig(
+	mc *ModelartsConfig, cred *client.Credential, log *logrus.Entry,
+) *client.Config {
+	v := &client.Config{
+		AccessKey:  mc.AccessKey,
+		SecretKey:  mc.SecretKey,
+		TenantName: mc.ProjectName,
+		TenantID:   mc.ProjectId,
+		Region:     mc.Region,
+		Endpoints: map[string]string{
+			modelartsService: mc.Endpoint,
+		},
+		IdentityEndpoint: fmt.Sprintf("https://iam.%s.myhuaweicloud.com:443/v3", mc.Region),
+		Log:              log,
+		LogConfig:        &mc.Log,
+	}
+
+	if cred != nil {
+		v.AccessKey = cred.AccessKey
+		v.SecretKey = cred.SecretKey
+		v.SecurityToken = cred.SecurityToken
+		v.SecurityKeyExpiresAt = cred.ExpiresAt
+	}
+
+	return v
+}
+
+type serviceClient struct {
+	mu  sync.RWMutex
+	cli *golangsdk.ServiceClient
+}
+
+func (c *serviceClient) get() *golangsdk.ServiceClient {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return c.cli
+}
+
+func (c *serviceClient) set(cli *golangsdk.ServiceClient) {
+	c.mu.Lock()
+	c.cli = cli
+	c.mu.Unlock()
+}
+
 type trainingImpl struct {
-	cli         *golangsdk.ServiceClient
+	cli         *serviceClient
 	config      TrainingConfig
+	dependency  DependencyConfig
 	obsRepoPath string
 
 	*helper
 }
 
 func (impl trainingImpl) genJobParameter(t *domain.UserTraining, opt *modelarts.JobCreateOption) {
-	if n := len(t.Hypeparameters); n > 0 {
+	// the hyperparameters are passed by the generated boot file in other modes.
+	if n := len(t.Hypeparameters); n > 0 && t.ParameterRender.IsFlags() {
 		p := make([]modelarts.ParameterOption, n)
 
 		for i, v := range t.Hypeparameters {
@@ -117,6 +189,7 @@ func (impl trainingImpl) Create(t *domain.UserTraining) (info domain.JobInfo, er
 
 	cfg := &impl.config
 	obs := filepath.Join(impl.obsRepoPath, t.ToPath())
+	code := filepath.Join(impl.bucket, impl.codePath(t))
 	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
 
 	info.LogDir = filepath.Join(obs, cfg.LogDir, timestamp) + "/"
@@ -130,12 +203,7 @@ func (impl trainingImpl) Create(t *domain.UserTraining) (info domain.JobInfo, er
 			Desc: desc,
 		},
 		Algorithm: modelarts.AlgorithmOption{
-			CodeDir:  obsPrefix + filepath.Join(obs, t.CodeDir.Directory()) + "/",
-			BootFile: obsPrefix + filepath.Join(obs, t.CodeDir.Directory(), t.BootFile.FilePath()),
-			Engine: modelarts.EngineOption{
-				EngineName:    t.Compute.Type.ComputeType(),
-				EngineVersion: t.Compute.Version.ComputeVersion(),
-			},
+			CodeDir: obsPrefix + code + "/",
 			Outputs: []modelarts.InputOutputOption{
 				{
 					Name: cfg.OutputKey,
@@ -170,13 +238,87 @@ func (impl trainingImpl) Create(t *domain.UserTraining) (info domain.JobInfo, er
 		opt.Algorithm.Inputs = impl.genInputOption(t.Inputs)
 	}
 
+	// the generated files can't be saved in the snapshot of code
+	// which is shared by the trainings of the same commit.
+	bootDir := filepath.Join(
+		impl.suc.RepoPath, t.ToPath(), cfg.BootDir, timestamp,
+	)
+
+	if err = impl.genEngine(t, code, bootDir, &opt.Algorithm); err != nil {
+		return
+	}
+
 	impl.genJobParameter(t, &opt)
 
-	info.JobId, err = modelarts.CreateJob(impl.cli, opt)
+	info.JobId, err = modelarts.CreateJob(impl.cli.get(), opt)
 
 	return
 }
 
+func (impl trainingImpl) genEngine(
+	t *domain.UserTraining, code, bootDir string, opt *modelarts.AlgorithmOption,
+) error {
+	image := t.Compute.Image
+	if image == nil {
+		boot, err := impl.genBootFile(t, bootDir)
+		if err != nil {
+			return err
+		}
+
+		opt.Engine = modelarts.EngineOption{
+			EngineName:    t.Compute.Type.ComputeType(),
+			EngineVersion: t.Compute.Version.ComputeVersion(),
+		}
+
+		if boot == "" {
+			opt.BootFile = obsPrefix + filepath.Join(code, t.BootFile.FilePath())
+
+			return nil
+		}
+
+		// the boot file must be under the code dir, so the code dir is
+		// replaced by the one of boot file, and the snapshot of code is
+		// downloaded as an input.
+		for i := range opt.Inputs {
+			if opt.Inputs[i].Name == codeInputKey {
+				return fmt.Errorf("the input key: %s is reserved", codeInputKey)
+			}
+		}
+
+		dir := filepath.Join(impl.bucket, bootDir)
+
+		opt.CodeDir = obsPrefix + dir + "/"
+		opt.BootFile = obsPrefix + filepath.Join(dir, boot)
+		opt.Inputs = append(opt.Inputs, modelarts.InputOutputOption{
+			Name: codeInputKey,
+			Remote: modelarts.RemoteOption{
+				OBS: modelarts.OBSOption{
+					OBSURL: obsPrefix + code + "/",
+				},
+			},
+		})
+
+		return nil
+	}
+
+	// the code dir is downloaded to the directory with the same name
+	// under the local code dir.
+	local := impl.config.LocalCodeDir
+
+	opt.Command = image.Command.ImageCommand()
+	opt.LocalCodeDir = local
+	opt.WorkingDir = filepath.Join(local, filepath.Base(code), image.WorkDir.Directory())
+	opt.Engine = modelarts.EngineOption{
+		ImageURL: image.URL.ImageURL(),
+	}
+
+	if image.UserId > 0 {
+		opt.Engine.RunUser = strconv.Itoa(image.UserId)
+	}
+
+	return nil
+}
+
 func (impl trainingImpl) genInputOption(kv []domain.Input) []modelarts.InputOutputOption {
 	r := make([]modelarts.InputOutputOption, len(kv))
 
@@ -185,8 +327,9 @@ func (impl trainingImpl) genInputOption(kv []domain.Input) []modelarts.InputOutp
 			Name: v.Key.CustomizedKey(),
 			Remote: modelarts.RemoteOption{
 				OBS: modelarts.OBSOption{
-					// v.Value maybe a directory.
-					OBSURL: obsPrefix + impl.obsRepoPath + "/" + v.ToPath(),
+					// the input is read from the snapshot of its
+					// commit which won't be changed by the later syncs.
+					OBSURL: obsPrefix + impl.bucket + "/" + impl.inputPath(&v.ResourceRef),
 				},
 			},
 		}
@@ -196,11 +339,11 @@ func (impl trainingImpl) genInputOption(kv []domain.Input) []modelarts.InputOutp
 }
 
 func (impl trainingImpl) Delete(jobId string) error {
-	return modelarts.DeleteJob(impl.cli, jobId)
+	return modelarts.DeleteJob(impl.cli.get(), jobId)
 }
 
 func (impl trainingImpl) GetDetail(jobId string) (r domain.JobDetail, err error) {
-	v, err := modelarts.GetJob(impl.cli, jobId)
+	v, err := modelarts.GetJob(impl.cli.get(), jobId)
 	if err != nil {
 		return
 	}
@@ -217,9 +360,9 @@ func (impl trainingImpl) GetDetail(jobId string) (r domain.JobDetail, err error)
 }
 
 func (impl trainingImpl) Terminate(jobId string) error {
-	return modelarts.TerminateJob(impl.cli, jobId)
+	return modelarts.TerminateJob(impl.cli.get(), jobId)
 }
 
 func (impl trainingImpl) GetLogDownloadURL(jobId string) (string, error) {
-	return modelarts.GetLogDownloadURL(impl.cli, jobId)
+	return modelarts.GetLogDownloadURL(impl.cli.get(), jobId)
 }
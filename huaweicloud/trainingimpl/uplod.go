@@ -5,12 +5,16 @@ import (
 	"io/ioutil"
 	"os"
 	"strings"
+	"time"
 
 	"github.com/huaweicloud/huaweicloud-sdk-go-obs/obs"
-	libutils "github.com/opensourceways/community-robot-lib/utils"
+
+	"github.com/opensourceways/xihe-training-center/metrics"
 )
 
 func (s *helper) GetLogFilePath(logDir string) (p string, err error) {
+	defer metrics.ObserveCall(serviceOBS, "list_objects", time.Now(), &err)
+
 	if !strings.HasSuffix(logDir, "/") {
 		logDir += "/"
 	}
@@ -32,11 +36,21 @@ func (s *helper) GetLogFilePath(logDir string) (p string, err error) {
 }
 
 func (s *helper) GenOutput(outputDir string) (string, error) {
-	return s.uploadFolder(outputDir)
+	return s.packageFolder("output", outputDir)
 }
 
 func (s *helper) GenAim(aimDir string) (string, error) {
-	return s.uploadFolder(aimDir)
+	return s.packageFolder("aim", aimDir)
+}
+
+func (s *helper) packageFolder(kind, obsPath string) (string, error) {
+	start := time.Now()
+
+	v, err := s.uploadFolder(obsPath)
+
+	metrics.ObservePackaging(kind, start, err)
+
+	return v, err
 }
 
 func (s *helper) uploadFolder(obsPath string) (string, error) {
@@ -56,7 +70,7 @@ func (s *helper) uploadFolder(obsPath string) (string, error) {
 		s.suc.OBSUtilPath, s.bucket, obsPath,
 	}
 
-	v, err, _ := libutils.RunCmd(params...)
+	v, err := s.runOBSUtilScript(nil, params...)
 	if err != nil {
 		err = fmt.Errorf(
 			"run upload folder shell, err=%s, params=%v",
@@ -0,0 +1,70 @@
+// Package gormdb implements the mappers of the relational databases
+// supported by gorm. The drivers only differ in how they open the
+// database and how they report the violation of unique constraint.
+package gormdb
+
+import (
+	"context"
+	"io/fs"
+
+	"gorm.io/gorm"
+
+	"github.com/opensourceways/xihe-training-center/infrastructure/migration"
+)
+
+const migrationDir = "migrations"
+
+// Tables is the names of tables which are configurable.
+type Tables struct {
+	ProjectTableName  string
+	SecretTableName   string
+	JobOwnerTableName string
+}
+
+// DB is the database which the mappers share.
+type DB struct {
+	db     *gorm.DB
+	tables Tables
+
+	// isUniqueViolation reports whether the error is caused by
+	// the violation of unique constraint. It is driver specific.
+	isUniqueViolation func(error) bool
+}
+
+func New(db *gorm.DB, tables Tables, isUniqueViolation func(error) bool) *DB {
+	return &DB{
+		db:                db,
+		tables:            tables,
+		isUniqueViolation: isUniqueViolation,
+	}
+}
+
+// Ping checks the connection to the database.
+func (d *DB) Ping(ctx context.Context) error {
+	sqlDB, err := d.db.DB()
+	if err != nil {
+		return err
+	}
+
+	return sqlDB.PingContext(ctx)
+}
+
+// Migrate applies the migrations under the "migrations" directory of
+// fsys and returns the names of migrations applied this time.
+// The table names can be used in the migrations, such as
+// {{.ProjectTableName}}.
+func (d *DB) Migrate(fsys fs.FS, lock migration.Lock) ([]string, error) {
+	ms, err := migration.Load(fsys, migrationDir, d.tables)
+	if err != nil {
+		return nil, err
+	}
+
+	v, err := migration.Migrate(d.db, ms, lock)
+
+	r := make([]string, len(v))
+	for i := range v {
+		r[i] = v[i].Name
+	}
+
+	return r, err
+}
@@ -0,0 +1,77 @@
+package gormdb
+
+import (
+	"encoding/json"
+	"errors"
+
+	"gorm.io/gorm"
+
+	"github.com/opensourceways/xihe-training-center/infrastructure/jobownerimpl"
+)
+
+func (d *DB) NewJobOwnerMapper() jobownerimpl.JobOwnerMapper {
+	return jobOwnerMapper{d}
+}
+
+type jobOwnerMapper struct {
+	*DB
+}
+
+func (m jobOwnerMapper) table() *gorm.DB {
+	return m.db.Table(m.tables.JobOwnerTableName)
+}
+
+func (m jobOwnerMapper) Insert(do *jobownerimpl.JobOwnerDO) error {
+	data := TrainingJobOwner{
+		JobId:         do.JobId,
+		Owner:         do.Owner,
+		ProjectCommit: do.ProjectCommit,
+		CreatedAt:     do.CreatedAt,
+	}
+
+	if len(do.Inputs) > 0 {
+		v, err := json.Marshal(do.Inputs)
+		if err != nil {
+			return err
+		}
+
+		data.Inputs = string(v)
+	}
+
+	return m.table().Create(&data).Error
+}
+
+func (m jobOwnerMapper) Get(jobId string) (do jobownerimpl.JobOwnerDO, err error) {
+	data := new(TrainingJobOwner)
+
+	err = m.table().Where(
+		map[string]interface{}{fieldJobId: jobId},
+	).First(data).Error
+
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			err = jobownerimpl.NewErrorDataNotExists(err)
+		}
+
+		return
+	}
+
+	do = jobownerimpl.JobOwnerDO{
+		JobId:         data.JobId,
+		Owner:         data.Owner,
+		ProjectCommit: data.ProjectCommit,
+		CreatedAt:     data.CreatedAt,
+	}
+
+	if data.Inputs != "" {
+		err = json.Unmarshal([]byte(data.Inputs), &do.Inputs)
+	}
+
+	return
+}
+
+func (m jobOwnerMapper) Delete(jobId string) error {
+	return m.table().Where(
+		map[string]interface{}{fieldJobId: jobId},
+	).Delete(&TrainingJobOwner{}).Error
+}
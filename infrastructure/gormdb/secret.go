@@ -0,0 +1,105 @@
+package gormdb
+
+import (
+	"errors"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/opensourceways/xihe-training-center/infrastructure/secretimpl"
+)
+
+func (d *DB) NewSecretMapper() secretimpl.SecretMapper {
+	return secretMapper{d}
+}
+
+type secretMapper struct {
+	*DB
+}
+
+func (m secretMapper) table() *gorm.DB {
+	return m.db.Table(m.tables.SecretTableName)
+}
+
+func (m secretMapper) Upsert(do *secretimpl.SecretDO) error {
+	data := TrainingSecret{
+		Owner:     do.Owner,
+		Name:      do.Name,
+		Value:     do.Value,
+		UpdatedAt: do.UpdatedAt,
+	}
+
+	return m.table().Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: fieldOwner}, {Name: fieldName}},
+		DoUpdates: clause.AssignmentColumns([]string{fieldValue, fieldUpdatedAt}),
+	}).Create(&data).Error
+}
+
+func (m secretMapper) Get(owner, name string) (do secretimpl.SecretDO, err error) {
+	cond := map[string]interface{}{
+		fieldOwner: owner,
+		fieldName:  name,
+	}
+
+	data := new(TrainingSecret)
+
+	err = m.table().Where(cond).First(data).Error
+
+	if err == nil {
+		do = m.toSecretDO(data)
+	} else {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			err = secretimpl.NewErrorDataNotExists(err)
+		}
+	}
+
+	return
+}
+
+func (m secretMapper) List(owner string) ([]secretimpl.SecretDO, error) {
+	var data []TrainingSecret
+
+	err := m.table().Where(
+		map[string]interface{}{fieldOwner: owner},
+	).Order(fieldName).Find(&data).Error
+	if err != nil {
+		return nil, err
+	}
+
+	r := make([]secretimpl.SecretDO, len(data))
+	for i := range data {
+		r[i] = m.toSecretDO(&data[i])
+		r[i].Value = ""
+	}
+
+	return r, nil
+}
+
+func (m secretMapper) Delete(owner, name string) error {
+	cond := map[string]interface{}{
+		fieldOwner: owner,
+		fieldName:  name,
+	}
+
+	tx := m.table().Where(cond).Delete(&TrainingSecret{})
+	if tx.Error != nil {
+		return tx.Error
+	}
+
+	if tx.RowsAffected == 0 {
+		return secretimpl.NewErrorDataNotExists(
+			errors.New("no matched record"),
+		)
+	}
+
+	return nil
+}
+
+func (m secretMapper) toSecretDO(data *TrainingSecret) secretimpl.SecretDO {
+	return secretimpl.SecretDO{
+		Owner:     data.Owner,
+		Name:      data.Name,
+		Value:     data.Value,
+		UpdatedAt: data.UpdatedAt,
+	}
+}
@@ -1,4 +1,4 @@
-package mysql
+package gormdb
 
 import (
 	"errors"
@@ -9,38 +9,44 @@ import (
 	"github.com/opensourceways/xihe-training-center/infrastructure/synclockimpl"
 )
 
-func NewSyncLockMapper() synclockimpl.SyncLockMapper {
-	return syncLock{}
+func (d *DB) NewSyncLockMapper() synclockimpl.SyncLockMapper {
+	return syncLock{d}
 }
 
-type syncLock struct{}
+type syncLock struct {
+	*DB
+}
+
+func (rs syncLock) table() *gorm.DB {
+	return rs.db.Table(rs.tables.ProjectTableName)
+}
 
 func (rs syncLock) Insert(do *synclockimpl.RepoSyncLockDO) (string, error) {
 	table := rs.toSyncLockTable(do)
 
-	r := cli.db.Model(&table).Create(&table)
-	if r.Error != nil {
-		return "", r.Error
-	}
+	if err := rs.table().Create(&table).Error; err != nil {
+		if rs.isUniqueViolation(err) {
+			err = synclockimpl.NewErrorDuplicateCreating(err)
+		}
 
-	if r.RowsAffected == 0 {
-		return "", synclockimpl.NewErrorDuplicateCreating(
-			errors.New("duplecate creating"),
-		)
+		return "", err
 	}
 
 	return strconv.Itoa(table.Id), nil
 }
 
-func (rs syncLock) Get(owner, repoId string) (do synclockimpl.RepoSyncLockDO, err error) {
-	cond := &ProjectRepoSyncLock{
-		Owner:  owner,
-		RepoId: repoId,
+func (rs syncLock) Get(owner, repoId, ref string) (do synclockimpl.RepoSyncLockDO, err error) {
+	// don't use struct as the condition, because gorm will ignore
+	// the ref if it is empty.
+	cond := map[string]interface{}{
+		fieldOwner:  owner,
+		fieldRepoId: repoId,
+		fieldRef:    ref,
 	}
 
 	data := new(ProjectRepoSyncLock)
 
-	err = cli.db.Model(data).Where(cond).First(data).Error
+	err = rs.table().Where(cond).First(data).Error
 
 	if err == nil {
 		do = rs.toSyncLockDo(data)
@@ -54,17 +60,22 @@ func (rs syncLock) Get(owner, repoId string) (do synclockimpl.RepoSyncLockDO, er
 }
 
 func (rs syncLock) Update(do *synclockimpl.RepoSyncLockDO) error {
-	cond := &ProjectRepoSyncLock{
-		Owner:   do.Owner,
-		RepoId:  do.RepoId,
-		Version: do.Version,
+	// don't use struct as the condition, because gorm will ignore
+	// the version if it is 0.
+	cond := map[string]interface{}{
+		fieldOwner:   do.Owner,
+		fieldRepoId:  do.RepoId,
+		fieldRef:     do.Ref,
+		fieldVersion: do.Version,
 	}
 
-	tx := cli.db.Model(cond).Where(cond).Updates(
+	tx := rs.table().Where(cond).Updates(
 		map[string]interface{}{
 			fieldVersion:    gorm.Expr(fieldVersion+" + ?", 1),
 			fieldLastCommit: do.LastCommit,
 			fieldStatus:     do.Status,
+			fieldHolder:     do.Holder,
+			fieldExpiry:     do.Expiry,
 		},
 	)
 	if tx.Error != nil {
@@ -84,9 +95,12 @@ func (rs syncLock) toSyncLockTable(do *synclockimpl.RepoSyncLockDO) ProjectRepoS
 	return ProjectRepoSyncLock{
 		Owner:      do.Owner,
 		RepoId:     do.RepoId,
+		Ref:        do.Ref,
 		Status:     do.Status,
 		Version:    do.Version,
 		LastCommit: do.LastCommit,
+		Holder:     do.Holder,
+		Expiry:     do.Expiry,
 	}
 }
 
@@ -95,8 +109,11 @@ func (rs syncLock) toSyncLockDo(data *ProjectRepoSyncLock) synclockimpl.RepoSync
 		Id:         strconv.Itoa(data.Id),
 		Owner:      data.Owner,
 		RepoId:     data.RepoId,
+		Ref:        data.Ref,
 		Status:     data.Status,
 		Version:    data.Version,
 		LastCommit: data.LastCommit,
+		Holder:     data.Holder,
+		Expiry:     data.Expiry,
 	}
 }
@@ -0,0 +1,46 @@
+package gormdb
+
+const (
+	fieldOwner      = "owner"
+	fieldRepoId     = "repo_id"
+	fieldRef        = "ref"
+	fieldStatus     = "status"
+	fieldHolder     = "holder"
+	fieldExpiry     = "expiry"
+	fieldVersion    = "version"
+	fieldLastCommit = "last_commit"
+	fieldName       = "name"
+	fieldValue      = "value"
+	fieldUpdatedAt  = "updated_at"
+	fieldJobId      = "job_id"
+)
+
+type ProjectRepoSyncLock struct {
+	Id         int    `json:"-"            gorm:"column:id"`
+	Owner      string `json:"-"            gorm:"column:owner"`
+	RepoId     string `json:"-"            gorm:"column:repo_id"`
+	Ref        string `json:"-"            gorm:"column:ref"`
+	Status     string `json:"status"       gorm:"column:status"`
+	Version    int    `json:"-"            gorm:"column:version"`
+	LastCommit string `json:"last_commit"  gorm:"column:last_commit"`
+	Holder     string `json:"holder"       gorm:"column:holder"`
+	Expiry     int64  `json:"expiry"       gorm:"column:expiry"`
+}
+
+type TrainingSecret struct {
+	Id        int    `gorm:"column:id"`
+	Owner     string `gorm:"column:owner"`
+	Name      string `gorm:"column:name"`
+	Value     string `gorm:"column:value"`
+	UpdatedAt int64  `gorm:"column:updated_at"`
+}
+
+type TrainingJobOwner struct {
+	Id            int    `gorm:"column:id"`
+	JobId         string `gorm:"column:job_id"`
+	Owner         string `gorm:"column:owner"`
+	ProjectCommit string `gorm:"column:project_commit"`
+	// Inputs is the json of the commits of inputs.
+	Inputs    string `gorm:"column:inputs"`
+	CreatedAt int64  `gorm:"column:created_at"`
+}
@@ -0,0 +1,52 @@
+package inmemory
+
+import (
+	"sync"
+
+	"github.com/opensourceways/xihe-training-center/domain"
+	"github.com/opensourceways/xihe-training-center/domain/compute"
+)
+
+var _ compute.Compute = (*Compute)(nil)
+
+// Compute is the training platform which supports the engines
+// and flavors set by SetCatalog.
+type Compute struct {
+	faults
+
+	mu      sync.RWMutex
+	catalog domain.ComputeCatalog
+}
+
+func NewCompute() *Compute {
+	return &Compute{}
+}
+
+// SetCatalog sets the engines and flavors supported.
+func (c *Compute) SetCatalog(v domain.ComputeCatalog) {
+	c.mu.Lock()
+	c.catalog = v
+	c.mu.Unlock()
+}
+
+func (c *Compute) ListEngines() ([]domain.ComputeEngine, error) {
+	if err := c.err("ListEngines"); err != nil {
+		return nil, err
+	}
+
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return append([]domain.ComputeEngine(nil), c.catalog.Engines...), nil
+}
+
+func (c *Compute) ListFlavors() ([]domain.ComputeFlavorInfo, error) {
+	if err := c.err("ListFlavors"); err != nil {
+		return nil, err
+	}
+
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return append([]domain.ComputeFlavorInfo(nil), c.catalog.Flavors...), nil
+}
@@ -0,0 +1,35 @@
+package inmemory
+
+import "sync"
+
+// faults makes the methods of fakes fail on demand.
+type faults struct {
+	mu   sync.Mutex
+	errs map[string]error
+}
+
+// Fail makes the method fail with err until it is recovered.
+func (f *faults) Fail(method string, err error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.errs == nil {
+		f.errs = make(map[string]error)
+	}
+
+	f.errs[method] = err
+}
+
+// Recover makes the method work again.
+func (f *faults) Recover(method string) {
+	f.mu.Lock()
+	delete(f.errs, method)
+	f.mu.Unlock()
+}
+
+func (f *faults) err(method string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return f.errs[method]
+}
@@ -0,0 +1,88 @@
+package inmemory
+
+import (
+	"errors"
+	"sync"
+
+	"github.com/opensourceways/xihe-training-center/domain"
+	"github.com/opensourceways/xihe-training-center/domain/jobowner"
+)
+
+var _ jobowner.JobOwner = (*JobOwner)(nil)
+
+// JobOwner saves the owners of jobs in memory.
+type JobOwner struct {
+	faults
+
+	mu      sync.RWMutex
+	owners  map[string]domain.Account
+	commits map[string]jobowner.JobCommits
+}
+
+func NewJobOwner() *JobOwner {
+	return &JobOwner{
+		owners:  make(map[string]domain.Account),
+		commits: make(map[string]jobowner.JobCommits),
+	}
+}
+
+func (j *JobOwner) Save(jobId string, owner domain.Account, commits *jobowner.JobCommits) error {
+	if err := j.err("Save"); err != nil {
+		return err
+	}
+
+	c := *commits
+	c.Inputs = append([]jobowner.InputCommit(nil), commits.Inputs...)
+
+	j.mu.Lock()
+	j.owners[jobId] = owner
+	j.commits[jobId] = c
+	j.mu.Unlock()
+
+	return nil
+}
+
+func (j *JobOwner) Find(jobId string) (domain.Account, error) {
+	if err := j.err("Find"); err != nil {
+		return nil, err
+	}
+
+	j.mu.RLock()
+	defer j.mu.RUnlock()
+
+	v, ok := j.owners[jobId]
+	if !ok {
+		return nil, jobowner.NewErrorJobOwnerNotExists(errors.New("not found"))
+	}
+
+	return v, nil
+}
+
+func (j *JobOwner) FindCommits(jobId string) (jobowner.JobCommits, error) {
+	if err := j.err("FindCommits"); err != nil {
+		return jobowner.JobCommits{}, err
+	}
+
+	j.mu.RLock()
+	defer j.mu.RUnlock()
+
+	v, ok := j.commits[jobId]
+	if !ok {
+		return v, jobowner.NewErrorJobOwnerNotExists(errors.New("not found"))
+	}
+
+	return v, nil
+}
+
+func (j *JobOwner) Delete(jobId string) error {
+	if err := j.err("Delete"); err != nil {
+		return err
+	}
+
+	j.mu.Lock()
+	delete(j.owners, jobId)
+	delete(j.commits, jobId)
+	j.mu.Unlock()
+
+	return nil
+}
@@ -0,0 +1,54 @@
+package inmemory
+
+import (
+	"sync"
+
+	"github.com/opensourceways/xihe-training-center/domain/platform"
+)
+
+var _ platform.Platform = (*Platform)(nil)
+
+// Platform is the code platform which saves the latest commit of each repo.
+type Platform struct {
+	faults
+
+	mu      sync.RWMutex
+	commits map[string]string
+}
+
+func NewPlatform() *Platform {
+	return &Platform{
+		commits: make(map[string]string),
+	}
+}
+
+// SetLastCommit pushes the commit to the default branch of repo.
+func (p *Platform) SetLastCommit(pid, commit string) {
+	p.SetRefCommit(pid, "", commit)
+}
+
+// SetRefCommit pushes the commit to the ref of repo.
+func (p *Platform) SetRefCommit(pid, ref, commit string) {
+	p.mu.Lock()
+	p.commits[pid+"@"+ref] = commit
+	p.mu.Unlock()
+}
+
+func (p *Platform) GetLastCommit(pid, ref string) (string, error) {
+	if err := p.err("GetLastCommit"); err != nil {
+		return "", err
+	}
+
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	return p.commits[pid+"@"+ref], nil
+}
+
+func (p *Platform) GetCloneURL(owner, repo string) string {
+	return "inmemory://" + owner + "/" + repo
+}
+
+func (p *Platform) GetCloneCredential() platform.Credential {
+	return platform.Credential{}
+}
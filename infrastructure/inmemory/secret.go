@@ -0,0 +1,99 @@
+package inmemory
+
+import (
+	"errors"
+	"sort"
+	"sync"
+
+	"github.com/opensourceways/xihe-training-center/domain"
+	"github.com/opensourceways/xihe-training-center/domain/secret"
+)
+
+var _ secret.Secret = (*Secret)(nil)
+
+// Secret saves the secrets of users in memory.
+type Secret struct {
+	faults
+
+	mu      sync.RWMutex
+	secrets map[string]domain.Secret
+}
+
+func NewSecret() *Secret {
+	return &Secret{
+		secrets: make(map[string]domain.Secret),
+	}
+}
+
+func (s *Secret) Save(v *domain.Secret) error {
+	if err := s.err("Save"); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	s.secrets[secretKey(v.Owner, v.Name)] = *v
+	s.mu.Unlock()
+
+	return nil
+}
+
+func (s *Secret) Find(owner domain.Account, name domain.SecretName) (domain.Secret, error) {
+	if err := s.err("Find"); err != nil {
+		return domain.Secret{}, err
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	v, ok := s.secrets[secretKey(owner, name)]
+	if !ok {
+		return v, secret.NewErrorSecretNotExists(errors.New("not found"))
+	}
+
+	return v, nil
+}
+
+func (s *Secret) List(owner domain.Account) ([]domain.Secret, error) {
+	if err := s.err("List"); err != nil {
+		return nil, err
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var r []domain.Secret
+	for _, v := range s.secrets {
+		if v.Owner.Account() == owner.Account() {
+			v.Value = ""
+			r = append(r, v)
+		}
+	}
+
+	sort.Slice(r, func(i, j int) bool {
+		return r[i].Name.SecretName() < r[j].Name.SecretName()
+	})
+
+	return r, nil
+}
+
+func (s *Secret) Delete(owner domain.Account, name domain.SecretName) error {
+	if err := s.err("Delete"); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	k := secretKey(owner, name)
+	if _, ok := s.secrets[k]; !ok {
+		return secret.NewErrorSecretNotExists(errors.New("not found"))
+	}
+
+	delete(s.secrets, k)
+
+	return nil
+}
+
+func secretKey(owner domain.Account, name domain.SecretName) string {
+	return owner.Account() + "/" + name.SecretName()
+}
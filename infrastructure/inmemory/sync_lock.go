@@ -0,0 +1,90 @@
+package inmemory
+
+import (
+	"errors"
+	"strconv"
+	"sync"
+
+	"github.com/opensourceways/xihe-training-center/domain"
+	"github.com/opensourceways/xihe-training-center/domain/synclock"
+)
+
+var _ synclock.RepoSyncLock = (*RepoSyncLock)(nil)
+
+// RepoSyncLock saves the sync locks with the same optimistic
+// version semantics as synclockimpl.
+type RepoSyncLock struct {
+	faults
+
+	mu    sync.Mutex
+	num   int
+	locks map[string]domain.RepoSyncLock
+}
+
+func NewRepoSyncLock() *RepoSyncLock {
+	return &RepoSyncLock{
+		locks: make(map[string]domain.RepoSyncLock),
+	}
+}
+
+func (impl *RepoSyncLock) Find(owner domain.Account, repoId, ref string) (
+	domain.RepoSyncLock, error,
+) {
+	if err := impl.err("Find"); err != nil {
+		return domain.RepoSyncLock{}, err
+	}
+
+	impl.mu.Lock()
+	defer impl.mu.Unlock()
+
+	v, ok := impl.locks[impl.key(owner, repoId, ref)]
+	if !ok {
+		return v, synclock.NewErrorRepoNotExists(errors.New("no sync lock"))
+	}
+
+	return v, nil
+}
+
+func (impl *RepoSyncLock) Save(p *domain.RepoSyncLock) (domain.RepoSyncLock, error) {
+	if err := impl.err("Save"); err != nil {
+		return domain.RepoSyncLock{}, err
+	}
+
+	impl.mu.Lock()
+	defer impl.mu.Unlock()
+
+	k := impl.key(p.Owner, p.RepoId, p.Ref)
+	v, ok := impl.locks[k]
+
+	if p.Id == "" {
+		if ok {
+			return domain.RepoSyncLock{}, synclock.NewErrorConcurrentUpdating(
+				errors.New("duplicate creating"),
+			)
+		}
+
+		impl.num++
+
+		r := *p
+		r.Id = strconv.Itoa(impl.num)
+		impl.locks[k] = r
+
+		return r, nil
+	}
+
+	if !ok || v.Version != p.Version {
+		return domain.RepoSyncLock{}, synclock.NewErrorConcurrentUpdating(
+			errors.New("no matched record"),
+		)
+	}
+
+	r := *p
+	r.Version++
+	impl.locks[k] = r
+
+	return r, nil
+}
+
+func (impl *RepoSyncLock) key(owner domain.Account, repoId, ref string) string {
+	return owner.Account() + "/" + repoId + "@" + ref
+}
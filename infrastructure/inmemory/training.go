@@ -0,0 +1,404 @@
+package inmemory
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/opensourceways/xihe-training-center/domain"
+	"github.com/opensourceways/xihe-training-center/domain/training"
+)
+
+var _ training.Training = (*Training)(nil)
+
+// StatusStep means the job turns to Status after the duration
+// since it was created.
+type StatusStep struct {
+	After  time.Duration
+	Status domain.TrainingStatus
+}
+
+type job struct {
+	info      domain.JobInfo
+	training  domain.UserTraining
+	createdAt time.Time
+	steps     []StatusStep
+
+	// status overrides the steps if it is set.
+	status domain.TrainingStatus
+}
+
+func (j *job) getStatus(now time.Time) domain.TrainingStatus {
+	if j.status != nil {
+		return j.status
+	}
+
+	s := domain.TrainingStatus(domain.TrainingStatusRunning)
+
+	for _, step := range j.steps {
+		if now.Sub(j.createdAt) >= step.After {
+			s = step.Status
+		}
+	}
+
+	return s
+}
+
+// Training runs the jobs in memory. The status of job changes
+// over time according to the steps set by SetStatusSteps.
+type Training struct {
+	faults
+
+	mu        sync.Mutex
+	num       int
+	jobs      map[string]*job
+	steps     []StatusStep
+	syncDelay time.Duration
+	syncNum   int
+	snapshots map[string]bool
+	resources map[string]string
+
+	// inputSnapshots are the snapshots of inputs saved by SnapshotInput.
+	inputSnapshots map[string]bool
+
+	// files are the files of project. All the files exist if it is nil.
+	files map[string]bool
+}
+
+func NewTraining() *Training {
+	return &Training{
+		jobs:      make(map[string]*job),
+		snapshots: make(map[string]bool),
+		resources: make(map[string]string),
+
+		inputSnapshots: make(map[string]bool),
+	}
+}
+
+// SetStatusSteps sets the status steps of jobs created after it.
+// The steps must be sorted by the duration.
+func (t *Training) SetStatusSteps(steps ...StatusStep) {
+	t.mu.Lock()
+	t.steps = steps
+	t.mu.Unlock()
+}
+
+// SetStatus sets the status of job directly.
+func (t *Training) SetStatus(jobId string, status domain.TrainingStatus) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	j, err := t.getJob(jobId)
+	if err == nil {
+		j.status = status
+	}
+
+	return err
+}
+
+// SetSyncDelay sets the time which each sync of project takes.
+func (t *Training) SetSyncDelay(d time.Duration) {
+	t.mu.Lock()
+	t.syncDelay = d
+	t.mu.Unlock()
+}
+
+// SetRepoSyncedCommit sets the commit which the resource has been synced to.
+func (t *Training) SetRepoSyncedCommit(r *domain.ResourceRef, commit string) {
+	t.mu.Lock()
+	t.resources[resourceKey(r)] = commit
+	t.mu.Unlock()
+}
+
+// SetProjectFiles sets the files of project, the path of which
+// are relative to the root of repo.
+func (t *Training) SetProjectFiles(files ...string) {
+	m := make(map[string]bool, len(files))
+	for _, f := range files {
+		m[projectFilePath(f)] = true
+	}
+
+	t.mu.Lock()
+	t.files = m
+	t.mu.Unlock()
+}
+
+// SyncNum returns the times of syncing project.
+func (t *Training) SyncNum() int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return t.syncNum
+}
+
+// Jobs returns the trainings of all the jobs created.
+func (t *Training) Jobs() map[string]domain.UserTraining {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	r := make(map[string]domain.UserTraining, len(t.jobs))
+	for k, j := range t.jobs {
+		r[k] = j.training
+	}
+
+	return r
+}
+
+func (t *Training) Create(ut *domain.UserTraining) (domain.JobInfo, error) {
+	if err := t.err("Create"); err != nil {
+		return domain.JobInfo{}, err
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.num++
+	id := fmt.Sprintf("job-%d", t.num)
+	dir := filepath.Join(ut.ToPath(), id)
+
+	j := &job{
+		info: domain.JobInfo{
+			JobId:     id,
+			LogDir:    filepath.Join(dir, "log"),
+			AimDir:    filepath.Join(dir, "aim"),
+			OutputDir: filepath.Join(dir, "output"),
+		},
+		training:  *ut,
+		createdAt: time.Now(),
+		steps:     t.steps,
+	}
+	t.jobs[id] = j
+
+	return j.info, nil
+}
+
+func (t *Training) Delete(jobId string) error {
+	if err := t.err("Delete"); err != nil {
+		return err
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if _, err := t.getJob(jobId); err != nil {
+		return err
+	}
+
+	delete(t.jobs, jobId)
+
+	return nil
+}
+
+func (t *Training) Terminate(jobId string) error {
+	if err := t.err("Terminate"); err != nil {
+		return err
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	j, err := t.getJob(jobId)
+	if err != nil {
+		return err
+	}
+
+	if !j.getStatus(time.Now()).IsDone() {
+		j.status = domain.TrainingStatusTerminated
+	}
+
+	return nil
+}
+
+func (t *Training) GetLogDownloadURL(jobId string) (string, error) {
+	if err := t.err("GetLogDownloadURL"); err != nil {
+		return "", err
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	j, err := t.getJob(jobId)
+	if err != nil {
+		return "", err
+	}
+
+	return "inmemory://" + j.info.LogDir, nil
+}
+
+func (t *Training) GetDetail(jobId string) (r domain.JobDetail, err error) {
+	if err = t.err("GetDetail"); err != nil {
+		return
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	j, err := t.getJob(jobId)
+	if err != nil {
+		return
+	}
+
+	now := time.Now()
+	r.Status = j.getStatus(now)
+	r.Duration = int(now.Sub(j.createdAt).Seconds())
+
+	return
+}
+
+func (t *Training) GetLogFilePath(logDir string) (string, error) {
+	if err := t.err("GetLogFilePath"); err != nil {
+		return "", err
+	}
+
+	return filepath.Join(logDir, "train.log"), nil
+}
+
+func (t *Training) GenOutput(outputDir string) (string, error) {
+	if err := t.err("GenOutput"); err != nil {
+		return "", err
+	}
+
+	return outputDir + ".zip", nil
+}
+
+func (t *Training) GenAim(aimDir string) (string, error) {
+	if err := t.err("GenAim"); err != nil {
+		return "", err
+	}
+
+	return aimDir + ".zip", nil
+}
+
+func (t *Training) SyncProject(p *training.ProjectInfo) (string, error) {
+	t.mu.Lock()
+	t.syncNum++
+	d := t.syncDelay
+	t.mu.Unlock()
+
+	time.Sleep(d)
+
+	if err := t.err("SyncProject"); err != nil {
+		return "", err
+	}
+
+	t.mu.Lock()
+	t.snapshots[snapshotKey(p)] = true
+	t.mu.Unlock()
+
+	return p.Commit, nil
+}
+
+func (t *Training) IsProjectSynced(p *training.ProjectInfo) (bool, error) {
+	if err := t.err("IsProjectSynced"); err != nil {
+		return false, err
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return t.snapshots[snapshotKey(p)], nil
+}
+
+func (t *Training) IsCodeDirEmpty(ut *domain.UserTraining) (bool, error) {
+	if err := t.err("IsCodeDirEmpty"); err != nil {
+		return false, err
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.files == nil {
+		return false, nil
+	}
+
+	dir := projectFilePath(ut.CodeDir.Directory())
+	for f := range t.files {
+		if dir == "" || strings.HasPrefix(f, dir+"/") {
+			return false, nil
+		}
+	}
+
+	return true, nil
+}
+
+func (t *Training) IsBootFileExist(ut *domain.UserTraining) (bool, error) {
+	if err := t.err("IsBootFileExist"); err != nil {
+		return false, err
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.files == nil {
+		return true, nil
+	}
+
+	f := filepath.Join(ut.CodeDir.Directory(), ut.BootFile.FilePath())
+
+	return t.files[projectFilePath(f)], nil
+}
+
+func (t *Training) GetRepoSyncedCommit(r *domain.ResourceRef) (string, error) {
+	if err := t.err("GetRepoSyncedCommit"); err != nil {
+		return "", err
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return t.resources[resourceKey(r)], nil
+}
+
+func (t *Training) SnapshotInput(r *domain.ResourceRef) error {
+	if err := t.err("SnapshotInput"); err != nil {
+		return err
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.resources[resourceKey(r)] != r.Commit {
+		return fmt.Errorf("the resource is not synced to %s", r.Commit)
+	}
+
+	t.inputSnapshots[inputSnapshotKey(r)] = true
+
+	return nil
+}
+
+// HasInputSnapshot checks whether the snapshot of ResourceRef.Commit is saved.
+func (t *Training) HasInputSnapshot(r *domain.ResourceRef) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return t.inputSnapshots[inputSnapshotKey(r)]
+}
+
+// getJob must be called with holding the lock.
+func (t *Training) getJob(jobId string) (*job, error) {
+	j, ok := t.jobs[jobId]
+	if !ok {
+		return nil, errors.New("no such job")
+	}
+
+	return j, nil
+}
+
+func snapshotKey(p *training.ProjectInfo) string {
+	return filepath.Join(p.Owner.Account(), p.RepoId, p.Commit) + ":" + p.CodeDir
+}
+
+func projectFilePath(p string) string {
+	return strings.Trim(filepath.Clean("/"+p), "/")
+}
+
+func resourceKey(r *domain.ResourceRef) string {
+	return filepath.Join(r.User.Account(), r.Type.ResourceType(), r.RepoId)
+}
+
+func inputSnapshotKey(r *domain.ResourceRef) string {
+	return filepath.Join(resourceKey(r), r.Commit)
+}
@@ -0,0 +1,149 @@
+package inmemory
+
+import (
+	"sync"
+
+	"github.com/opensourceways/xihe-training-center/domain"
+	"github.com/opensourceways/xihe-training-center/domain/training"
+	"github.com/opensourceways/xihe-training-center/domain/watch"
+)
+
+var _ watch.WatchService = (*WatchService)(nil)
+
+// WatchService watches the trainings like watchimpl.Watcher, but it
+// checks them only when Check is called, so that tests can control it.
+type WatchService struct {
+	faults
+
+	ts training.Training
+
+	mu        sync.Mutex
+	callback  func(*watch.TrainingInfo)
+	trainings []watch.TrainingInfo
+	statuses  map[string]string
+
+	// hold blocks the notifications until it is closed,
+	// and held is signaled once a notification is blocked.
+	hold chan struct{}
+	held chan struct{}
+}
+
+func NewWatchService(ts training.Training) *WatchService {
+	return &WatchService{
+		ts:       ts,
+		statuses: make(map[string]string),
+	}
+}
+
+func (w *WatchService) WatchTraining(t *watch.TrainingInfo) {
+	w.mu.Lock()
+	w.trainings = append(w.trainings, *t)
+	w.mu.Unlock()
+}
+
+func (w *WatchService) RegisterTrainingDone(f func(*watch.TrainingInfo)) {
+	w.mu.Lock()
+	w.callback = f
+	w.mu.Unlock()
+}
+
+func (w *WatchService) NotifyTrainingStatus(t *watch.TrainingInfo, status domain.TrainingStatus) error {
+	if err := w.err("NotifyTrainingStatus"); err != nil {
+		return err
+	}
+
+	w.mu.Lock()
+	hold, held := w.hold, w.held
+	w.mu.Unlock()
+
+	if hold != nil {
+		select {
+		case held <- struct{}{}:
+		default:
+		}
+
+		<-hold
+	}
+
+	w.mu.Lock()
+	w.statuses[t.TrainingId] = status.TrainingStatus()
+	w.mu.Unlock()
+
+	return nil
+}
+
+// HoldNotify blocks the notifications like a slow server until the
+// returned release is called. The held is signaled once one is blocked.
+func (w *WatchService) HoldNotify() (held <-chan struct{}, release func()) {
+	hold := make(chan struct{})
+	c := make(chan struct{}, 1)
+
+	w.mu.Lock()
+	w.hold, w.held = hold, c
+	w.mu.Unlock()
+
+	return c, func() {
+		w.mu.Lock()
+		w.hold = nil
+		w.mu.Unlock()
+
+		close(hold)
+	}
+}
+
+// Status returns the status notified of the training.
+func (w *WatchService) Status(trainingId string) string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	return w.statuses[trainingId]
+}
+
+// Watching returns the number of trainings being watched.
+func (w *WatchService) Watching() int {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	return len(w.trainings)
+}
+
+// Check checks the trainings being watched, notifies the status of
+// them and calls the callback for the ones which are done.
+func (w *WatchService) Check() {
+	w.mu.Lock()
+	items := w.trainings
+	w.trainings = nil
+	callback := w.callback
+	w.mu.Unlock()
+
+	var remains, done []watch.TrainingInfo
+
+	for i := range items {
+		v := &items[i]
+
+		detail, err := w.ts.GetDetail(v.JobId)
+		if err != nil || !detail.Status.IsDone() {
+			remains = append(remains, *v)
+
+			continue
+		}
+
+		if w.NotifyTrainingStatus(v, detail.Status) != nil {
+			remains = append(remains, *v)
+		} else {
+			v.Status = detail.Status
+
+			done = append(done, *v)
+		}
+	}
+
+	w.mu.Lock()
+	w.trainings = append(w.trainings, remains...)
+	w.mu.Unlock()
+
+	if callback != nil {
+		for i := range done {
+			callback(&done[i])
+		}
+	}
+}
@@ -0,0 +1,23 @@
+package jobownerimpl
+
+import "github.com/opensourceways/xihe-training-center/domain/jobowner"
+
+type errorDataNotExists struct {
+	error
+}
+
+func NewErrorDataNotExists(err error) errorDataNotExists {
+	return errorDataNotExists{err}
+}
+
+func convertError(err error) (out error) {
+	switch err.(type) {
+	case errorDataNotExists:
+		out = jobowner.NewErrorJobOwnerNotExists(err)
+
+	default:
+		out = err
+	}
+
+	return
+}
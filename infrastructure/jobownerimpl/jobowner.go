@@ -0,0 +1,97 @@
+package jobownerimpl
+
+import (
+	"time"
+
+	"github.com/opensourceways/xihe-training-center/domain"
+	"github.com/opensourceways/xihe-training-center/domain/jobowner"
+)
+
+type JobOwnerMapper interface {
+	// Insert inserts the owner of job.
+	Insert(*JobOwnerDO) error
+	// Get gets the owner by job id.
+	Get(string) (JobOwnerDO, error)
+	// Delete deletes the owner by job id.
+	Delete(string) error
+}
+
+func NewJobOwner(mapper JobOwnerMapper) jobowner.JobOwner {
+	return jobOwnerImpl{mapper: mapper}
+}
+
+type jobOwnerImpl struct {
+	mapper JobOwnerMapper
+}
+
+func (impl jobOwnerImpl) Save(
+	jobId string, owner domain.Account, commits *jobowner.JobCommits,
+) error {
+	do := JobOwnerDO{
+		JobId:         jobId,
+		Owner:         owner.Account(),
+		ProjectCommit: commits.Project,
+		CreatedAt:     time.Now().Unix(),
+	}
+
+	if n := len(commits.Inputs); n > 0 {
+		do.Inputs = make([]InputCommitDO, n)
+
+		for i := range commits.Inputs {
+			v := &commits.Inputs[i]
+
+			do.Inputs[i] = InputCommitDO{Key: v.Key, Commit: v.Commit}
+		}
+	}
+
+	return convertError(impl.mapper.Insert(&do))
+}
+
+func (impl jobOwnerImpl) Find(jobId string) (domain.Account, error) {
+	do, err := impl.mapper.Get(jobId)
+	if err != nil {
+		return nil, convertError(err)
+	}
+
+	return domain.NewAccount(do.Owner)
+}
+
+func (impl jobOwnerImpl) FindCommits(jobId string) (r jobowner.JobCommits, err error) {
+	do, err := impl.mapper.Get(jobId)
+	if err != nil {
+		err = convertError(err)
+
+		return
+	}
+
+	r.Project = do.ProjectCommit
+
+	if n := len(do.Inputs); n > 0 {
+		r.Inputs = make([]jobowner.InputCommit, n)
+
+		for i := range do.Inputs {
+			v := &do.Inputs[i]
+
+			r.Inputs[i] = jobowner.InputCommit{Key: v.Key, Commit: v.Commit}
+		}
+	}
+
+	return
+}
+
+func (impl jobOwnerImpl) Delete(jobId string) error {
+	return convertError(impl.mapper.Delete(jobId))
+}
+
+type JobOwnerDO struct {
+	JobId         string
+	Owner         string
+	ProjectCommit string
+	Inputs        []InputCommitDO
+	CreatedAt     int64
+}
+
+type InputCommitDO struct {
+	Key    string `json:"key"`
+	Commit string `json:"commit"`
+}
@@ -0,0 +1,210 @@
+package migration
+
+import (
+	"bytes"
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const versionTableName = "schema_version"
+
+// Migration is an up-migration loaded from the file named like
+// "0001_create_table.sql". The file can include several statements
+// which are separated by ";", so don't use ";" in comments or strings.
+type Migration struct {
+	Version int
+	Name    string
+	Stmts   []string
+}
+
+type schemaVersion struct {
+	Version   int    `gorm:"column:version;primaryKey;autoIncrement:false"`
+	Name      string `gorm:"column:name;size:255;not null"`
+	AppliedAt int64  `gorm:"column:applied_at;not null"`
+}
+
+func (s *schemaVersion) TableName() string {
+	return versionTableName
+}
+
+// Load loads the migrations under dir of fsys. The content of each file
+// is a text/template which will be executed with data, so that the
+// configurable names, such as the table name, can be used in the sql.
+func Load(fsys fs.FS, dir string, data interface{}) ([]Migration, error) {
+	files, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		return nil, err
+	}
+
+	r := make([]Migration, 0, len(files))
+	versions := make(map[int]string)
+
+	for _, f := range files {
+		if f.IsDir() || !strings.HasSuffix(f.Name(), ".sql") {
+			continue
+		}
+
+		m, err := loadMigration(fsys, path.Join(dir, f.Name()), data)
+		if err != nil {
+			return nil, err
+		}
+
+		if v, ok := versions[m.Version]; ok {
+			return nil, fmt.Errorf(
+				"duplicate migration version: %s and %s", v, m.Name,
+			)
+		}
+		versions[m.Version] = m.Name
+
+		r = append(r, m)
+	}
+
+	sort.Slice(r, func(i, j int) bool {
+		return r[i].Version < r[j].Version
+	})
+
+	return r, nil
+}
+
+func loadMigration(fsys fs.FS, file string, data interface{}) (m Migration, err error) {
+	name := strings.TrimSuffix(path.Base(file), ".sql")
+
+	items := strings.SplitN(name, "_", 2)
+	if m.Version, err = strconv.Atoi(items[0]); err != nil || m.Version <= 0 {
+		err = fmt.Errorf("invalid migration file name: %s", file)
+
+		return
+	}
+
+	m.Name = name
+
+	content, err := fs.ReadFile(fsys, file)
+	if err != nil {
+		return
+	}
+
+	tmpl, err := template.New(name).Parse(string(content))
+	if err != nil {
+		return
+	}
+
+	buf := new(bytes.Buffer)
+	if err = tmpl.Execute(buf, data); err != nil {
+		return
+	}
+
+	for _, s := range strings.Split(buf.String(), ";") {
+		if s = strings.TrimSpace(s); s != "" && !isComment(s) {
+			m.Stmts = append(m.Stmts, s)
+		}
+	}
+
+	return
+}
+
+func isComment(s string) bool {
+	for _, line := range strings.Split(s, "\n") {
+		if line = strings.TrimSpace(line); line != "" && !strings.HasPrefix(line, "--") {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Lock makes the instances sharing a database migrate one by one.
+// It is held by the session of database, so Lock and Unlock are
+// called on the same connection.
+type Lock interface {
+	Lock(*gorm.DB) error
+	Unlock(*gorm.DB) error
+}
+
+// Migrate applies the migrations which have not been applied in order
+// and returns the ones applied this time. The migrations are applied
+// while holding the lock if it is not nil.
+func Migrate(db *gorm.DB, ms []Migration, lock Lock) (r []Migration, err error) {
+	if lock == nil {
+		return migrate(db, ms)
+	}
+
+	err = db.Connection(func(conn *gorm.DB) (err error) {
+		if err = lock.Lock(conn); err != nil {
+			return fmt.Errorf("lock migrations failed, err:%s", err.Error())
+		}
+
+		r, err = migrate(conn, ms)
+
+		if err1 := lock.Unlock(conn); err1 != nil && err == nil {
+			err = fmt.Errorf("unlock migrations failed, err:%s", err1.Error())
+		}
+
+		return
+	})
+
+	return
+}
+
+func migrate(db *gorm.DB, ms []Migration) ([]Migration, error) {
+	if err := db.AutoMigrate(&schemaVersion{}); err != nil {
+		return nil, err
+	}
+
+	var versions []int
+
+	err := db.Model(&schemaVersion{}).Pluck("version", &versions).Error
+	if err != nil {
+		return nil, err
+	}
+
+	applied := make(map[int]bool, len(versions))
+	for _, v := range versions {
+		applied[v] = true
+	}
+
+	var r []Migration
+
+	for i := range ms {
+		m := &ms[i]
+
+		if applied[m.Version] {
+			continue
+		}
+
+		if err := apply(db, m); err != nil {
+			return r, fmt.Errorf(
+				"apply migration %s failed, err:%s", m.Name, err.Error(),
+			)
+		}
+
+		r = append(r, *m)
+	}
+
+	return r, nil
+}
+
+// apply runs the migration in a transaction. Note that the DDL of
+// some databases, such as MySQL, can't be rolled back.
+func apply(db *gorm.DB, m *Migration) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		for _, s := range m.Stmts {
+			if err := tx.Exec(s).Error; err != nil {
+				return err
+			}
+		}
+
+		return tx.Create(&schemaVersion{
+			Version:   m.Version,
+			Name:      m.Name,
+			AppliedAt: time.Now().Unix(),
+		}).Error
+	})
+}
@@ -7,10 +7,30 @@ type Config struct {
 	MaxIdleConns    int    `json:"max_idle_conns"`
 
 	ProjectTableName string `json:"project_table_name" required:"true"`
+
+	// SecretTableName is the table of secrets of users.
+	// It is "training_secret" by default.
+	SecretTableName string `json:"secret_table_name"`
+
+	// JobOwnerTableName is the table of owners of training jobs.
+	// It is "training_job_owner" by default.
+	JobOwnerTableName string `json:"job_owner_table_name"`
+
+	// AutoMigrate specifies whether to apply the migrations of tables at startup.
+	// The migrations can also be applied by the "migrate" sub command.
+	AutoMigrate bool `json:"auto_migrate"`
 }
 
 func (cfg *Config) SetDefault() {
 	cfg.ConnMaxLifetime = 900
 	cfg.MaxOpenConns = 3000
 	cfg.MaxIdleConns = 30
+
+	if cfg.SecretTableName == "" {
+		cfg.SecretTableName = "training_secret"
+	}
+
+	if cfg.JobOwnerTableName == "" {
+		cfg.JobOwnerTableName = "training_job_owner"
+	}
 }
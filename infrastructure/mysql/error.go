@@ -0,0 +1,16 @@
+package mysql
+
+import (
+	"errors"
+
+	"github.com/go-sql-driver/mysql"
+)
+
+// mysqlDuplicateEntry is the error number of ER_DUP_ENTRY of MySQL.
+const mysqlDuplicateEntry = 1062
+
+func isErrorUniqueViolation(err error) bool {
+	var e *mysql.MySQLError
+
+	return errors.As(err, &e) && e.Number == mysqlDuplicateEntry
+}
@@ -0,0 +1,48 @@
+package mysql
+
+import (
+	"database/sql"
+	"embed"
+	"errors"
+
+	"gorm.io/gorm"
+)
+
+//go:embed migrations/*.sql
+var migrationFiles embed.FS
+
+// Migrate applies the migrations of tables and returns the names of
+// migrations applied this time. It must be called after Init.
+func Migrate() ([]string, error) {
+	return cli.Migrate(migrationFiles, migrationLock{})
+}
+
+const (
+	migrationLockName    = "xihe_training_center_migration"
+	migrationLockTimeout = 300
+)
+
+// migrationLock is the named lock of MySQL. It is released automatically
+// if the session is closed.
+type migrationLock struct{}
+
+func (l migrationLock) Lock(db *gorm.DB) error {
+	var r sql.NullInt64
+
+	err := db.Raw(
+		"SELECT GET_LOCK(?, ?)", migrationLockName, migrationLockTimeout,
+	).Row().Scan(&r)
+	if err != nil {
+		return err
+	}
+
+	if !r.Valid || r.Int64 != 1 {
+		return errors.New("timeout of waiting for the lock")
+	}
+
+	return nil
+}
+
+func (l migrationLock) Unlock(db *gorm.DB) error {
+	return db.Exec("SELECT RELEASE_LOCK(?)", migrationLockName).Error
+}
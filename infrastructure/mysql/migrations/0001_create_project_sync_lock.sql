@@ -0,0 +1,11 @@
+-- the table of the sync lock of project repo.
+CREATE TABLE IF NOT EXISTS `{{.ProjectTableName}}` (
+    `id`          INT          NOT NULL AUTO_INCREMENT,
+    `owner`       VARCHAR(255) NOT NULL,
+    `repo_id`     VARCHAR(255) NOT NULL,
+    `status`      VARCHAR(32)  NOT NULL DEFAULT '',
+    `version`     INT          NOT NULL DEFAULT 0,
+    `last_commit` VARCHAR(64)  NOT NULL DEFAULT '',
+    PRIMARY KEY (`id`),
+    UNIQUE KEY `uk_owner_repo` (`owner`, `repo_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
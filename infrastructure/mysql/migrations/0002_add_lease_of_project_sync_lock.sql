@@ -0,0 +1,4 @@
+-- the lease of sync lock, see app.SyncLockConfig.
+ALTER TABLE `{{.ProjectTableName}}`
+    ADD COLUMN `holder` VARCHAR(255) NOT NULL DEFAULT '',
+    ADD COLUMN `expiry` BIGINT       NOT NULL DEFAULT 0;
@@ -0,0 +1,24 @@
+-- the sync lock tracks the last commit of each ref of repo.
+ALTER TABLE `{{.ProjectTableName}}`
+    ADD COLUMN `ref` VARCHAR(255) NOT NULL DEFAULT '' AFTER `repo_id`,
+    ADD UNIQUE KEY `uk_owner_repo_ref` (`owner`, `repo_id`, `ref`);
+
+-- the table created before the migrations may not have the unique key
+-- uk_owner_repo, so drop it only if it exists.
+SET @drop_uk_owner_repo = (
+    SELECT IF(
+        COUNT(*) > 0,
+        'ALTER TABLE `{{.ProjectTableName}}` DROP INDEX `uk_owner_repo`',
+        'DO 0'
+    )
+    FROM information_schema.statistics
+    WHERE table_schema = DATABASE()
+      AND table_name = '{{.ProjectTableName}}'
+      AND index_name = 'uk_owner_repo'
+);
+
+PREPARE drop_uk_owner_repo FROM @drop_uk_owner_repo;
+
+EXECUTE drop_uk_owner_repo;
+
+DEALLOCATE PREPARE drop_uk_owner_repo;
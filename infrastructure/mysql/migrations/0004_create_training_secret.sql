@@ -0,0 +1,10 @@
+-- the table of the secrets of users which can be referenced by the env of training.
+CREATE TABLE IF NOT EXISTS `{{.SecretTableName}}` (
+    `id`         INT          NOT NULL AUTO_INCREMENT,
+    `owner`      VARCHAR(255) NOT NULL,
+    `name`       VARCHAR(64)  NOT NULL,
+    `value`      TEXT         NOT NULL,
+    `updated_at` BIGINT       NOT NULL DEFAULT 0,
+    PRIMARY KEY (`id`),
+    UNIQUE KEY `uk_owner_name` (`owner`, `name`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
@@ -0,0 +1,9 @@
+-- the table of the owners of training jobs which are checked when accessing the jobs.
+CREATE TABLE IF NOT EXISTS `{{.JobOwnerTableName}}` (
+    `id`         INT          NOT NULL AUTO_INCREMENT,
+    `job_id`     VARCHAR(255) NOT NULL,
+    `owner`      VARCHAR(255) NOT NULL,
+    `created_at` BIGINT       NOT NULL DEFAULT 0,
+    PRIMARY KEY (`id`),
+    UNIQUE KEY `uk_job_id` (`job_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
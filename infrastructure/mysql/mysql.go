@@ -1,14 +1,20 @@
 package mysql
 
 import (
+	"context"
 	"time"
 
 	_ "github.com/go-sql-driver/mysql"
 	gormmysql "gorm.io/driver/mysql"
 	"gorm.io/gorm"
+
+	"github.com/opensourceways/xihe-training-center/infrastructure/gormdb"
+	"github.com/opensourceways/xihe-training-center/infrastructure/jobownerimpl"
+	"github.com/opensourceways/xihe-training-center/infrastructure/secretimpl"
+	"github.com/opensourceways/xihe-training-center/infrastructure/synclockimpl"
 )
 
-var cli *mysqlService
+var cli *gormdb.DB
 
 func Init(cfg *Config) error {
 	config := gormmysql.Config{
@@ -32,15 +38,28 @@ func Init(cfg *Config) error {
 	sqlDB.SetMaxOpenConns(cfg.MaxOpenConns)
 	sqlDB.SetMaxIdleConns(cfg.MaxIdleConns)
 
-	cli = &mysqlService{
-		db: db,
-	}
-
-	projectTableName = cfg.ProjectTableName
+	cli = gormdb.New(db, gormdb.Tables{
+		ProjectTableName:  cfg.ProjectTableName,
+		SecretTableName:   cfg.SecretTableName,
+		JobOwnerTableName: cfg.JobOwnerTableName,
+	}, isErrorUniqueViolation)
 
 	return nil
 }
 
-type mysqlService struct {
-	db *gorm.DB
+func NewSyncLockMapper() synclockimpl.SyncLockMapper {
+	return cli.NewSyncLockMapper()
+}
+
+func NewSecretMapper() secretimpl.SecretMapper {
+	return cli.NewSecretMapper()
+}
+
+func NewJobOwnerMapper() jobownerimpl.JobOwnerMapper {
+	return cli.NewJobOwnerMapper()
+}
+
+// Ping checks the connection to the database.
+func Ping(ctx context.Context) error {
+	return cli.Ping(ctx)
 }
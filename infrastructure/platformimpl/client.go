@@ -0,0 +1,67 @@
+package platformimpl
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+// errorStatus is the error of http response whose status code is not 2xx.
+type errorStatus struct {
+	code int
+	body string
+}
+
+func (e errorStatus) Error() string {
+	return fmt.Sprintf("response status: %d, body: %s", e.code, e.body)
+}
+
+func isErrorStatus(err error, code int) bool {
+	v, ok := err.(errorStatus)
+
+	return ok && v.code == code
+}
+
+// apiClient accesses the rest api of platform.
+type apiClient struct {
+	cli      http.Client
+	endpoint string
+	header   map[string]string
+}
+
+func newAPIClient(endpoint string, timeout int, header map[string]string) apiClient {
+	return apiClient{
+		cli:      http.Client{Timeout: time.Duration(timeout) * time.Second},
+		endpoint: endpoint,
+		header:   header,
+	}
+}
+
+func (c *apiClient) get(path string, result interface{}) error {
+	req, err := http.NewRequest(http.MethodGet, c.endpoint+path, nil)
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Accept", "application/json")
+	for k, v := range c.header {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := c.cli.Do(req)
+	if err != nil {
+		return err
+	}
+
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		b, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+
+		return errorStatus{code: resp.StatusCode, body: string(b)}
+	}
+
+	return json.NewDecoder(resp.Body).Decode(result)
+}
@@ -1,8 +1,79 @@
 package platformimpl
 
+import (
+	"errors"
+	"strings"
+)
+
+const (
+	platformGit    = "git"
+	platformGitea  = "gitea"
+	platformGitlab = "gitlab"
+	platformGithub = "github"
+)
+
 type Config struct {
-	Token string `json:"token" required:"true"`
+	// Type is the type of code platform, it is one of
+	// gitlab, gitea, github and git. It is gitlab by default.
+	// git means any git server which only the git protocol
+	// is used to access, and the repo id is the path of repo,
+	// such as "owner/repo".
+	Type string `json:"type"`
+
+	// Token is the access token of platform.
+	// It is optional only for the type of git.
+	Token string `json:"token"`
+
+	// User is the user of token. It is the owner of
+	// token by default if the platform supports it.
+	User string `json:"user"`
 
 	// Host is like https://gitlab.com
 	Host string `json:"host" required:"true"`
+
+	// APIEndpoint is the endpoint of api of github.
+	// It is https://api.github.com for github.com and
+	// Host/api/v3 for github enterprise by default.
+	APIEndpoint string `json:"api_endpoint"`
+
+	// Timeout specifies the seconds of accessing the platform.
+	Timeout int `json:"timeout"`
+}
+
+func (cfg *Config) SetDefault() {
+	if cfg.Type == "" {
+		cfg.Type = platformGitlab
+	}
+
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = 30
+	}
+
+	if cfg.Type == platformGithub && cfg.APIEndpoint == "" {
+		if cfg.host() == "https://github.com" {
+			cfg.APIEndpoint = "https://api.github.com"
+		} else {
+			cfg.APIEndpoint = cfg.host() + "/api/v3"
+		}
+	}
+}
+
+func (cfg *Config) Validate() error {
+	switch cfg.Type {
+	case platformGitlab, platformGitea, platformGithub:
+		if cfg.Token == "" {
+			return errors.New("missing token of platform")
+		}
+
+	case platformGit:
+
+	default:
+		return errors.New("unknown type of platform")
+	}
+
+	return nil
+}
+
+func (cfg *Config) host() string {
+	return strings.TrimSuffix(cfg.Host, "/")
 }
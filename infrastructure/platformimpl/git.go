@@ -0,0 +1,104 @@
+package platformimpl
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/opensourceways/xihe-training-center/domain/platform"
+	"github.com/opensourceways/xihe-training-center/utils"
+)
+
+func newGit(cfg *Config) platform.Platform {
+	user := cfg.User
+	if user == "" {
+		user = "git"
+	}
+
+	return &gitImpl{
+		endpoint: cfg.host(),
+		timeout:  time.Duration(cfg.Timeout) * time.Second,
+		credential: platform.Credential{
+			Username: user,
+			Password: cfg.Token,
+		},
+	}
+}
+
+// gitImpl accesses the repo by git protocol only,
+// and the repo id is the path of repo, such as "owner/repo".
+type gitImpl struct {
+	endpoint   string
+	timeout    time.Duration
+	credential platform.Credential
+}
+
+func (impl *gitImpl) GetCloneURL(owner, repo string) string {
+	return fmt.Sprintf("%s/%s/%s", impl.endpoint, owner, repo)
+}
+
+func (impl *gitImpl) GetCloneCredential() platform.Credential {
+	return impl.credential
+}
+
+// GetLastCommit returns the commit of ref of remote repo.
+// It is the commit of HEAD if ref is empty.
+func (impl *gitImpl) GetLastCommit(pid, ref string) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), impl.timeout)
+	defer cancel()
+
+	patterns := []string{"HEAD"}
+	if ref != "" {
+		// the commit of annotated tag is the one of "refs/tags/<ref>^{}".
+		patterns = []string{
+			"refs/heads/" + ref, "refs/tags/" + ref, "refs/tags/" + ref + "^{}",
+		}
+	}
+
+	args := append([]string{"ls-remote", impl.endpoint + "/" + pid}, patterns...)
+	cmd := exec.CommandContext(ctx, "git", args...)
+	// don't prompt for the credentials.
+	cmd.Env = append(os.Environ(), "GIT_TERMINAL_PROMPT=0")
+	cmd.Env = append(cmd.Env, utils.GitCredentialEnv(
+		impl.credential.Username, impl.credential.Password,
+	)...)
+
+	out, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("ls-remote repo:%s failed, err:%s", pid, err.Error())
+	}
+
+	return parseLsRemote(string(out), patterns)
+}
+
+// parseLsRemote parses the output of ls-remote whose lines are like
+// "<commit>\t<ref>", and returns the commit of the matched pattern. The
+// later pattern takes precedence. It returns empty if nothing matched.
+func parseLsRemote(out string, patterns []string) (string, error) {
+	commits := make(map[string]string)
+
+	for _, line := range strings.Split(out, "\n") {
+		if line = strings.TrimSpace(line); line == "" {
+			continue
+		}
+
+		fields := strings.Fields(line)
+		if len(fields) != 2 {
+			return "", errors.New("unexpected output of ls-remote")
+		}
+
+		commits[fields[1]] = fields[0]
+	}
+
+	for i := len(patterns) - 1; i >= 0; i-- {
+		if c, ok := commits[patterns[i]]; ok {
+			return c, nil
+		}
+	}
+
+	return "", nil
+}
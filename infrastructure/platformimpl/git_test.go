@@ -0,0 +1,169 @@
+package platformimpl
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParseLsRemote(t *testing.T) {
+	const (
+		head   = "1111111111111111111111111111111111111111"
+		branch = "2222222222222222222222222222222222222222"
+		tag    = "3333333333333333333333333333333333333333"
+		peeled = "4444444444444444444444444444444444444444"
+	)
+
+	refPatterns := []string{"refs/heads/v1", "refs/tags/v1", "refs/tags/v1^{}"}
+
+	cases := []struct {
+		name     string
+		out      string
+		patterns []string
+		want     string
+		wantErr  bool
+	}{
+		{
+			name:     "head",
+			out:      head + "\tHEAD\n",
+			patterns: []string{"HEAD"},
+			want:     head,
+		},
+		{
+			name:     "branch",
+			out:      branch + "\trefs/heads/v1\n",
+			patterns: refPatterns,
+			want:     branch,
+		},
+		{
+			name:     "lightweight tag",
+			out:      tag + "\trefs/tags/v1\n",
+			patterns: refPatterns,
+			want:     tag,
+		},
+		{
+			name:     "annotated tag is peeled",
+			out:      tag + "\trefs/tags/v1\n" + peeled + "\trefs/tags/v1^{}\n",
+			patterns: refPatterns,
+			want:     peeled,
+		},
+		{
+			name:     "tag takes precedence over branch",
+			out:      branch + "\trefs/heads/v1\n" + tag + "\trefs/tags/v1\n",
+			patterns: refPatterns,
+			want:     tag,
+		},
+		{
+			name:     "blank lines are skipped",
+			out:      "\n  \n" + head + "\tHEAD\n\n",
+			patterns: []string{"HEAD"},
+			want:     head,
+		},
+		{
+			name:     "not matched",
+			out:      branch + "\trefs/heads/v10\n",
+			patterns: refPatterns,
+		},
+		{
+			name:     "empty repo",
+			patterns: []string{"HEAD"},
+		},
+		{
+			name:     "malformed line",
+			out:      head + "\n",
+			patterns: []string{"HEAD"},
+			wantErr:  true,
+		},
+	}
+
+	for i := range cases {
+		c := &cases[i]
+
+		v, err := parseLsRemote(c.out, c.patterns)
+		if (err != nil) != c.wantErr {
+			t.Errorf("case %s: err = %v, want error %v", c.name, err, c.wantErr)
+
+			continue
+		}
+
+		if v != c.want {
+			t.Errorf("case %s: commit = %s, want %s", c.name, v, c.want)
+		}
+	}
+}
+
+func TestGitGetLastCommit(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+
+	dir := t.TempDir()
+	work := filepath.Join(dir, "work")
+
+	run := func(args ...string) string {
+		t.Helper()
+
+		cmd := exec.Command("git", args...)
+		cmd.Env = append(
+			os.Environ(),
+			"GIT_AUTHOR_NAME=test", "GIT_AUTHOR_EMAIL=test@example.com",
+			"GIT_COMMITTER_NAME=test", "GIT_COMMITTER_EMAIL=test@example.com",
+		)
+
+		out, err := cmd.CombinedOutput()
+		if err != nil {
+			t.Fatalf("git %v failed, err:%v, out:%s", args, err, out)
+		}
+
+		return strings.TrimSpace(string(out))
+	}
+
+	run("init", "-q", "--bare", "-b", "main", filepath.Join(dir, "owner", "repo"))
+	run("init", "-q", "-b", "main", work)
+	run("-C", work, "commit", "-q", "--allow-empty", "-m", "first")
+	first := run("-C", work, "rev-parse", "HEAD")
+	run("-C", work, "tag", "-a", "v1", "-m", "v1")
+	run("-C", work, "checkout", "-q", "-b", "dev")
+	run("-C", work, "commit", "-q", "--allow-empty", "-m", "second")
+	second := run("-C", work, "rev-parse", "HEAD")
+	run("-C", work, "push", "-q", filepath.Join(dir, "owner", "repo"), "main", "dev", "v1")
+
+	p := newGit(&Config{Type: platformGit, Host: "file://" + dir, Timeout: 10})
+
+	cases := []struct {
+		ref  string
+		want string
+	}{
+		{"", first},
+		{"dev", second},
+		{"v1", first},
+		{"unknown", ""},
+	}
+
+	for _, c := range cases {
+		v, err := p.GetLastCommit("owner/repo", c.ref)
+		if err != nil {
+			t.Errorf("ref %q: err:%v", c.ref, err)
+
+			continue
+		}
+
+		if v != c.want {
+			t.Errorf("ref %q: commit = %s, want %s", c.ref, v, c.want)
+		}
+	}
+
+	if _, err := p.GetLastCommit("owner/none", ""); err == nil {
+		t.Error("no error for the repo which doesn't exist")
+	}
+
+	if v := p.GetCloneURL("owner", "repo"); v != "file://"+dir+"/owner/repo" {
+		t.Errorf("clone url = %s", v)
+	}
+
+	if v := p.GetCloneCredential(); v.Username != "git" {
+		t.Errorf("user = %s, want the default one", v.Username)
+	}
+}
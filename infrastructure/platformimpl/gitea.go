@@ -0,0 +1,111 @@
+package platformimpl
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/opensourceways/xihe-training-center/domain/platform"
+)
+
+func newGitea(cfg *Config) (platform.Platform, error) {
+	cli := newAPIClient(
+		cfg.host()+"/api/v1", cfg.Timeout,
+		map[string]string{"Authorization": "token " + cfg.Token},
+	)
+
+	user := cfg.User
+	if user == "" {
+		var u struct {
+			Login string `json:"login"`
+		}
+
+		if err := cli.get("/user", &u); err != nil {
+			return nil, err
+		}
+
+		user = u.Login
+	}
+
+	return &giteaImpl{
+		cli:      cli,
+		endpoint: cfg.host(),
+		credential: platform.Credential{
+			Username: user,
+			Password: cfg.Token,
+		},
+	}, nil
+}
+
+type giteaImpl struct {
+	cli        apiClient
+	endpoint   string
+	credential platform.Credential
+}
+
+func (impl *giteaImpl) GetCloneURL(owner, repo string) string {
+	return fmt.Sprintf("%s/%s/%s", impl.endpoint, owner, repo)
+}
+
+func (impl *giteaImpl) GetCloneCredential() platform.Credential {
+	return impl.credential
+}
+
+// GetLastCommit returns the last commit of ref. pid is the id of repo.
+func (impl *giteaImpl) GetLastCommit(pid, ref string) (string, error) {
+	var repo struct {
+		Empty         bool   `json:"empty"`
+		FullName      string `json:"full_name"`
+		DefaultBranch string `json:"default_branch"`
+	}
+
+	if err := impl.cli.get("/repositories/"+url.PathEscape(pid), &repo); err != nil {
+		return "", err
+	}
+
+	if repo.Empty {
+		return "", nil
+	}
+
+	if ref != "" {
+		var v []struct {
+			SHA string `json:"sha"`
+		}
+
+		err := impl.cli.get(
+			fmt.Sprintf(
+				"/repos/%s/commits?limit=1&stat=false&sha=%s",
+				repo.FullName, url.QueryEscape(ref),
+			),
+			&v,
+		)
+		if err != nil || len(v) == 0 {
+			return "", err
+		}
+
+		return v[0].SHA, nil
+	}
+
+	var branch struct {
+		Commit struct {
+			Id string `json:"id"`
+		} `json:"commit"`
+	}
+
+	err := impl.cli.get(
+		fmt.Sprintf(
+			"/repos/%s/branches/%s",
+			repo.FullName, url.PathEscape(repo.DefaultBranch),
+		),
+		&branch,
+	)
+	if err != nil {
+		if isErrorStatus(err, http.StatusNotFound) {
+			return "", nil
+		}
+
+		return "", err
+	}
+
+	return branch.Commit.Id, nil
+}
@@ -0,0 +1,129 @@
+package platformimpl
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+const testToken = "tk"
+
+// newTestServer returns the server which responds the body of path.
+// It responds 404 if the path is not found and 401 if the header of
+// authorization is not the expected one.
+func newTestServer(
+	t *testing.T, auth string, bodies map[string]interface{},
+) *httptest.Server {
+	t.Helper()
+
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != auth {
+			w.WriteHeader(http.StatusUnauthorized)
+
+			return
+		}
+
+		v, ok := bodies[r.URL.RequestURI()]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+
+			return
+		}
+
+		if code, ok := v.(int); ok {
+			w.WriteHeader(code)
+
+			return
+		}
+
+		if err := json.NewEncoder(w).Encode(v); err != nil {
+			t.Errorf("encode response failed, err:%v", err)
+		}
+	}))
+
+	t.Cleanup(s.Close)
+
+	return s
+}
+
+func TestGitea(t *testing.T) {
+	s := newTestServer(t, "token "+testToken, map[string]interface{}{
+		"/api/v1/user": map[string]string{"login": "bot"},
+		"/api/v1/repositories/1": map[string]interface{}{
+			"full_name": "owner/repo", "default_branch": "main",
+		},
+		"/api/v1/repos/owner/repo/branches/main": map[string]interface{}{
+			"commit": map[string]string{"id": "c1"},
+		},
+		"/api/v1/repos/owner/repo/commits?limit=1&stat=false&sha=dev": []map[string]string{
+			{"sha": "c2"},
+		},
+		"/api/v1/repositories/2": map[string]interface{}{
+			"full_name": "owner/empty", "empty": true,
+		},
+		"/api/v1/repositories/3": map[string]interface{}{
+			"full_name": "owner/nobranch", "default_branch": "main",
+		},
+	})
+
+	p, err := newGitea(&Config{Type: platformGitea, Host: s.URL + "/", Token: testToken})
+	if err != nil {
+		t.Fatalf("new gitea failed, err:%v", err)
+	}
+
+	if v := p.GetCloneCredential(); v.Username != "bot" || v.Password != testToken {
+		t.Errorf("credential = %+v, want the user of token", v)
+	}
+
+	if v := p.GetCloneURL("owner", "repo"); v != s.URL+"/owner/repo" {
+		t.Errorf("clone url = %s", v)
+	}
+
+	cases := []struct {
+		pid     string
+		ref     string
+		want    string
+		wantErr bool
+	}{
+		{pid: "1", want: "c1"},
+		{pid: "1", ref: "dev", want: "c2"},
+		{pid: "1", ref: "unknown", wantErr: true},
+		{pid: "2"},
+		{pid: "3"},
+		{pid: "4", wantErr: true},
+	}
+
+	for _, c := range cases {
+		v, err := p.GetLastCommit(c.pid, c.ref)
+		if (err != nil) != c.wantErr {
+			t.Errorf("repo %s ref %q: err = %v, want error %v", c.pid, c.ref, err, c.wantErr)
+
+			continue
+		}
+
+		if v != c.want {
+			t.Errorf("repo %s ref %q: commit = %s, want %s", c.pid, c.ref, v, c.want)
+		}
+	}
+}
+
+func TestGiteaWithUser(t *testing.T) {
+	// the user of token is not fetched if it is configured.
+	s := newTestServer(t, "token "+testToken, nil)
+
+	p, err := newGitea(&Config{
+		Type: platformGitea, Host: s.URL, Token: testToken, User: "alice",
+	})
+	if err != nil {
+		t.Fatalf("new gitea failed, err:%v", err)
+	}
+
+	if v := p.GetCloneCredential(); v.Username != "alice" {
+		t.Errorf("user = %s, want alice", v.Username)
+	}
+
+	if _, err := newGitea(&Config{Type: platformGitea, Host: s.URL, Token: "bad"}); err == nil {
+		t.Error("no error for the invalid token")
+	}
+}
@@ -0,0 +1,83 @@
+package platformimpl
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/opensourceways/xihe-training-center/domain/platform"
+)
+
+func newGithub(cfg *Config) (platform.Platform, error) {
+	cli := newAPIClient(
+		cfg.APIEndpoint, cfg.Timeout,
+		map[string]string{
+			"Accept":        "application/vnd.github+json",
+			"Authorization": "Bearer " + cfg.Token,
+		},
+	)
+
+	user := cfg.User
+	if user == "" {
+		var u struct {
+			Login string `json:"login"`
+		}
+
+		if err := cli.get("/user", &u); err != nil {
+			return nil, err
+		}
+
+		user = u.Login
+	}
+
+	return &githubImpl{
+		cli:      cli,
+		endpoint: cfg.host(),
+		credential: platform.Credential{
+			Username: user,
+			Password: cfg.Token,
+		},
+	}, nil
+}
+
+type githubImpl struct {
+	cli        apiClient
+	endpoint   string
+	credential platform.Credential
+}
+
+func (impl *githubImpl) GetCloneURL(owner, repo string) string {
+	return fmt.Sprintf("%s/%s/%s", impl.endpoint, owner, repo)
+}
+
+func (impl *githubImpl) GetCloneCredential() platform.Credential {
+	return impl.credential
+}
+
+// GetLastCommit returns the last commit of ref. pid is the id of repo.
+func (impl *githubImpl) GetLastCommit(pid, ref string) (string, error) {
+	var v []struct {
+		SHA string `json:"sha"`
+	}
+
+	path := "/repositories/" + url.PathEscape(pid) + "/commits?per_page=1"
+	if ref != "" {
+		path += "&sha=" + url.QueryEscape(ref)
+	}
+
+	err := impl.cli.get(path, &v)
+	if err != nil {
+		// github responds 409 if the repo is empty.
+		if isErrorStatus(err, http.StatusConflict) {
+			return "", nil
+		}
+
+		return "", err
+	}
+
+	if len(v) == 0 {
+		return "", nil
+	}
+
+	return v[0].SHA, nil
+}
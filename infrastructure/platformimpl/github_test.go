@@ -0,0 +1,76 @@
+package platformimpl
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestGithub(t *testing.T) {
+	s := newTestServer(t, "Bearer "+testToken, map[string]interface{}{
+		"/user":                              map[string]string{"login": "bot"},
+		"/repositories/1/commits?per_page=1": []map[string]string{{"sha": "c1"}},
+		"/repositories/1/commits?per_page=1&sha=dev": []map[string]string{
+			{"sha": "c2"},
+		},
+		"/repositories/1/commits?per_page=1&sha=none": []map[string]string{},
+		"/repositories/2/commits?per_page=1":          http.StatusConflict,
+		"/repositories/3/commits?per_page=1":          http.StatusInternalServerError,
+	})
+
+	cfg := Config{Type: platformGithub, Host: "https://github.example.com", Token: testToken}
+	cfg.SetDefault()
+
+	if cfg.APIEndpoint != "https://github.example.com/api/v3" {
+		t.Errorf("api endpoint = %s, want the one of enterprise", cfg.APIEndpoint)
+	}
+
+	cfg.APIEndpoint = s.URL
+
+	p, err := newGithub(&cfg)
+	if err != nil {
+		t.Fatalf("new github failed, err:%v", err)
+	}
+
+	if v := p.GetCloneCredential(); v.Username != "bot" || v.Password != testToken {
+		t.Errorf("credential = %+v, want the user of token", v)
+	}
+
+	if v := p.GetCloneURL("owner", "repo"); v != "https://github.example.com/owner/repo" {
+		t.Errorf("clone url = %s", v)
+	}
+
+	cases := []struct {
+		pid     string
+		ref     string
+		want    string
+		wantErr bool
+	}{
+		{pid: "1", want: "c1"},
+		{pid: "1", ref: "dev", want: "c2"},
+		{pid: "1", ref: "none"},
+		{pid: "2"},
+		{pid: "3", wantErr: true},
+	}
+
+	for _, c := range cases {
+		v, err := p.GetLastCommit(c.pid, c.ref)
+		if (err != nil) != c.wantErr {
+			t.Errorf("repo %s ref %q: err = %v, want error %v", c.pid, c.ref, err, c.wantErr)
+
+			continue
+		}
+
+		if v != c.want {
+			t.Errorf("repo %s ref %q: commit = %s, want %s", c.pid, c.ref, v, c.want)
+		}
+	}
+}
+
+func TestGithubAPIEndpoint(t *testing.T) {
+	cfg := Config{Type: platformGithub, Host: "https://github.com/"}
+	cfg.SetDefault()
+
+	if cfg.APIEndpoint != "https://api.github.com" {
+		t.Errorf("api endpoint = %s, want the one of github.com", cfg.APIEndpoint)
+	}
+}
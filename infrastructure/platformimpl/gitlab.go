@@ -2,14 +2,13 @@ package platformimpl
 
 import (
 	"fmt"
-	"strings"
 
 	gitlab "github.com/xanzy/go-gitlab"
 
 	"github.com/opensourceways/xihe-training-center/domain/platform"
 )
 
-func NewPlatform(cfg *Config) (platform.Platform, error) {
+func newGitlab(cfg *Config) (platform.Platform, error) {
 	cli, err := gitlab.NewOAuthClient(
 		cfg.Token,
 		gitlab.WithBaseURL(cfg.Host),
@@ -18,34 +17,49 @@ func NewPlatform(cfg *Config) (platform.Platform, error) {
 		return nil, err
 	}
 
-	u, _, err := cli.Users.CurrentUser()
-	if err != nil {
-		return nil, err
+	user := cfg.User
+	if user == "" {
+		u, _, err := cli.Users.CurrentUser()
+		if err != nil {
+			return nil, err
+		}
+
+		user = u.Username
 	}
 
 	return &platformImpl{
-		cli: cli,
-		endpoint: strings.Replace(
-			strings.TrimSuffix(cfg.Host, "/"), "://",
-			fmt.Sprintf("://%s:%s@", u.Username, cfg.Token), 1,
-		),
+		cli:      cli,
+		endpoint: cfg.host(),
+		credential: platform.Credential{
+			Username: user,
+			Password: cfg.Token,
+		},
 	}, nil
 }
 
 type platformImpl struct {
-	cli      *gitlab.Client
-	endpoint string
+	cli        *gitlab.Client
+	endpoint   string
+	credential platform.Credential
 }
 
 func (h *platformImpl) GetCloneURL(owner, repo string) string {
 	return fmt.Sprintf("%s/%s/%s", h.endpoint, owner, repo)
 }
 
-func (h *platformImpl) GetLastCommit(pid string) (string, error) {
+func (h *platformImpl) GetCloneCredential() platform.Credential {
+	return h.credential
+}
+
+func (h *platformImpl) GetLastCommit(pid, ref string) (string, error) {
 	opts := gitlab.ListCommitsOptions{}
 	opts.Page = 1
 	opts.PerPage = 1
 
+	if ref != "" {
+		opts.RefName = gitlab.String(ref)
+	}
+
 	v, _, err := h.cli.Commits.ListCommits(pid, &opts, nil)
 
 	if err != nil || len(v) == 0 {
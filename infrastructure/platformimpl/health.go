@@ -0,0 +1,33 @@
+package platformimpl
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+)
+
+// NewHealthCheck returns the check of whether the platform is reachable.
+// Any response which is not a server error means it is reachable.
+func NewHealthCheck(cfg *Config) func(context.Context) error {
+	host := cfg.host()
+
+	return func(ctx context.Context) error {
+		req, err := http.NewRequestWithContext(ctx, http.MethodHead, host, nil)
+		if err != nil {
+			return err
+		}
+
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			return err
+		}
+
+		resp.Body.Close()
+
+		if resp.StatusCode >= http.StatusInternalServerError {
+			return fmt.Errorf("response status: %d", resp.StatusCode)
+		}
+
+		return nil
+	}
+}
@@ -0,0 +1,46 @@
+package platformimpl
+
+import (
+	"time"
+
+	"github.com/opensourceways/xihe-training-center/domain/platform"
+	"github.com/opensourceways/xihe-training-center/metrics"
+)
+
+func NewPlatform(cfg *Config) (platform.Platform, error) {
+	p, err := newPlatform(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	return observedPlatform{Platform: p, service: cfg.Type}, nil
+}
+
+func newPlatform(cfg *Config) (platform.Platform, error) {
+	switch cfg.Type {
+	case platformGitea:
+		return newGitea(cfg)
+
+	case platformGithub:
+		return newGithub(cfg)
+
+	case platformGit:
+		return newGit(cfg), nil
+
+	default:
+		return newGitlab(cfg)
+	}
+}
+
+// observedPlatform observes the calls to the code platform.
+type observedPlatform struct {
+	platform.Platform
+
+	service string
+}
+
+func (p observedPlatform) GetLastCommit(pid, ref string) (v string, err error) {
+	defer metrics.ObserveCall(p.service, "get_last_commit", time.Now(), &err)
+
+	return p.Platform.GetLastCommit(pid, ref)
+}
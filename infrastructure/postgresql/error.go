@@ -0,0 +1,16 @@
+package postgresql
+
+import (
+	"errors"
+
+	"github.com/jackc/pgconn"
+)
+
+// pgUniqueViolation is the error code of unique_violation of PostgreSQL.
+const pgUniqueViolation = "23505"
+
+func isErrorUniqueViolation(err error) bool {
+	var e *pgconn.PgError
+
+	return errors.As(err, &e) && e.Code == pgUniqueViolation
+}
@@ -0,0 +1,31 @@
+package postgresql
+
+import (
+	"embed"
+
+	"gorm.io/gorm"
+)
+
+//go:embed migrations/*.sql
+var migrationFiles embed.FS
+
+// Migrate applies the migrations of tables and returns the names of
+// migrations applied this time. It must be called after Init.
+func Migrate() ([]string, error) {
+	return cli.Migrate(migrationFiles, migrationLock{})
+}
+
+// migrationLockKey is the key of advisory lock for the migrations.
+const migrationLockKey = 0x7869686574726e
+
+// migrationLock is the session level advisory lock of PostgreSQL.
+// It is released automatically if the session is closed.
+type migrationLock struct{}
+
+func (l migrationLock) Lock(db *gorm.DB) error {
+	return db.Exec("SELECT pg_advisory_lock(?)", migrationLockKey).Error
+}
+
+func (l migrationLock) Unlock(db *gorm.DB) error {
+	return db.Exec("SELECT pg_advisory_unlock(?)", migrationLockKey).Error
+}
@@ -0,0 +1,5 @@
+-- the sync lock tracks the last commit of each ref of repo.
+ALTER TABLE "{{.ProjectTableName}}"
+    ADD COLUMN "ref" VARCHAR(255) NOT NULL DEFAULT '',
+    DROP CONSTRAINT "uk_{{.ProjectTableName}}_owner_repo",
+    ADD CONSTRAINT "uk_{{.ProjectTableName}}_owner_repo_ref" UNIQUE ("owner", "repo_id", "ref");
@@ -0,0 +1,9 @@
+-- the table of the secrets of users which can be referenced by the env of training.
+CREATE TABLE IF NOT EXISTS "{{.SecretTableName}}" (
+    "id"         SERIAL       PRIMARY KEY,
+    "owner"      VARCHAR(255) NOT NULL,
+    "name"       VARCHAR(64)  NOT NULL,
+    "value"      TEXT         NOT NULL,
+    "updated_at" BIGINT       NOT NULL DEFAULT 0,
+    CONSTRAINT "uk_{{.SecretTableName}}_owner_name" UNIQUE ("owner", "name")
+);
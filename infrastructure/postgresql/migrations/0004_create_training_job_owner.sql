@@ -0,0 +1,8 @@
+-- the table of the owners of training jobs which are checked when accessing the jobs.
+CREATE TABLE IF NOT EXISTS "{{.JobOwnerTableName}}" (
+    "id"         SERIAL       PRIMARY KEY,
+    "job_id"     VARCHAR(255) NOT NULL,
+    "owner"      VARCHAR(255) NOT NULL,
+    "created_at" BIGINT       NOT NULL DEFAULT 0,
+    CONSTRAINT "uk_{{.JobOwnerTableName}}_job_id" UNIQUE ("job_id")
+);
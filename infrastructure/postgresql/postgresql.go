@@ -0,0 +1,57 @@
+package postgresql
+
+import (
+	"context"
+	"time"
+
+	gormpostgres "gorm.io/driver/postgres"
+	"gorm.io/gorm"
+
+	"github.com/opensourceways/xihe-training-center/infrastructure/gormdb"
+	"github.com/opensourceways/xihe-training-center/infrastructure/jobownerimpl"
+	"github.com/opensourceways/xihe-training-center/infrastructure/secretimpl"
+	"github.com/opensourceways/xihe-training-center/infrastructure/synclockimpl"
+)
+
+var cli *gormdb.DB
+
+func Init(cfg *Config) error {
+	db, err := gorm.Open(gormpostgres.Open(cfg.Conn), &gorm.Config{})
+	if err != nil {
+		return err
+	}
+
+	sqlDB, err := db.DB()
+	if err != nil {
+		return err
+	}
+
+	sqlDB.SetConnMaxLifetime(time.Duration(cfg.ConnMaxLifetime) * time.Second)
+	sqlDB.SetMaxOpenConns(cfg.MaxOpenConns)
+	sqlDB.SetMaxIdleConns(cfg.MaxIdleConns)
+
+	cli = gormdb.New(db, gormdb.Tables{
+		ProjectTableName:  cfg.ProjectTableName,
+		SecretTableName:   cfg.SecretTableName,
+		JobOwnerTableName: cfg.JobOwnerTableName,
+	}, isErrorUniqueViolation)
+
+	return nil
+}
+
+func NewSyncLockMapper() synclockimpl.SyncLockMapper {
+	return cli.NewSyncLockMapper()
+}
+
+func NewSecretMapper() secretimpl.SecretMapper {
+	return cli.NewSecretMapper()
+}
+
+func NewJobOwnerMapper() jobownerimpl.JobOwnerMapper {
+	return cli.NewJobOwnerMapper()
+}
+
+// Ping checks the connection to the database.
+func Ping(ctx context.Context) error {
+	return cli.Ping(ctx)
+}
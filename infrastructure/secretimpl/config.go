@@ -0,0 +1,32 @@
+package secretimpl
+
+import (
+	"encoding/base64"
+	"errors"
+)
+
+type Config struct {
+	// EncryptionKey is the base64 encoded key of AES which encrypts the
+	// values of secrets saved in the database. It must be 16, 24 or 32
+	// bytes after decoded.
+	EncryptionKey string `json:"encryption_key" required:"true"`
+}
+
+func (cfg *Config) Validate() error {
+	_, err := cfg.key()
+
+	return err
+}
+
+func (cfg *Config) key() ([]byte, error) {
+	v, err := base64.StdEncoding.DecodeString(cfg.EncryptionKey)
+	if err != nil {
+		return nil, errors.New("the encryption key of secret is not base64 encoded")
+	}
+
+	if n := len(v); n != 16 && n != 24 && n != 32 {
+		return nil, errors.New("the encryption key of secret must be 16, 24 or 32 bytes")
+	}
+
+	return v, nil
+}
@@ -0,0 +1,23 @@
+package secretimpl
+
+import "github.com/opensourceways/xihe-training-center/domain/secret"
+
+type errorDataNotExists struct {
+	error
+}
+
+func NewErrorDataNotExists(err error) errorDataNotExists {
+	return errorDataNotExists{err}
+}
+
+func convertError(err error) (out error) {
+	switch err.(type) {
+	case errorDataNotExists:
+		out = secret.NewErrorSecretNotExists(err)
+
+	default:
+		out = err
+	}
+
+	return
+}
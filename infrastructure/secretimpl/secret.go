@@ -0,0 +1,125 @@
+package secretimpl
+
+import (
+	"encoding/base64"
+
+	"github.com/opensourceways/xihe-training-center/domain"
+	"github.com/opensourceways/xihe-training-center/domain/secret"
+	"github.com/opensourceways/xihe-training-center/utils"
+)
+
+type SecretMapper interface {
+	// Upsert inserts the secret or updates it if it exists.
+	Upsert(*SecretDO) error
+	// Get gets the secret by owner and name.
+	Get(string, string) (SecretDO, error)
+	// List lists the secrets of owner.
+	List(string) ([]SecretDO, error)
+	// Delete deletes the secret by owner and name.
+	Delete(string, string) error
+}
+
+// NewSecret returns the secret repository which encrypts
+// the values before saving them to the database.
+func NewSecret(mapper SecretMapper, cfg *Config) (secret.Secret, error) {
+	key, err := cfg.key()
+	if err != nil {
+		return nil, err
+	}
+
+	e, err := utils.NewSymmetricEncryption(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return secretImpl{mapper: mapper, encryption: e}, nil
+}
+
+type secretImpl struct {
+	mapper     SecretMapper
+	encryption utils.SymmetricEncryption
+}
+
+func (impl secretImpl) Save(s *domain.Secret) error {
+	v, err := impl.encryption.Encrypt([]byte(s.Value))
+	if err != nil {
+		return err
+	}
+
+	do := SecretDO{
+		Owner:     s.Owner.Account(),
+		Name:      s.Name.SecretName(),
+		Value:     base64.StdEncoding.EncodeToString(v),
+		UpdatedAt: s.UpdatedAt,
+	}
+
+	return convertError(impl.mapper.Upsert(&do))
+}
+
+func (impl secretImpl) Find(owner domain.Account, name domain.SecretName) (
+	r domain.Secret, err error,
+) {
+	do, err := impl.mapper.Get(owner.Account(), name.SecretName())
+	if err != nil {
+		err = convertError(err)
+
+		return
+	}
+
+	if err = do.toSecret(&r); err != nil {
+		return
+	}
+
+	v, err := base64.StdEncoding.DecodeString(do.Value)
+	if err != nil {
+		return
+	}
+
+	if v, err = impl.encryption.Decrypt(v); err == nil {
+		r.Value = string(v)
+	}
+
+	return
+}
+
+func (impl secretImpl) List(owner domain.Account) ([]domain.Secret, error) {
+	v, err := impl.mapper.List(owner.Account())
+	if err != nil {
+		return nil, convertError(err)
+	}
+
+	r := make([]domain.Secret, len(v))
+	for i := range v {
+		if err := v[i].toSecret(&r[i]); err != nil {
+			return nil, err
+		}
+	}
+
+	return r, nil
+}
+
+func (impl secretImpl) Delete(owner domain.Account, name domain.SecretName) error {
+	return convertError(impl.mapper.Delete(owner.Account(), name.SecretName()))
+}
+
+type SecretDO struct {
+	Owner string
+	Name  string
+
+	// Value is the base64 encoded cipher text of secret.
+	Value     string
+	UpdatedAt int64
+}
+
+// toSecret converts the data without the value.
+func (do *SecretDO) toSecret(r *domain.Secret) (err error) {
+	r.UpdatedAt = do.UpdatedAt
+
+	if r.Owner, err = domain.NewAccount(do.Owner); err != nil {
+		return
+	}
+
+	r.Name, err = domain.NewSecretName(do.Name)
+
+	return
+}
@@ -0,0 +1,31 @@
+package sqlite
+
+type Config struct {
+	// Path is the path of database file. Use ":memory:" to run
+	// with an in-memory database which is lost once exited.
+	Path string `json:"path" required:"true"`
+
+	ProjectTableName string `json:"project_table_name" required:"true"`
+
+	// SecretTableName is the table of secrets of users.
+	// It is "training_secret" by default.
+	SecretTableName string `json:"secret_table_name"`
+
+	// JobOwnerTableName is the table of owners of training jobs.
+	// It is "training_job_owner" by default.
+	JobOwnerTableName string `json:"job_owner_table_name"`
+
+	// AutoMigrate specifies whether to apply the migrations of tables at startup.
+	// The migrations can also be applied by the "migrate" sub command.
+	AutoMigrate bool `json:"auto_migrate"`
+}
+
+func (cfg *Config) SetDefault() {
+	if cfg.SecretTableName == "" {
+		cfg.SecretTableName = "training_secret"
+	}
+
+	if cfg.JobOwnerTableName == "" {
+		cfg.JobOwnerTableName = "training_job_owner"
+	}
+}
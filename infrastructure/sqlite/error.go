@@ -0,0 +1,21 @@
+package sqlite
+
+import (
+	"errors"
+
+	gosqlite "github.com/glebarez/go-sqlite"
+	sqlite3 "modernc.org/sqlite/lib"
+)
+
+func isErrorUniqueViolation(err error) bool {
+	var e *gosqlite.Error
+	if !errors.As(err, &e) {
+		return false
+	}
+
+	// the code may be the primary result code if the extended
+	// result codes are not enabled.
+	code := e.Code()
+
+	return code == sqlite3.SQLITE_CONSTRAINT_UNIQUE || code == sqlite3.SQLITE_CONSTRAINT
+}
@@ -0,0 +1,13 @@
+package sqlite
+
+import "embed"
+
+//go:embed migrations/*.sql
+var migrationFiles embed.FS
+
+// Migrate applies the migrations of tables and returns the names of
+// migrations applied this time. It must be called after Init.
+func Migrate() ([]string, error) {
+	// the writes to the database file are serialized by SQLite itself.
+	return cli.Migrate(migrationFiles, nil)
+}
@@ -0,0 +1,12 @@
+-- the table of the sync lock of project repo.
+CREATE TABLE IF NOT EXISTS "{{.ProjectTableName}}" (
+    "id"          INTEGER      PRIMARY KEY AUTOINCREMENT,
+    "owner"       VARCHAR(255) NOT NULL,
+    "repo_id"     VARCHAR(255) NOT NULL,
+    "status"      VARCHAR(32)  NOT NULL DEFAULT '',
+    "version"     INT          NOT NULL DEFAULT 0,
+    "last_commit" VARCHAR(64)  NOT NULL DEFAULT '',
+    "holder"      VARCHAR(255) NOT NULL DEFAULT '',
+    "expiry"      BIGINT       NOT NULL DEFAULT 0,
+    UNIQUE ("owner", "repo_id")
+);
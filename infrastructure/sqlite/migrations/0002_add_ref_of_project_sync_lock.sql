@@ -0,0 +1,23 @@
+-- the sync lock tracks the last commit of each ref of repo.
+-- sqlite can't change the unique constraint, so rebuild the table.
+CREATE TABLE "{{.ProjectTableName}}_new" (
+    "id"          INTEGER      PRIMARY KEY AUTOINCREMENT,
+    "owner"       VARCHAR(255) NOT NULL,
+    "repo_id"     VARCHAR(255) NOT NULL,
+    "ref"         VARCHAR(255) NOT NULL DEFAULT '',
+    "status"      VARCHAR(32)  NOT NULL DEFAULT '',
+    "version"     INT          NOT NULL DEFAULT 0,
+    "last_commit" VARCHAR(64)  NOT NULL DEFAULT '',
+    "holder"      VARCHAR(255) NOT NULL DEFAULT '',
+    "expiry"      BIGINT       NOT NULL DEFAULT 0,
+    UNIQUE ("owner", "repo_id", "ref")
+);
+
+INSERT INTO "{{.ProjectTableName}}_new"
+    ("id", "owner", "repo_id", "status", "version", "last_commit", "holder", "expiry")
+SELECT "id", "owner", "repo_id", "status", "version", "last_commit", "holder", "expiry"
+FROM "{{.ProjectTableName}}";
+
+DROP TABLE "{{.ProjectTableName}}";
+
+ALTER TABLE "{{.ProjectTableName}}_new" RENAME TO "{{.ProjectTableName}}";
@@ -0,0 +1,4 @@
+-- the job owner records the commits which the training job runs on.
+ALTER TABLE "{{.JobOwnerTableName}}" ADD COLUMN "project_commit" VARCHAR(64) NOT NULL DEFAULT '';
+
+ALTER TABLE "{{.JobOwnerTableName}}" ADD COLUMN "inputs" TEXT NOT NULL DEFAULT '';
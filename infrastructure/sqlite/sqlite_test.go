@@ -0,0 +1,216 @@
+package sqlite
+
+import (
+	"testing"
+
+	"github.com/opensourceways/xihe-training-center/domain"
+	"github.com/opensourceways/xihe-training-center/domain/jobowner"
+	"github.com/opensourceways/xihe-training-center/domain/secret"
+	"github.com/opensourceways/xihe-training-center/domain/synclock"
+	"github.com/opensourceways/xihe-training-center/infrastructure/jobownerimpl"
+	"github.com/opensourceways/xihe-training-center/infrastructure/secretimpl"
+	"github.com/opensourceways/xihe-training-center/infrastructure/synclockimpl"
+)
+
+// testEncryptionKey is the base64 encoded key of 16 bytes.
+const testEncryptionKey = "MDEyMzQ1Njc4OWFiY2RlZg=="
+
+func initTestDB(t *testing.T) {
+	t.Helper()
+
+	cfg := Config{
+		Path:             ":memory:",
+		ProjectTableName: "project_sync_lock",
+	}
+	cfg.SetDefault()
+
+	if err := Init(&cfg); err != nil {
+		t.Fatalf("init failed, err:%v", err)
+	}
+
+	if _, err := Migrate(); err != nil {
+		t.Fatalf("migrate failed, err:%v", err)
+	}
+}
+
+func newTestAccount(t *testing.T, v string) domain.Account {
+	t.Helper()
+
+	a, err := domain.NewAccount(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return a
+}
+
+func TestMigrateIsIdempotent(t *testing.T) {
+	initTestDB(t)
+
+	v, err := Migrate()
+	if err != nil {
+		t.Fatalf("migrate again failed, err:%v", err)
+	}
+
+	if len(v) != 0 {
+		t.Errorf("applied %v again, want none", v)
+	}
+}
+
+func TestSyncLockMapper(t *testing.T) {
+	initTestDB(t)
+
+	lock := synclockimpl.NewRepoSyncLock(NewSyncLockMapper())
+	owner := newTestAccount(t, "alice")
+
+	if _, err := lock.Find(owner, "1", ""); !synclock.IsRepoSyncLockNotExist(err) {
+		t.Fatalf("find the lock not exists, err:%v", err)
+	}
+
+	c, err := lock.Save(&domain.RepoSyncLock{
+		Owner:  owner,
+		RepoId: "1",
+		Status: domain.RepoSyncStatusRunning,
+		Holder: "a",
+	})
+	if err != nil {
+		t.Fatalf("insert failed, err:%v", err)
+	}
+
+	// the locks of different refs of the same repo are independent.
+	if _, err := lock.Save(&domain.RepoSyncLock{
+		Owner:  owner,
+		RepoId: "1",
+		Ref:    "dev",
+		Status: domain.RepoSyncStatusDone,
+	}); err != nil {
+		t.Fatalf("insert the lock of ref failed, err:%v", err)
+	}
+
+	_, err = lock.Save(&domain.RepoSyncLock{
+		Owner:  owner,
+		RepoId: "1",
+		Status: domain.RepoSyncStatusRunning,
+		Holder: "b",
+	})
+	if !synclock.IsErrorConcurrentUpdating(err) {
+		t.Fatalf("insert the duplicate lock, err:%v, want concurrent updating", err)
+	}
+
+	c.Status = domain.RepoSyncStatusDone
+	c.Holder = ""
+	c.LastCommit = "abc"
+
+	if c, err = lock.Save(&c); err != nil {
+		t.Fatalf("update failed, err:%v", err)
+	}
+
+	// the version has been changed by the update above.
+	stale := c
+	stale.Version--
+
+	if _, err := lock.Save(&stale); !synclock.IsErrorConcurrentUpdating(err) {
+		t.Fatalf("update with the stale version, err:%v, want concurrent updating", err)
+	}
+
+	v, err := lock.Find(owner, "1", "")
+	if err != nil {
+		t.Fatalf("find failed, err:%v", err)
+	}
+
+	if v.Version != c.Version || v.LastCommit != "abc" || v.IsLocked() {
+		t.Errorf("lock = %+v, want the updated one of version %d", v, c.Version)
+	}
+}
+
+func TestSecretMapper(t *testing.T) {
+	initTestDB(t)
+
+	s, err := secretimpl.NewSecret(
+		NewSecretMapper(), &secretimpl.Config{EncryptionKey: testEncryptionKey},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	owner := newTestAccount(t, "alice")
+
+	name, err := domain.NewSecretName("token")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, v := range []string{"v1", "v2"} {
+		if err := s.Save(&domain.Secret{Owner: owner, Name: name, Value: v}); err != nil {
+			t.Fatalf("save failed, err:%v", err)
+		}
+	}
+
+	v, err := s.Find(owner, name)
+	if err != nil {
+		t.Fatalf("find failed, err:%v", err)
+	}
+
+	if v.Value != "v2" {
+		t.Errorf("value = %s, want the updated one", v.Value)
+	}
+
+	items, err := s.List(owner)
+	if err != nil {
+		t.Fatalf("list failed, err:%v", err)
+	}
+
+	if len(items) != 1 || items[0].Value != "" {
+		t.Errorf("list = %+v, want one secret without value", items)
+	}
+
+	if err := s.Delete(owner, name); err != nil {
+		t.Fatalf("delete failed, err:%v", err)
+	}
+
+	if err := s.Delete(owner, name); !secret.IsErrorSecretNotExists(err) {
+		t.Errorf("delete again, err:%v, want not exists", err)
+	}
+}
+
+func TestJobOwnerMapper(t *testing.T) {
+	initTestDB(t)
+
+	jo := jobownerimpl.NewJobOwner(NewJobOwnerMapper())
+	owner := newTestAccount(t, "alice")
+
+	commits := jobowner.JobCommits{
+		Project: "c1",
+		Inputs:  []jobowner.InputCommit{{Key: "data", Commit: "c2"}},
+	}
+
+	if err := jo.Save("job1", owner, &commits); err != nil {
+		t.Fatalf("save failed, err:%v", err)
+	}
+
+	v, err := jo.Find("job1")
+	if err != nil {
+		t.Fatalf("find failed, err:%v", err)
+	}
+
+	if v.Account() != owner.Account() {
+		t.Errorf("owner = %s, want %s", v.Account(), owner.Account())
+	}
+
+	c, err := jo.FindCommits("job1")
+	if err != nil {
+		t.Fatalf("find commits failed, err:%v", err)
+	}
+
+	if c.Project != "c1" || len(c.Inputs) != 1 || c.Inputs[0] != commits.Inputs[0] {
+		t.Errorf("commits = %+v, want %+v", c, commits)
+	}
+
+	if err := jo.Delete("job1"); err != nil {
+		t.Fatalf("delete failed, err:%v", err)
+	}
+
+	if _, err := jo.Find("job1"); !jobowner.IsErrorJobOwnerNotExists(err) {
+		t.Errorf("find the deleted one, err:%v, want not exists", err)
+	}
+}
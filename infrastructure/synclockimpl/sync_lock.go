@@ -8,7 +8,8 @@ import (
 type SyncLockMapper interface {
 	Insert(*RepoSyncLockDO) (string, error)
 	Update(*RepoSyncLockDO) error
-	Get(string, string) (RepoSyncLockDO, error)
+	// Get gets the lock by owner, repo id and ref.
+	Get(string, string, string) (RepoSyncLockDO, error)
 }
 
 func NewRepoSyncLock(mapper SyncLockMapper) synclock.RepoSyncLock {
@@ -44,10 +45,10 @@ func (impl syncLock) Save(p *domain.RepoSyncLock) (r domain.RepoSyncLock, err er
 	return
 }
 
-func (impl syncLock) Find(owner domain.Account, repoId string) (
+func (impl syncLock) Find(owner domain.Account, repoId, ref string) (
 	r domain.RepoSyncLock, err error,
 ) {
-	v, err := impl.mapper.Get(owner.Account(), repoId)
+	v, err := impl.mapper.Get(owner.Account(), repoId, ref)
 	if err != nil {
 		err = convertError(err)
 	} else {
@@ -62,9 +63,12 @@ func (impl syncLock) toRepoSyncLockDO(p *domain.RepoSyncLock) RepoSyncLockDO {
 		Id:         p.Id,
 		Owner:      p.Owner.Account(),
 		RepoId:     p.RepoId,
+		Ref:        p.Ref,
 		LastCommit: p.LastCommit,
 		Status:     p.Status.RepoSyncStatus(),
 		Version:    p.Version,
+		Holder:     p.Holder,
+		Expiry:     p.Expiry,
 	}
 }
 
@@ -72,16 +76,22 @@ type RepoSyncLockDO struct {
 	Id         string
 	Owner      string
 	RepoId     string
+	Ref        string
 	LastCommit string
 	Status     string
 	Version    int
+	Holder     string
+	Expiry     int64
 }
 
 func (do *RepoSyncLockDO) toSyncLock(r *domain.RepoSyncLock) (err error) {
 	r.Id = do.Id
 	r.RepoId = do.RepoId
+	r.Ref = do.Ref
 	r.Version = do.Version
 	r.LastCommit = do.LastCommit
+	r.Holder = do.Holder
+	r.Expiry = do.Expiry
 
 	if r.Owner, err = domain.NewAccount(do.Owner); err != nil {
 		return
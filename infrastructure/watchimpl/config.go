@@ -1,10 +1,17 @@
 package watchimpl
 
+import "time"
+
 type Config struct {
 	// Interval specifies the interval of second between two loops
 	// that check all trainings in a loop.
 	Interval int `json:"interval"`
 
+	// StallTimeout specifies the max seconds which the watcher can take
+	// to check a training. The watcher is not alive if it doesn't
+	// advance within the interval above plus this timeout.
+	StallTimeout int `json:"stall_timeout"`
+
 	Endpoint string `json:"endpoint" required:"true"`
 }
 
@@ -12,4 +19,17 @@ func (cfg *Config) SetDefault() {
 	if cfg.Interval <= 0 {
 		cfg.Interval = 10
 	}
+
+	if cfg.StallTimeout <= 0 {
+		cfg.StallTimeout = 1800
+	}
+}
+
+func (cfg *Config) interval() time.Duration {
+	return time.Duration(cfg.Interval) * time.Second
+}
+
+// stallTimeout returns the max duration between two advances of watcher.
+func (cfg *Config) stallTimeout() time.Duration {
+	return cfg.interval() + time.Duration(cfg.StallTimeout)*time.Second
 }
@@ -1,12 +1,19 @@
 package watchimpl
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
 	"time"
 
 	pt "github.com/opensourceways/xihe-grpc-protocol/training"
 	"github.com/opensourceways/xihe-grpc-protocol/training/client"
 	"github.com/sirupsen/logrus"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/connectivity"
 
+	"github.com/opensourceways/xihe-training-center/domain"
 	"github.com/opensourceways/xihe-training-center/domain/training"
 	"github.com/opensourceways/xihe-training-center/domain/watch"
 )
@@ -14,22 +21,32 @@ import (
 type trainingData = pt.TrainingInfo
 
 func NewWatcher(
-	cfg *Config, ts training.Training,
-	maxTrainingNum int, log *logrus.Entry,
+	cfg *Config, ts training.Training, log *logrus.Entry,
 ) (*Watcher, error) {
 	cli, err := client.NewClient(cfg.Endpoint)
 	if err != nil {
 		return nil, err
 	}
 
+	// the connection of client is not exposed, so dial another
+	// one to the same endpoint to check the connectivity.
+	probe, err := grpc.Dial(cfg.Endpoint, grpc.WithInsecure())
+	if err != nil {
+		cli.Disconnect()
+
+		return nil, err
+	}
+
 	return &Watcher{
-		log:       log,
-		cli:       cli,
-		ts:        ts,
-		interval:  time.Duration(cfg.Interval) * time.Second,
-		stop:      make(chan struct{}),
-		stopped:   make(chan struct{}),
-		trainings: make(chan trainingInfo, maxTrainingNum+1),
+		log:          log,
+		cli:          cli,
+		probe:        probe,
+		ts:           ts,
+		interval:     cfg.interval(),
+		stallTimeout: cfg.stallTimeout(),
+		advancedAt:   time.Now(),
+		stop:         make(chan struct{}),
+		stopped:      make(chan struct{}),
 	}, nil
 }
 
@@ -70,85 +87,187 @@ func (t *trainingInfo) isDone() bool {
 }
 
 type Watcher struct {
-	log *logrus.Entry
-	cli *client.Client
-	ts  training.Training
-
-	interval time.Duration
+	log   *logrus.Entry
+	cli   *client.Client
+	probe *grpc.ClientConn
+	ts    training.Training
 
-	stop      chan struct{}
-	stopped   chan struct{}
-	trainings chan trainingInfo
+	stop    chan struct{}
+	stopped chan struct{}
 
 	callback func(*watch.TrainingInfo)
+
+	// the trainings are kept in a queue instead of a channel
+	// which has a fixed capacity, because the max num of
+	// trainings can be changed at runtime.
+	lock         sync.Mutex
+	interval     time.Duration
+	stallTimeout time.Duration
+	advancedAt   time.Time
+	trainings    []trainingInfo
 }
 
 func (w *Watcher) WatchTraining(t *watch.TrainingInfo) {
-	w.trainings <- trainingInfo{TrainingInfo: *t}
+	w.push(trainingInfo{TrainingInfo: *t})
+}
+
+// UpdateConfig applies the config which can be changed at runtime.
+func (w *Watcher) UpdateConfig(cfg *Config) {
+	w.lock.Lock()
+	w.interval = cfg.interval()
+	w.stallTimeout = cfg.stallTimeout()
+	w.lock.Unlock()
+}
+
+// CheckAlive checks whether the loop of watcher is advancing.
+func (w *Watcher) CheckAlive(context.Context) error {
+	select {
+	case <-w.stopped:
+		return errors.New("the watcher has stopped")
+	default:
+	}
+
+	w.lock.Lock()
+	d, timeout := time.Since(w.advancedAt), w.stallTimeout
+	w.lock.Unlock()
+
+	if d > timeout {
+		return fmt.Errorf("the watcher has not advanced for %s", d.Round(time.Second))
+	}
+
+	return nil
+}
+
+// CheckConnection checks whether the connection to the xihe server is ready.
+func (w *Watcher) CheckConnection(ctx context.Context) error {
+	for {
+		s := w.probe.GetState()
+		if s == connectivity.Ready {
+			return nil
+		}
+
+		if s == connectivity.Idle {
+			w.probe.Connect()
+		}
+
+		if !w.probe.WaitForStateChange(ctx, s) {
+			return fmt.Errorf("the connection is %s", s)
+		}
+	}
+}
+
+// advance records that the loop of watcher is advancing.
+func (w *Watcher) advance() {
+	w.lock.Lock()
+	w.advancedAt = time.Now()
+	w.lock.Unlock()
+}
+
+func (w *Watcher) push(info trainingInfo) {
+	w.lock.Lock()
+	w.trainings = append(w.trainings, info)
+	w.lock.Unlock()
+}
+
+// popAll returns all the trainings which will be checked in a loop.
+func (w *Watcher) popAll() ([]trainingInfo, time.Duration) {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	v := w.trainings
+	w.trainings = nil
+
+	return v, w.interval
 }
 
 func (w *Watcher) RegisterTrainingDone(f func(*watch.TrainingInfo)) {
 	w.callback = f
 }
 
+func (w *Watcher) NotifyTrainingStatus(t *watch.TrainingInfo, status domain.TrainingStatus) error {
+	info := trainingInfo{TrainingInfo: *t}
+	index := info.toIndex()
+
+	return w.cli.SetTrainingInfo(&index, &trainingData{
+		Status: status.TrainingStatus(),
+	})
+}
+
 func (w *Watcher) Run() {
 	if w.callback == nil {
 		w.callback = func(*watch.TrainingInfo) {}
 	}
 
-	start := time.Now()
+	defer close(w.stopped)
 
 	for {
-		select {
-		case info := <-w.trainings:
-			// use =="" stands for the case that the loop is done
-			if info.User == nil {
-				t := start.Add(w.interval)
+		start := time.Now()
 
-				if n := time.Now(); t.After(n) {
-					time.Sleep(t.Sub(n))
-				}
+		w.advance()
 
-				w.trainings <- trainingInfo{}
+		trainings, interval := w.popAll()
 
-				start = time.Now()
+		for i := range trainings {
+			select {
+			case <-w.stop:
+				return
+			default:
+			}
 
-			} else {
-				changed := w.check(&info)
+			w.watch(&trainings[i])
 
-				if info.isDone() {
-					index := info.toIndex()
+			w.advance()
+		}
 
-					if err := w.cli.SetTrainingInfo(&index, &info.result); err == nil {
-						w.callback(&info.TrainingInfo)
-					} else {
-						w.trainings <- info
-					}
+		d := time.Until(start.Add(interval))
+		if d <= 0 {
+			continue
+		}
 
-				} else {
-					if changed {
-						index := info.toIndex()
-						w.cli.SetTrainingInfo(&index, &info.result)
-					}
+		timer := time.NewTimer(d)
 
-					w.trainings <- info
-				}
-			}
+		select {
+		case <-timer.C:
 
 		case <-w.stop:
-			close(w.stopped)
+			timer.Stop()
 
 			return
 		}
 	}
 }
 
+// watch checks the training and pushes it back if it is not done.
+func (w *Watcher) watch(info *trainingInfo) {
+	changed := w.check(info)
+
+	if info.isDone() {
+		index := info.toIndex()
+
+		if err := w.cli.SetTrainingInfo(&index, &info.result); err == nil {
+			w.callback(&info.TrainingInfo)
+		} else {
+			w.push(*info)
+		}
+
+		return
+	}
+
+	if changed {
+		index := info.toIndex()
+		w.cli.SetTrainingInfo(&index, &info.result)
+	}
+
+	w.push(*info)
+}
+
 func (w *Watcher) Exit() {
 	close(w.stop)
 
 	<-w.stopped
 
 	w.cli.Disconnect()
+	w.probe.Close()
 }
 
 func (w *Watcher) check(info *trainingInfo) (changed bool) {
@@ -171,6 +290,7 @@ func (w *Watcher) check(info *trainingInfo) (changed bool) {
 
 		info.done = true
 		info.success = detail.Status.IsSuccess()
+		info.Status = detail.Status
 	}
 
 	if !info.logDone {
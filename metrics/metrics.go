@@ -0,0 +1,183 @@
+package metrics
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "xihe_training_center"
+
+const (
+	resultSuccess = "success"
+	resultFailed  = "failed"
+)
+
+var registry = prometheus.NewRegistry()
+
+var (
+	httpRequestDuration = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "http_request_duration_seconds",
+			Help:      "Duration of http requests per route and status.",
+			Buckets:   prometheus.DefBuckets,
+		},
+		[]string{"method", "route", "status"},
+	)
+
+	trainingsRunning = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "trainings_running",
+		Help:      "Number of trainings which have been submitted and are not done.",
+	})
+
+	trainingsWaiting = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "trainings_waiting",
+		Help:      "Number of trainings which are waiting for the inputs.",
+	})
+
+	trainingSlotsAvailable = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "training_slots_available",
+		Help:      "Number of trainings which can be submitted more.",
+	})
+
+	trainingsFinished = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "trainings_finished_total",
+			Help:      "Number of finished trainings per final status and flavor.",
+		},
+		[]string{"status", "flavor"},
+	)
+
+	projectSyncDuration = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "project_sync_duration_seconds",
+			Help:      "Duration of syncing the project to object storage.",
+			Buckets:   prometheus.ExponentialBuckets(1, 2, 12),
+		},
+		[]string{"result"},
+	)
+
+	packagingDuration = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "artifact_packaging_duration_seconds",
+			Help:      "Duration of packaging the output or aim of training.",
+			Buckets:   prometheus.ExponentialBuckets(1, 2, 12),
+		},
+		[]string{"kind", "result"},
+	)
+
+	callDuration = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "external_call_duration_seconds",
+			Help:      "Duration of calling ModelArts, OBS and the code platform.",
+			Buckets:   prometheus.DefBuckets,
+		},
+		[]string{"service", "operation"},
+	)
+
+	callErrors = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "external_call_errors_total",
+			Help:      "Number of failed calls to ModelArts, OBS and the code platform.",
+		},
+		[]string{"service", "operation"},
+	)
+)
+
+func init() {
+	registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		httpRequestDuration,
+		trainingsRunning,
+		trainingsWaiting,
+		trainingSlotsAvailable,
+		trainingsFinished,
+		projectSyncDuration,
+		packagingDuration,
+		callDuration,
+		callErrors,
+	)
+}
+
+// Handler returns the handler which exposes the metrics.
+func Handler() http.Handler {
+	return promhttp.HandlerFor(registry, promhttp.HandlerOpts{})
+}
+
+// ObserveHTTPRequest observes the duration of http request. The route is
+// the pattern of path, such as /api/v1/train/:id, so that the cardinality
+// of labels is bounded.
+func ObserveHTTPRequest(method, route string, status int, start time.Time) {
+	httpRequestDuration.WithLabelValues(
+		method, route, strconv.Itoa(status),
+	).Observe(time.Since(start).Seconds())
+}
+
+// SetTrainingSlots sets the number of running and waiting trainings,
+// as well as the available slots which are calculated by the max num.
+func SetTrainingSlots(running, waiting, max int) {
+	trainingsRunning.Set(float64(running))
+	trainingsWaiting.Set(float64(waiting))
+
+	if n := max - running; n > 0 {
+		trainingSlotsAvailable.Set(float64(n))
+	} else {
+		trainingSlotsAvailable.Set(0)
+	}
+}
+
+// TrainingFinished counts the training which is done with the final status.
+func TrainingFinished(status, flavor string) {
+	trainingsFinished.WithLabelValues(status, flavor).Inc()
+}
+
+// ObserveProjectSync observes the duration of syncing the project
+// which starts at start, and the result is decided by err.
+func ObserveProjectSync(start time.Time, err error) {
+	projectSyncDuration.WithLabelValues(toResult(err)).Observe(
+		time.Since(start).Seconds(),
+	)
+}
+
+// ObservePackaging observes the duration of packaging the artifact of kind,
+// which is output or aim, and the result is decided by err.
+func ObservePackaging(kind string, start time.Time, err error) {
+	packagingDuration.WithLabelValues(kind, toResult(err)).Observe(
+		time.Since(start).Seconds(),
+	)
+}
+
+// ObserveCall observes the duration of calling the external service and
+// counts the error. It is deferred with the named error usually, such as
+// defer metrics.ObserveCall("obs", "get_object", time.Now(), &err).
+func ObserveCall(service, operation string, start time.Time, err *error) {
+	callDuration.WithLabelValues(service, operation).Observe(
+		time.Since(start).Seconds(),
+	)
+
+	if err != nil && *err != nil {
+		callErrors.WithLabelValues(service, operation).Inc()
+	}
+}
+
+func toResult(err error) string {
+	if err != nil {
+		return resultFailed
+	}
+
+	return resultSuccess
+}
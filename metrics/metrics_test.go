@@ -0,0 +1,120 @@
+package metrics
+
+import (
+	"bufio"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// scrape returns the values of samples exposed by Handler, and
+// the key is like `name{label="value"}`.
+func scrape(t *testing.T) map[string]float64 {
+	t.Helper()
+
+	w := httptest.NewRecorder()
+	Handler().ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("scrape failed, status:%d", w.Code)
+	}
+
+	r := make(map[string]float64)
+
+	s := bufio.NewScanner(w.Body)
+	for s.Scan() {
+		line := s.Text()
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		i := strings.LastIndex(line, " ")
+
+		v, err := strconv.ParseFloat(line[i+1:], 64)
+		if err != nil {
+			t.Fatalf("invalid sample: %s", line)
+		}
+
+		r[line[:i]] = v
+	}
+
+	return r
+}
+
+func TestHandler(t *testing.T) {
+	const p = namespace + "_"
+
+	before := scrape(t)
+
+	start := time.Now().Add(-time.Second)
+
+	ObserveHTTPRequest(http.MethodGet, "/api/v1/train/:id", http.StatusOK, start)
+	SetTrainingSlots(3, 2, 10)
+	TrainingFinished("Completed", "cpu")
+	TrainingFinished("Completed", "cpu")
+	ObserveProjectSync(start, errors.New("failed"))
+	ObservePackaging("aim", start, nil)
+
+	var err error
+	ObserveCall("obs", "get_object", start, &err)
+
+	err = errors.New("failed")
+	ObserveCall("obs", "get_object", start, &err)
+
+	after := scrape(t)
+
+	counters := []struct {
+		key  string
+		want float64
+	}{
+		{p + `http_request_duration_seconds_count{method="GET",route="/api/v1/train/:id",status="200"}`, 1},
+		{p + `trainings_finished_total{flavor="cpu",status="Completed"}`, 2},
+		{p + `project_sync_duration_seconds_count{result="failed"}`, 1},
+		{p + `artifact_packaging_duration_seconds_count{kind="aim",result="success"}`, 1},
+		{p + `external_call_duration_seconds_count{operation="get_object",service="obs"}`, 2},
+		{p + `external_call_errors_total{operation="get_object",service="obs"}`, 1},
+	}
+
+	for _, c := range counters {
+		v, ok := after[c.key]
+		if !ok {
+			t.Errorf("%s is not exposed", c.key)
+
+			continue
+		}
+
+		if d := v - before[c.key]; d != c.want {
+			t.Errorf("%s increased by %v, want %v", c.key, d, c.want)
+		}
+	}
+
+	gauges := []struct {
+		key  string
+		want float64
+	}{
+		{p + "trainings_running", 3},
+		{p + "trainings_waiting", 2},
+		{p + "training_slots_available", 7},
+	}
+
+	for _, c := range gauges {
+		if v := after[c.key]; v != c.want {
+			t.Errorf("%s = %v, want %v", c.key, v, c.want)
+		}
+	}
+
+	// the available slots is never negative.
+	SetTrainingSlots(12, 0, 10)
+
+	if v := scrape(t)[p+"training_slots_available"]; v != 0 {
+		t.Errorf("available slots = %v, want 0", v)
+	}
+
+	if _, ok := after["go_goroutines"]; !ok {
+		t.Error("the metrics of go runtime are not exposed")
+	}
+}
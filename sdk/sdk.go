@@ -31,9 +31,18 @@ func NewTrainingCenter(endpoint string) TrainingCenter {
 
 type TrainingCenter struct {
 	endpoint string
+	token    string
 	cli      utils.HttpClient
 }
 
+// WithToken returns the client which sends the bearer
+// token when the training center requires the auth.
+func (t TrainingCenter) WithToken(token string) TrainingCenter {
+	t.token = token
+
+	return t
+}
+
 func (t TrainingCenter) jobURL(jobId string) string {
 	return fmt.Sprintf("%s/%s", t.endpoint, jobId)
 }
@@ -104,6 +113,10 @@ func (t TrainingCenter) forwardTo(req *http.Request, jsonResp interface{}) (err
 	req.Header.Set("Accept", "application/json")
 	req.Header.Set("User-Agent", "xihe-training-center")
 
+	if t.token != "" {
+		req.Header.Set("Authorization", "Bearer "+t.token)
+	}
+
 	if jsonResp != nil {
 		v := struct {
 			Data interface{} `json:"data"`
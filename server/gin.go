@@ -13,7 +13,9 @@ import (
 	"github.com/swaggo/swag"
 
 	"github.com/opensourceways/xihe-training-center/app"
+	"github.com/opensourceways/xihe-training-center/auth"
 	"github.com/opensourceways/xihe-training-center/controller"
+	"github.com/opensourceways/xihe-training-center/metrics"
 )
 
 type Service struct {
@@ -22,13 +24,24 @@ type Service struct {
 	Port    int
 	Timeout time.Duration
 
+	// Auth authenticates the callers of apis. The apis
+	// are not authenticated if it is nil.
+	Auth auth.Authenticator
+
 	Training app.TrainingService
+	Compute  app.ComputeService
+	Project  app.ProjectService
+	SyncLock app.SyncLockService
+	Secret   app.SecretService
+	Config   app.ConfigService
+	Health   app.HealthService
 }
 
 func StartWebServer(spec *swag.Spec, service *Service) {
 	r := gin.New()
 	r.Use(gin.Recovery())
 	r.Use(logRequest())
+	r.Use(observeRequest())
 
 	setRouter(r, spec, service)
 
@@ -49,14 +62,46 @@ func setRouter(engine *gin.Engine, spec *swag.Spec, service *Service) {
 	spec.Description = "APIs of xihe training center"
 
 	v1 := engine.Group(spec.BasePath)
+	if service.Auth != nil {
+		v1.Use(controller.Authenticate(service.Auth))
+	}
+
 	{
 		controller.AddRouterForTrainingController(
 			v1,
 			service.Training,
+			service.Compute,
+		)
+
+		controller.AddRouterForComputeController(
+			v1,
+			service.Compute,
+		)
+
+		controller.AddRouterForProjectController(
+			v1,
+			service.Project,
+		)
+
+		controller.AddRouterForAdminController(
+			v1,
+			service.SyncLock,
+			service.Config,
+		)
+
+		controller.AddRouterForSecretController(
+			v1,
+			service.Secret,
 		)
 	}
 
+	controller.AddRouterForHealthController(
+		&engine.RouterGroup,
+		service.Health,
+	)
+
 	engine.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerfiles.Handler))
+	engine.GET("/metrics", gin.WrapH(metrics.Handler()))
 }
 
 func logRequest() gin.HandlerFunc {
@@ -76,3 +121,21 @@ func logRequest() gin.HandlerFunc {
 		)
 	}
 }
+
+func observeRequest() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		startTime := time.Now()
+
+		c.Next()
+
+		// use the pattern of route instead of the path to limit the labels.
+		route := c.FullPath()
+		if route == "" {
+			route = "unmatched"
+		}
+
+		metrics.ObserveHTTPRequest(
+			c.Request.Method, route, c.Writer.Status(), startTime,
+		)
+	}
+}
@@ -0,0 +1,56 @@
+package utils
+
+import (
+	"os"
+	"os/exec"
+	"strings"
+)
+
+const (
+	envGitUsername = "XIHE_GIT_USERNAME"
+	envGitPassword = "XIHE_GIT_PASSWORD"
+
+	redacted = "***"
+)
+
+// GitCredentialEnv returns the environment variables which supply the
+// credential to git by a credential helper, so that the credential will
+// not appear in the clone url or command line. It needs git >= 2.31.
+func GitCredentialEnv(username, password string) []string {
+	if password == "" {
+		return nil
+	}
+
+	helper := `!f() { test "$1" = get && ` +
+		`echo "username=${` + envGitUsername + `}" && ` +
+		`echo "password=${` + envGitPassword + `}"; }; f`
+
+	return []string{
+		"GIT_TERMINAL_PROMPT=0",
+		"GIT_CONFIG_COUNT=1",
+		"GIT_CONFIG_KEY_0=credential.helper",
+		"GIT_CONFIG_VALUE_0=" + helper,
+		envGitUsername + "=" + username,
+		envGitPassword + "=" + password,
+	}
+}
+
+// RunCmdWithEnv runs the command with the environment variables
+// appended to the ones of current process.
+func RunCmdWithEnv(env []string, args ...string) ([]byte, error) {
+	cmd := exec.Command(args[0], args[1:]...)
+	cmd.Env = append(os.Environ(), env...)
+
+	return cmd.CombinedOutput()
+}
+
+// Redact replaces the secrets in s.
+func Redact(s string, secrets ...string) string {
+	for _, v := range secrets {
+		if v != "" {
+			s = strings.ReplaceAll(s, v, redacted)
+		}
+	}
+
+	return s
+}
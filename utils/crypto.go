@@ -0,0 +1,49 @@
+package utils
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"errors"
+	"io"
+)
+
+// SymmetricEncryption encrypts the data by AES-GCM. The nonce
+// is generated randomly and put before the cipher text.
+type SymmetricEncryption struct {
+	aead cipher.AEAD
+}
+
+// NewSymmetricEncryption returns the encryption of key which
+// must be 16, 24 or 32 bytes.
+func NewSymmetricEncryption(key []byte) (SymmetricEncryption, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return SymmetricEncryption{}, err
+	}
+
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return SymmetricEncryption{}, err
+	}
+
+	return SymmetricEncryption{aead}, nil
+}
+
+func (e SymmetricEncryption) Encrypt(plain []byte) ([]byte, error) {
+	nonce := make([]byte, e.aead.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, err
+	}
+
+	return e.aead.Seal(nonce, nonce, plain, nil), nil
+}
+
+func (e SymmetricEncryption) Decrypt(data []byte) ([]byte, error) {
+	n := e.aead.NonceSize()
+	if len(data) < n {
+		return nil, errors.New("invalid cipher text")
+	}
+
+	return e.aead.Open(nil, data[:n], data[n:], nil)
+}